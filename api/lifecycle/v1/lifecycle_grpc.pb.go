@@ -0,0 +1,146 @@
+// Copyright 2025.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: lifecycle/v1/lifecycle.proto
+
+package lifecyclev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	LifecycleService_Watch_FullMethodName = "/lifecycle.v1.LifecycleService/Watch"
+)
+
+// LifecycleServiceClient is the client API for LifecycleService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// LifecycleService streams pod lifecycle records as the controller produces them.
+type LifecycleServiceClient interface {
+	// Watch streams every record produced after the call is made. Records are
+	// dropped for subscribers that cannot keep up.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PodLifecycleEvent], error)
+}
+
+type lifecycleServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLifecycleServiceClient(cc grpc.ClientConnInterface) LifecycleServiceClient {
+	return &lifecycleServiceClient{cc}
+}
+
+func (c *lifecycleServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PodLifecycleEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &LifecycleService_ServiceDesc.Streams[0], LifecycleService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, PodLifecycleEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LifecycleService_WatchClient = grpc.ServerStreamingClient[PodLifecycleEvent]
+
+// LifecycleServiceServer is the server API for LifecycleService service.
+// All implementations must embed UnimplementedLifecycleServiceServer
+// for forward compatibility.
+//
+// LifecycleService streams pod lifecycle records as the controller produces them.
+type LifecycleServiceServer interface {
+	// Watch streams every record produced after the call is made. Records are
+	// dropped for subscribers that cannot keep up.
+	Watch(*WatchRequest, grpc.ServerStreamingServer[PodLifecycleEvent]) error
+	mustEmbedUnimplementedLifecycleServiceServer()
+}
+
+// UnimplementedLifecycleServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLifecycleServiceServer struct{}
+
+func (UnimplementedLifecycleServiceServer) Watch(*WatchRequest, grpc.ServerStreamingServer[PodLifecycleEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedLifecycleServiceServer) mustEmbedUnimplementedLifecycleServiceServer() {}
+func (UnimplementedLifecycleServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeLifecycleServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LifecycleServiceServer will
+// result in compilation errors.
+type UnsafeLifecycleServiceServer interface {
+	mustEmbedUnimplementedLifecycleServiceServer()
+}
+
+func RegisterLifecycleServiceServer(s grpc.ServiceRegistrar, srv LifecycleServiceServer) {
+	// If the following call pancis, it indicates UnimplementedLifecycleServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&LifecycleService_ServiceDesc, srv)
+}
+
+func _LifecycleService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LifecycleServiceServer).Watch(m, &grpc.GenericServerStream[WatchRequest, PodLifecycleEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LifecycleService_WatchServer = grpc.ServerStreamingServer[PodLifecycleEvent]
+
+// LifecycleService_ServiceDesc is the grpc.ServiceDesc for LifecycleService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LifecycleService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "lifecycle.v1.LifecycleService",
+	HandlerType: (*LifecycleServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _LifecycleService_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "lifecycle/v1/lifecycle.proto",
+}
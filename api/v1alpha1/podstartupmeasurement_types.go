@@ -0,0 +1,94 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// PodStartupMeasurementSpec identifies the pod a measurement belongs to.
+type PodStartupMeasurementSpec struct {
+	// podName is the name of the measured pod, in the same namespace.
+	// +required
+	PodName string `json:"podName"`
+
+	// podUID is the UID of the measured pod.
+	// +optional
+	PodUID types.UID `json:"podUID,omitempty"`
+}
+
+// PodStartupMeasurementStatus defines the observed lifecycle timing of a pod.
+type PodStartupMeasurementStatus struct {
+	// node is the node the pod was scheduled to.
+	// +optional
+	Node string `json:"node,omitempty"`
+
+	// phase is the pod phase at the time of the last measurement.
+	// +optional
+	Phase corev1.PodPhase `json:"phase,omitempty"`
+
+	// timestamps holds the time each lifecycle state was reached, keyed by
+	// state name (e.g. scheduled, ready).
+	// +optional
+	Timestamps map[string]metav1.Time `json:"timestamps,omitempty"`
+
+	// durations holds the time taken to reach each lifecycle state from pod
+	// creation, keyed by duration name (e.g. toReady).
+	// +optional
+	Durations map[string]metav1.Duration `json:"durations,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=psm
+// +kubebuilder:printcolumn:name="Pod",type=string,JSONPath=`.spec.podName`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.status.node`
+// +kubebuilder:printcolumn:name="To Ready",type=string,JSONPath=`.status.durations.toReady`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// PodStartupMeasurement is the Schema for the podstartupmeasurements API
+type PodStartupMeasurement struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec identifies the measured pod
+	// +required
+	Spec PodStartupMeasurementSpec `json:"spec"`
+
+	// status holds the measured lifecycle timing
+	// +optional
+	Status PodStartupMeasurementStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PodStartupMeasurementList contains a list of PodStartupMeasurement
+type PodStartupMeasurementList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PodStartupMeasurement `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PodStartupMeasurement{}, &PodStartupMeasurementList{})
+}
@@ -19,23 +19,32 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
+	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	monitoringv1alpha1 "github.com/karthikbhat19/pod-time-measure-controller/api/v1alpha1"
+	"github.com/karthikbhat19/pod-time-measure-controller/internal/analyze"
 	"github.com/karthikbhat19/pod-time-measure-controller/internal/controller"
+	"github.com/karthikbhat19/pod-time-measure-controller/internal/tail"
+	"github.com/karthikbhat19/pod-time-measure-controller/internal/validateconfig"
+	"github.com/karthikbhat19/pod-time-measure-controller/internal/version"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -47,11 +56,25 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
+	utilruntime.Must(monitoringv1alpha1.AddToScheme(scheme))
+
 	// +kubebuilder:scaffold:scheme
 }
 
 // nolint:gocyclo
 func main() {
+	// Subcommands run offline and take their own arguments
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "analyze":
+			os.Exit(analyze.Main(os.Args[2:], os.Stdout, os.Stderr))
+		case "validate-config":
+			os.Exit(validateconfig.Main(os.Args[2:], os.Stdout, os.Stderr))
+		case "tail":
+			os.Exit(tail.Main(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
 	var metricsAddr string
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
@@ -59,6 +82,9 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var configPath string
+	var printVersion bool
+	var namespaced bool
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -77,13 +103,226 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&configPath, "config", "",
+		"Path to a YAML config file. Flags given explicitly override its values.")
+	flag.BoolVar(&printVersion, "version", false, "Print the version and exit.")
+	flag.BoolVar(&namespaced, "namespaced", false,
+		"If set, only pods in the controller's own namespace ($"+controller.PodNamespaceEnv+") are watched, "+
+			"so it can run with a Role instead of a ClusterRole.")
+
+	// Controller flags write straight into the config, so their defaults are
+	// the config defaults
+	cfg := controller.DefaultConfig()
+	flag.StringVar((*string)(&cfg.MinCompleteness), "min-completeness", string(cfg.MinCompleteness),
+		"The least complete state a pod must reach before it is recorded: Scheduled, Initialized or Ready.")
+	flag.StringVar(&cfg.GRPCBindAddress, "grpc-bind-address", cfg.GRPCBindAddress,
+		"The address the lifecycle event gRPC server binds to. Leave as 0 to disable the streaming API.")
+	flag.StringVar(&cfg.QueryBindAddress, "query-bind-address", cfg.QueryBindAddress,
+		"The address the HTTP query server binds to. Leave as 0 to disable the query API.")
+	flag.StringVar(&cfg.QuerySocketPath, "query-socket-path", cfg.QuerySocketPath,
+		"If set, the read-only query API is also served on a Unix socket at this path, "+
+			"e.g. in an emptyDir shared with a sidecar. Set --query-bind-address to 0 to serve it only there.")
+	flag.StringVar(&cfg.ResetTokenFile, "reset-token-file", cfg.ResetTokenFile,
+		"File holding the bearer token that enables POST /reset on the query server. Leave empty to disable it.")
+	flag.StringVar(&cfg.ServerTLS.CertFile, "server-cert-file", cfg.ServerTLS.CertFile,
+		"PEM certificate the query and gRPC servers serve TLS with. Leave empty to serve plain text.")
+	flag.StringVar(&cfg.ServerTLS.KeyFile, "server-key-file", cfg.ServerTLS.KeyFile,
+		"PEM key of --server-cert-file.")
+	flag.StringVar(&cfg.ServerTLS.ClientCAFile, "server-client-ca-file", cfg.ServerTLS.ClientCAFile,
+		"If set, the query and gRPC servers require client certificates signed by a CA in this PEM bundle.")
+	flag.BoolVar(&cfg.RecordMeasurements, "record-measurements", cfg.RecordMeasurements,
+		"If set, every record is also stored as a PodStartupMeasurement resource named after the pod.")
+	flag.BoolVar(&cfg.AnnotatePods, "annotate-pods", cfg.AnnotatePods,
+		"If set, ready pods are annotated with their measured time to ready.")
+	flag.StringVar(&cfg.IgnoreAnnotation, "ignore-annotation", cfg.IgnoreAnnotation,
+		"Pods with this annotation set to \"true\" are not measured.")
+	flag.BoolVar(&cfg.DryRun, "dry-run", cfg.DryRun,
+		"If set, records are logged instead of being written to any sink, and pods and measurements are left untouched.")
+	flag.BoolVar(&cfg.FailHard, "fail-hard", cfg.FailHard,
+		"If set, failed record writes are retried with backoff instead of only being logged and counted.")
+	flag.DurationVar(&cfg.MaxBackoff.Duration, "max-backoff", cfg.MaxBackoff.Duration,
+		"The longest a pod whose records fail to write waits between retries.")
+	flag.DurationVar(&cfg.ClientTimeout.Duration, "client-timeout", cfg.ClientTimeout.Duration,
+		"How long each API call made while reconciling may take before it is abandoned and the pod retried.")
+	flag.IntVar(&cfg.UnhealthyAfter, "unhealthy-after", cfg.UnhealthyAfter,
+		"How many consecutive failed record writes make the readiness probe fail.")
+	flag.DurationVar(&cfg.PollInterval.Duration, "poll-interval", cfg.PollInterval.Duration,
+		"How often Running pods that are not ready yet are revisited. Leave as 0 to rely on watch events only.")
+	flag.DurationVar(&cfg.ExemplarThreshold.Duration, "exemplar-threshold", cfg.ExemplarThreshold.Duration,
+		"Pods slower than this to become ready are attached as exemplars to the time to ready histogram, "+
+			"and their records are logged at info level.")
+	flag.DurationVar(&cfg.ToReadyWindow.Duration, "to-ready-window", cfg.ToReadyWindow.Duration,
+		"The sliding window pod_startup_to_ready_p99_window_seconds is computed over.")
+	flag.BoolVar(&cfg.Backfill, "backfill", cfg.Backfill,
+		"If set, every existing pod is recorded once on startup.")
+	flag.BoolVar(&cfg.TerminalOnly, "terminal-only", cfg.TerminalOnly,
+		"If set, pods are only recorded once they have Succeeded or Failed.")
+	flag.BoolVar(&cfg.RecordOnlyWhenComplete, "record-only-when-complete", cfg.RecordOnlyWhenComplete,
+		"If set, pods are only recorded once Ready or finished, or after --max-wait marked incomplete.")
+	flag.DurationVar(&cfg.MaxWait.Duration, "max-wait", cfg.MaxWait.Duration,
+		"How long --record-only-when-complete waits for a pod before recording it anyway.")
+	flag.DurationVar(&cfg.TerminalIgnoreAge.Duration, "terminal-ignore-age", cfg.TerminalIgnoreAge.Duration,
+		"Succeeded and Failed pods that finished longer ago than this are no longer reconciled once recorded. "+
+			"Leave as 0 to keep reconciling them.")
+	flag.DurationVar(&cfg.DebounceWindow.Duration, "debounce-window", cfg.DebounceWindow.Duration,
+		"How long a pod must go without reconciles before its record is written. Leave as 0 to write immediately.")
+	flag.BoolVar(&cfg.EnrichNodeInfo, "enrich-node-info", cfg.EnrichNodeInfo,
+		"If set, records include the kubelet version, OS image, container runtime version and age at scheduling "+
+			"of the pod's node.")
+	flag.BoolVar(&cfg.EnrichJobInfo, "enrich-job-info", cfg.EnrichJobInfo,
+		"If set, records of Job pods include the Job's creation and start times, and the time from its creation "+
+			"to the pod becoming ready and finishing.")
+	flag.BoolVar(&cfg.IncludeRawConditions, "include-raw-conditions", cfg.IncludeRawConditions,
+		"If set, records include the pod's raw conditions, for debugging unexpected durations.")
+	flag.IntVar(&cfg.MaxConcurrentReconciles, "max-concurrent-reconciles", cfg.MaxConcurrentReconciles,
+		"How many pods are reconciled in parallel.")
+	flag.Float64Var(&cfg.SampleRate, "sample-rate", cfg.SampleRate,
+		"Fraction of pods to record, chosen by UID so each pod is either always or never recorded.")
+	flag.Float64Var(&cfg.PerNamespaceRateLimit, "per-namespace-rate-limit", cfg.PerNamespaceRateLimit,
+		"Records per second written for each namespace; records over it are retried later. 0 is unlimited.")
+	flag.StringVar(&cfg.BaselineContainer, "baseline-container", cfg.BaselineContainer,
+		"If set, durations are measured from when the container of this name started instead of pod creation.")
+	flag.StringVar(&cfg.ClusterName, "cluster-name", cfg.ClusterName,
+		"Name added to every record as cluster, defaulting to $"+controller.ClusterNameEnv+". Leave empty to omit it.")
+	flag.Func("owner-kinds",
+		"Comma-separated kinds of top-level controllers, such as Deployment, whose pods are recorded. "+
+			"Leave empty to record every pod.",
+		func(s string) error {
+			cfg.OwnerKinds = nil
+			if s != "" {
+				cfg.OwnerKinds = strings.Split(s, ",")
+			}
+			return nil
+		})
+	flag.Func("phases",
+		"Comma-separated pod phases, such as Running,Succeeded, in which pods are recorded. "+
+			"Leave empty to record pods in every phase.",
+		func(s string) error {
+			cfg.Phases = nil
+			for _, phase := range strings.Split(s, ",") {
+				if phase != "" {
+					cfg.Phases = append(cfg.Phases, corev1.PodPhase(phase))
+				}
+			}
+			return nil
+		})
+	flag.Func("capture-labels",
+		"Comma-separated pod label keys copied into each record under labels.",
+		func(s string) error {
+			cfg.CaptureLabels = nil
+			if s != "" {
+				cfg.CaptureLabels = strings.Split(s, ",")
+			}
+			return nil
+		})
+	flag.Func("kafka-brokers",
+		"Comma-separated Kafka brokers to also produce every record to. Leave empty to disable the Kafka sink.",
+		func(s string) error {
+			cfg.Sinks.Kafka.Enabled = s != ""
+			cfg.Sinks.Kafka.Brokers = nil
+			if s != "" {
+				cfg.Sinks.Kafka.Brokers = strings.Split(s, ",")
+			}
+			return nil
+		})
+	flag.StringVar(&cfg.Sinks.Kafka.Topic, "kafka-topic", cfg.Sinks.Kafka.Topic,
+		"The Kafka topic records are produced to.")
+	flag.DurationVar(&cfg.Sinks.Kafka.FlushTimeout.Duration, "kafka-flush-timeout", cfg.Sinks.Kafka.FlushTimeout.Duration,
+		"How long shutdown waits for Kafka messages in flight to be delivered.")
+	flag.Func("s3-bucket",
+		"Bucket to also upload batches of records to. Leave empty to disable the S3 sink.",
+		func(s string) error {
+			cfg.Sinks.S3.Enabled = s != ""
+			cfg.Sinks.S3.Bucket = s
+			return nil
+		})
+	flag.StringVar(&cfg.Sinks.S3.Prefix, "s3-prefix", cfg.Sinks.S3.Prefix,
+		"Key prefix for uploaded record batches.")
+	flag.StringVar(&cfg.Sinks.S3.Endpoint, "s3-endpoint", cfg.Sinks.S3.Endpoint,
+		"Endpoint of an S3-compatible store such as MinIO. Leave empty for AWS S3.")
+	flag.Func("influx-url",
+		"InfluxDB write endpoint, including org and bucket or db, to also write durations to as line protocol. "+
+			"Leave empty to disable the InfluxDB sink.",
+		func(s string) error {
+			cfg.Sinks.Influx.Enabled = s != ""
+			cfg.Sinks.Influx.URL = s
+			return nil
+		})
+	flag.StringVar(&cfg.Sinks.Influx.TokenFile, "influx-token-file", cfg.Sinks.Influx.TokenFile,
+		"File holding the InfluxDB authorization token.")
+	flag.Func("parquet-dir",
+		"Existing directory to also write records to as Parquet files, one per batch. "+
+			"Leave empty to disable the Parquet sink.",
+		func(s string) error {
+			cfg.Sinks.Parquet.Enabled = s != ""
+			cfg.Sinks.Parquet.Dir = s
+			return nil
+		})
+	flag.Func("bolt-path",
+		"Embedded bbolt database file to also keep the latest record of every pod in, for durable local storage. "+
+			"Leave empty to disable the bbolt sink.",
+		func(s string) error {
+			cfg.Sinks.Bolt.Enabled = s != ""
+			cfg.Sinks.Bolt.Path = s
+			return nil
+		})
+	flag.Func("otlp-logs-endpoint",
+		"OTLP/HTTP logs endpoint, such as http://otel-collector:4318/v1/logs, to also export records to as log records. "+
+			"Leave empty to disable the OTLP sink.",
+		func(s string) error {
+			cfg.Sinks.OTLP.Enabled = s != ""
+			cfg.Sinks.OTLP.Endpoint = s
+			return nil
+		})
+	flag.BoolVar(&cfg.Rollup.Enabled, "rollup", cfg.Rollup.Enabled,
+		"If set, records are summarized per namespace into rollups.json once per rollup interval.")
+	flag.DurationVar(&cfg.Rollup.Interval.Duration, "rollup-interval", cfg.Rollup.Interval.Duration,
+		"The period each rollup entry covers.")
+	flag.BoolVar(&cfg.Rollup.Prune, "rollup-prune", cfg.Rollup.Prune,
+		"If set, raw records are removed once their period has been rolled up.")
+	flag.BoolVar(&cfg.Sinks.File.Compress, "compress-output", cfg.Sinks.File.Compress,
+		"If set, the record log file is gzipped. Implied when the log path ends in .gz.")
+	flag.StringVar(&cfg.Sinks.File.Format, "log-file-format", cfg.Sinks.File.Format,
+		"The format of the record log file: json for a JSON array or jsonl for JSON Lines. "+
+			"Existing JSON array files are converted when switching to jsonl.")
+	flag.BoolVar(&cfg.Sinks.File.Compact, "log-file-compact", cfg.Sinks.File.Compact,
+		"If set, the JSON array in the record log file is written without indentation.")
 	opts := zap.Options{
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
+	if printVersion {
+		fmt.Println(version.String())
+		os.Exit(0)
+	}
+
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	setupLog.Info("Starting", "version", version.Version, "commit", version.Commit)
+	metrics.Registry.MustRegister(version.NewCollector())
+
+	if configPath != "" {
+		loaded, err := controller.LoadConfig(configPath)
+		if err != nil {
+			setupLog.Error(err, "unable to load config", "path", configPath)
+			os.Exit(1)
+		}
+		// Parse again so flags given on the command line win over the file
+		cfg = loaded
+		flag.Parse()
+	}
+	if namespaced {
+		cfg.WatchNamespace = os.Getenv(controller.PodNamespaceEnv)
+		if cfg.WatchNamespace == "" {
+			setupLog.Error(nil, "--namespaced requires the namespace in $"+controller.PodNamespaceEnv)
+			os.Exit(1)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		setupLog.Error(err, "invalid configuration")
+		os.Exit(1)
+	}
 
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
@@ -155,6 +394,7 @@ func main() {
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
+		Cache:                  cfg.CacheOptions(),
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
@@ -176,10 +416,12 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := (&controller.PodStartupReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	reconciler, err := cfg.NewReconciler(mgr.GetClient(), mgr.GetScheme())
+	if err != nil {
+		setupLog.Error(err, "invalid configuration")
+		os.Exit(1)
+	}
+	if err := reconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "PodStartup")
 		os.Exit(1)
 	}
@@ -0,0 +1,189 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package analyze computes startup statistics from log files written by the
+// controller, without a cluster.
+package analyze
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/karthikbhat19/pod-time-measure-controller/internal/controller"
+)
+
+// allScope labels the statistics computed over every namespace.
+const allScope = "*"
+
+// Stats summarizes the time to ready of a group of pods.
+type Stats struct {
+	// Pods is the number of distinct pods in the group.
+	Pods int
+	// Ready is the number of those pods with a toReady duration.
+	Ready int
+	// P50, P90 and P99 are nearest-rank percentiles of toReady, zero when no
+	// pod is ready.
+	P50, P90, P99 time.Duration
+}
+
+// Result holds the statistics of a log file, overall and per namespace.
+type Result struct {
+	Overall      Stats
+	PerNamespace map[string]Stats
+}
+
+// Main runs the analyze subcommand with the given arguments, writing the
+// report to stdout and errors to stderr, and returns the exit code.
+func Main(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: analyze <file>") //nolint:errcheck
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	records, err := controller.ReadRecords(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "analyze: %v\n", err) //nolint:errcheck
+		return 1
+	}
+	if err := Analyze(records).Write(stdout); err != nil {
+		fmt.Fprintf(stderr, "analyze: %v\n", err) //nolint:errcheck
+		return 1
+	}
+	return 0
+}
+
+// Analyze computes the result of the given records. The log holds a record
+// for every reconcile, so only the last record of each pod is counted.
+func Analyze(records []controller.Record) Result {
+	latest := map[string]controller.Record{}
+	for _, rec := range records {
+		latest[podKey(rec)] = rec
+	}
+
+	all := []time.Duration{}
+	byNamespace := map[string][]time.Duration{}
+	pods := map[string]int{}
+	for _, rec := range latest {
+		namespace, _ := rec["namespace"].(string)
+		pods[namespace]++
+		if _, ok := byNamespace[namespace]; !ok {
+			byNamespace[namespace] = []time.Duration{}
+		}
+		if d, ok := toReady(rec); ok {
+			all = append(all, d)
+			byNamespace[namespace] = append(byNamespace[namespace], d)
+		}
+	}
+
+	result := Result{
+		Overall:      stats(len(latest), all),
+		PerNamespace: map[string]Stats{},
+	}
+	for namespace, ds := range byNamespace {
+		result.PerNamespace[namespace] = stats(pods[namespace], ds)
+	}
+	return result
+}
+
+// Write prints the result as a table, overall first and then by namespace.
+func (r Result) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tPODS\tREADY\tP50\tP90\tP99") //nolint:errcheck
+	writeRow(tw, allScope, r.Overall)
+
+	namespaces := make([]string, 0, len(r.PerNamespace))
+	for namespace := range r.PerNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	slices.Sort(namespaces)
+	for _, namespace := range namespaces {
+		writeRow(tw, namespace, r.PerNamespace[namespace])
+	}
+	return tw.Flush()
+}
+
+func writeRow(w io.Writer, scope string, s Stats) {
+	fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\n", //nolint:errcheck
+		scope, s.Pods, s.Ready, fmtDuration(s, s.P50), fmtDuration(s, s.P90), fmtDuration(s, s.P99))
+}
+
+// fmtDuration prints "-" for the percentiles of a group without ready pods.
+func fmtDuration(s Stats, d time.Duration) string {
+	if s.Ready == 0 {
+		return "-"
+	}
+	return d.String()
+}
+
+// stats computes the statistics of pods pods, of which ds are ready.
+func stats(pods int, ds []time.Duration) Stats {
+	slices.Sort(ds)
+	return Stats{
+		Pods:  pods,
+		Ready: len(ds),
+		P50:   percentile(ds, 0.50),
+		P90:   percentile(ds, 0.90),
+		P99:   percentile(ds, 0.99),
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted, or zero when
+// it is empty.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// toReady parses the record's toReady duration. Records without one, or
+// with an empty or malformed one, report false.
+func toReady(rec controller.Record) (time.Duration, bool) {
+	durations, _ := rec["durations"].(map[string]interface{})
+	value, _ := durations["toReady"].(string)
+	if value == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+// podKey identifies the pod a record belongs to, preferring its UID.
+func podKey(rec controller.Record) string {
+	if uid, _ := rec["uid"].(string); uid != "" {
+		return uid
+	}
+	namespace, _ := rec["namespace"].(string)
+	pod, _ := rec["pod"].(string)
+	return namespace + "/" + pod
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyze
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("analyze", func() {
+	fixture := filepath.Join("testdata", "pod_startup_times.json")
+
+	It("should print percentiles overall and per namespace", func() {
+		var stdout, stderr bytes.Buffer
+		Expect(Main([]string{fixture}, &stdout, &stderr)).To(Equal(0), stderr.String())
+
+		lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+		Expect(lines).To(HaveLen(4))
+		Expect(strings.Fields(lines[0])).To(Equal([]string{"NAMESPACE", "PODS", "READY", "P50", "P90", "P99"}))
+		Expect(strings.Fields(lines[1])).To(Equal([]string{"*", "7", "5", "5s", "30s", "30s"}))
+		Expect(strings.Fields(lines[2])).To(Equal([]string{"team-a", "4", "4", "2s", "9s", "9s"}))
+		Expect(strings.Fields(lines[3])).To(Equal([]string{"team-b", "3", "1", "30s", "30s", "30s"}))
+	})
+
+	It("should return empty stats without records", func() {
+		Expect(Analyze(nil).Overall).To(Equal(Stats{}))
+	})
+
+	It("should print dashes for groups without ready pods", func() {
+		var buf bytes.Buffer
+		Expect(Result{Overall: Stats{Pods: 2}, PerNamespace: map[string]Stats{}}.Write(&buf)).To(Succeed())
+		Expect(strings.Fields(strings.Split(buf.String(), "\n")[1])).To(Equal([]string{"*", "2", "0", "-", "-", "-"}))
+	})
+
+	It("should use nearest-rank percentiles", func() {
+		ds := []time.Duration{}
+		for i := 1; i <= 100; i++ {
+			ds = append(ds, time.Duration(i)*time.Second)
+		}
+		s := stats(100, ds)
+		Expect(s.P50).To(Equal(50 * time.Second))
+		Expect(s.P90).To(Equal(90 * time.Second))
+		Expect(s.P99).To(Equal(99 * time.Second))
+	})
+
+	It("should fail on unreadable input and bad usage", func() {
+		var stdout, stderr bytes.Buffer
+		Expect(Main([]string{"testdata/missing.json"}, &stdout, &stderr)).To(Equal(1))
+		Expect(stderr.String()).To(ContainSubstring("missing.json"))
+
+		Expect(Main(nil, &stdout, &stderr)).To(Equal(2))
+		Expect(stderr.String()).To(ContainSubstring("Usage: analyze <file>"))
+	})
+})
@@ -0,0 +1,113 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ToReadyAnnotation is set on pods to their measured toReady duration when
+// AnnotatePods is enabled.
+const ToReadyAnnotation = "startup.measure/to-ready"
+
+// BaselineAnnotation overrides the creation timestamp as the start every
+// duration is measured from, for pods whose meaningful start is earlier, such
+// as a step in a larger workflow. The value is an RFC3339 timestamp.
+const BaselineAnnotation = "startup.measure/baseline"
+
+// DefaultIgnoreAnnotation opts a pod out of measurement when set to "true".
+const DefaultIgnoreAnnotation = "startup.measure/ignore"
+
+// conflictRequeueDelay is how long to wait before retrying a pod write that
+// lost a race with another writer.
+const conflictRequeueDelay = time.Second
+
+// measurementBaseline returns the time durations are measured from: the
+// BaselineAnnotation when it holds a valid timestamp, otherwise the start of
+// the container named container when it is set and has started, and the
+// pod's creation time otherwise.
+func measurementBaseline(ctx context.Context, pod corev1.Pod, container string) time.Time {
+	value, ok := pod.Annotations[BaselineAnnotation]
+	if !ok {
+		if container != "" {
+			return containerBaseline(ctx, pod, container)
+		}
+		return pod.CreationTimestamp.Time
+	}
+	baseline, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		logf.FromContext(ctx).Info("Ignoring invalid baseline annotation, measuring from creation",
+			"annotation", BaselineAnnotation, "value", value, "error", err.Error())
+		return pod.CreationTimestamp.Time
+	}
+	return baseline
+}
+
+// containerBaseline returns when the named container started, or the pod's
+// creation time while it hasn't. A container that restarted is measured
+// from its latest start.
+func containerBaseline(ctx context.Context, pod corev1.Pod, name string) time.Time {
+	inSpec := slices.ContainsFunc(pod.Spec.Containers, func(c corev1.Container) bool { return c.Name == name }) ||
+		slices.ContainsFunc(pod.Spec.InitContainers, func(c corev1.Container) bool { return c.Name == name })
+	if !inSpec {
+		// Pods of other workloads rarely have the container, so this is
+		// only of interest when debugging
+		logf.FromContext(ctx).V(1).Info("Baseline container is not in the pod spec, measuring from creation",
+			"container", name)
+		return pod.CreationTimestamp.Time
+	}
+
+	statuses := slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses)
+	for _, c := range statuses {
+		if c.Name != name {
+			continue
+		}
+		switch {
+		case c.State.Running != nil:
+			return c.State.Running.StartedAt.Time
+		case c.State.Terminated != nil && !c.State.Terminated.StartedAt.IsZero():
+			return c.State.Terminated.StartedAt.Time
+		}
+	}
+	return pod.CreationTimestamp.Time
+}
+
+// annotateToReady records the toReady duration on the pod itself. The patch
+// is skipped when the annotation already matches, since every patch triggers
+// another reconcile of the same pod.
+func (r *PodStartupReconciler) annotateToReady(ctx context.Context, pod *corev1.Pod, toReady string) error {
+	if pod.Annotations[ToReadyAnnotation] == toReady {
+		return nil
+	}
+
+	// A merge patch only touches our key, so other writers are left alone
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[ToReadyAnnotation] = toReady
+
+	ctx, cancel := r.clientContext(ctx)
+	defer cancel()
+	return r.Patch(ctx, pod, patch)
+}
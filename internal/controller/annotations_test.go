@@ -0,0 +1,243 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// podPatchCountingClient counts the patches issued against pods.
+type podPatchCountingClient struct {
+	client.Client
+	patches atomic.Int32
+}
+
+func (c *podPatchCountingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if _, ok := obj.(*corev1.Pod); ok {
+		c.patches.Add(1)
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+var _ = Describe("Pod annotation", func() {
+	readyStatus := func() corev1.PodStatus {
+		return corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()},
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()},
+			},
+		}
+	}
+
+	It("should annotate a ready pod once", func() {
+		ctx := context.Background()
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "annotated-pod", Namespace: "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-3 * time.Second)),
+			},
+			Spec: corev1.PodSpec{
+				NodeName:   "fake-node",
+				Containers: []corev1.Container{{Name: "c1", Image: "busybox"}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		Eventually(func() error {
+			var existing corev1.Pod
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pod), &existing); err != nil {
+				return err
+			}
+			existing.Status = readyStatus()
+			return k8sClient.Status().Update(ctx, &existing)
+		}, 5*time.Second, 500*time.Millisecond).Should(Succeed())
+
+		countingClient := &podPatchCountingClient{Client: k8sClient}
+		r := &PodStartupReconciler{Client: countingClient, Scheme: scheme.Scheme, AnnotatePods: true}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+
+		By("Reconciling the ready pod")
+		Eventually(func(g Gomega) {
+			_, err := r.Reconcile(ctx, req)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(countingClient.patches.Load()).To(BeEquivalentTo(1))
+		}, 10*time.Second, 500*time.Millisecond).Should(Succeed())
+
+		By("Waiting for the annotation to be visible")
+		Eventually(func(g Gomega) {
+			var existing corev1.Pod
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pod), &existing)).To(Succeed())
+			g.Expect(existing.Annotations).To(HaveKey(ToReadyAnnotation))
+		}, 10*time.Second, 500*time.Millisecond).Should(Succeed())
+
+		By("Reconciling again without a redundant patch")
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(countingClient.patches.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should requeue when the patch conflicts", func() {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "conflicted", Namespace: "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-3 * time.Second)),
+			},
+			Spec:   corev1.PodSpec{NodeName: "fake-node"},
+			Status: readyStatus(),
+		}
+		c := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(pod).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Patch: func(context.Context, client.WithWatch, client.Object, client.Patch, ...client.PatchOption) error {
+					return apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, pod.Name, nil)
+				},
+			}).
+			Build()
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, AnnotatePods: true}
+
+		result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(conflictRequeueDelay))
+	})
+})
+
+var _ = Describe("Baseline annotation", func() {
+	recordOf := func(pod *corev1.Pod) Record {
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+		return recorder.Records()[0]
+	}
+
+	It("should measure from a valid baseline", func() {
+		pod := newRunningPod("baseline-valid")
+		baseline := pod.CreationTimestamp.Add(-57 * time.Second)
+		pod.Annotations = map[string]string{BaselineAnnotation: baseline.Format(time.RFC3339)}
+
+		rec := recordOf(pod)
+		Expect(rec["durations"]).To(HaveKeyWithValue("toReady", "1m0s"))
+		Expect(rec["timestamps"]).To(HaveKeyWithValue("baseline", baseline.Format(time.RFC3339)))
+		Expect(rec["timestamps"]).To(HaveKeyWithValue("created", pod.CreationTimestamp.Format(time.RFC3339)))
+	})
+
+	It("should fall back to the creation time for an invalid baseline", func() {
+		pod := newRunningPod("baseline-invalid")
+		pod.Annotations = map[string]string{BaselineAnnotation: "yesterday"}
+
+		rec := recordOf(pod)
+		Expect(rec["durations"]).To(HaveKeyWithValue("toReady", "3s"))
+		Expect(rec["timestamps"]).NotTo(HaveKey("baseline"))
+	})
+
+	It("should measure from the creation time without the annotation", func() {
+		rec := recordOf(newRunningPod("baseline-absent"))
+		Expect(rec["durations"]).To(HaveKeyWithValue("toReady", "3s"))
+		Expect(rec["timestamps"]).NotTo(HaveKey("baseline"))
+	})
+})
+
+var _ = Describe("Baseline container", func() {
+	recordOf := func(pod *corev1.Pod) Record {
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{Sinks: []Sink{recorder}, BaselineContainer: "c1"}
+		_, err := reconcilePod(context.Background(), r, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+		return recorder.Records()[0]
+	}
+	withStatus := func(pod *corev1.Pod, state corev1.ContainerState) *corev1.Pod {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "c1", State: state}}
+		return pod
+	}
+
+	It("should measure from the container's start", func() {
+		pod := newRunningPod("baseline-container")
+		started := pod.CreationTimestamp.Add(2 * time.Second)
+		rec := recordOf(withStatus(pod, corev1.ContainerState{
+			Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(started)},
+		}))
+		Expect(rec["durations"]).To(HaveKeyWithValue("toReady", "1s"))
+		Expect(rec["timestamps"]).To(HaveKeyWithValue("baseline", started.Format(time.RFC3339)))
+	})
+
+	It("should fall back to the creation time until the container starts", func() {
+		pod := newRunningPod("baseline-container-waiting")
+		rec := recordOf(withStatus(pod, corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"},
+		}))
+		Expect(rec["durations"]).To(HaveKeyWithValue("toReady", "3s"))
+		Expect(rec["timestamps"]).NotTo(HaveKey("baseline"))
+	})
+
+	It("should fall back to the creation time for pods without the container", func() {
+		pod := newRunningPod("baseline-container-missing")
+		pod.Spec.Containers[0].Name = "other"
+		pod.Status.ContainerStatuses[0].Name = "other"
+		rec := recordOf(pod)
+		Expect(rec["durations"]).To(HaveKeyWithValue("toReady", "3s"))
+		Expect(rec["timestamps"]).NotTo(HaveKey("baseline"))
+	})
+})
+
+var _ = Describe("Ignore annotation", func() {
+	reconcileRecords := func(r *PodStartupReconciler, pod *corev1.Pod) []Record {
+		recorder := &recordingSink{}
+		r.Sinks = []Sink{recorder}
+		_, err := reconcilePod(context.Background(), r, pod)
+		Expect(err).NotTo(HaveOccurred())
+		return recorder.Records()
+	}
+
+	It("should skip pods that opted out", func() {
+		pod := newRunningPod("ignored")
+		pod.Annotations = map[string]string{DefaultIgnoreAnnotation: "true"}
+		r := &PodStartupReconciler{}
+		Expect(reconcileRecords(r, pod)).To(BeEmpty())
+		Expect(r.pods.pods).NotTo(HaveKey(client.ObjectKeyFromObject(pod)), "no state is kept for the pod")
+	})
+
+	It("should record pods without the annotation or with another value", func() {
+		Expect(reconcileRecords(&PodStartupReconciler{}, newRunningPod("measured"))).To(HaveLen(1))
+
+		pod := newRunningPod("not-ignored")
+		pod.Annotations = map[string]string{DefaultIgnoreAnnotation: "false"}
+		Expect(reconcileRecords(&PodStartupReconciler{}, pod)).To(HaveLen(1))
+	})
+
+	It("should honor a custom annotation", func() {
+		pod := newRunningPod("custom-ignored")
+		pod.Annotations = map[string]string{"example.com/skip-timing": "true"}
+		Expect(reconcileRecords(&PodStartupReconciler{IgnoreAnnotation: "example.com/skip-timing"}, pod)).To(BeEmpty())
+		Expect(reconcileRecords(&PodStartupReconciler{}, pod)).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,88 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Backfill", func() {
+	It("should record pods that existed before startup exactly once", func() {
+		ctx := context.Background()
+		names := []string{"backfill-a", "backfill-b"}
+
+		By("Pre-creating ready pods")
+		for _, name := range names {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec: corev1.PodSpec{
+					NodeName:   "fake-node",
+					Containers: []corev1.Container{{Name: "c1", Image: "busybox"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			pod.Status = corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()},
+					{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(time.Now().Add(time.Second))},
+				},
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "c1",
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.Now()}},
+				}},
+			}
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+		}
+
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), Sinks: []Sink{recorder}, Backfill: true}
+
+		// recordedNames returns the backfilled pods in the order they were
+		// recorded, ignoring pods created by other specs.
+		recordedNames := func() []string {
+			var got []string
+			for _, rec := range recorder.Records() {
+				for _, name := range names {
+					if rec["pod"] == name {
+						got = append(got, name)
+					}
+				}
+			}
+			return got
+		}
+
+		By("Backfilling")
+		Expect(r.BackfillExisting(ctx)).To(Succeed())
+		Expect(recordedNames()).To(ConsistOf(names))
+
+		By("Reconciling the unchanged pods as watch events would")
+		for _, name := range names {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: name}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(recordedNames()).To(ConsistOf(names), "backfilled pods must not be recorded twice")
+	})
+})
@@ -0,0 +1,150 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// batchBufferFactor sets the default cap of a batching sink's buffer, in
+// batches. It bounds the memory held while the destination is down.
+const batchBufferFactor = 10
+
+// batchLimits configures a batcher on each call, as the sinks embed it by
+// value and have no constructor to set it up.
+type batchLimits struct {
+	// sink labels the error and drop metrics.
+	sink string
+	// maxBatch buffered items trigger an early flush.
+	maxBatch int
+	// maxBuffered caps the buffer. Beyond it the oldest items are dropped.
+	maxBuffered int
+}
+
+// batchedItem is an item waiting in a batcher. failed is set once a batch
+// holding it failed to be written, so its retries aren't counted again.
+type batchedItem[T any] struct {
+	item   T
+	failed bool
+}
+
+// batcher buffers the items of a batching sink, such as the S3Sink, until
+// they are written out in one batch. run flushes every interval and once
+// maxBatch items are buffered, and the sink's Close flushes the rest. A
+// batch that fails to be written is put back, to be retried with the next.
+type batcher[T any] struct {
+	mu      sync.Mutex
+	pending []batchedItem[T]
+	full    chan struct{}
+}
+
+// add buffers item, waking run to flush early once maxBatch items are
+// buffered. It never blocks on the flush, so the reconcile isn't held up.
+func (b *batcher[T]) add(item T, limits batchLimits) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, batchedItem[T]{item: item})
+	b.trim(limits)
+	if len(b.pending) >= limits.maxBatch {
+		select {
+		case b.fullCh() <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run calls flush every interval and whenever the batch is full, until ctx
+// is done. Failures are logged with msg and keysAndValues.
+func (b *batcher[T]) run(ctx context.Context, interval time.Duration, flush func(context.Context) error, msg string, keysAndValues ...any) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	b.mu.Lock()
+	full := b.fullCh()
+	b.mu.Unlock()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-full:
+		}
+		if err := flush(ctx); err != nil {
+			logf.FromContext(ctx).Error(err, msg, keysAndValues...)
+		}
+	}
+}
+
+// flush passes the buffered items to write as one batch. On failure they
+// are put back ahead of the items buffered meanwhile, keeping their order,
+// and those failing for the first time are counted on the error metric.
+func (b *batcher[T]) flush(ctx context.Context, limits batchLimits, write func(context.Context, []T) error) error {
+	b.mu.Lock()
+	if len(b.pending) == 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	batch := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	items := make([]T, len(batch))
+	for i, pending := range batch {
+		items[i] = pending.item
+	}
+	if err := write(ctx, items); err != nil {
+		failed := 0
+		for i := range batch {
+			if !batch[i].failed {
+				batch[i].failed = true
+				failed++
+			}
+		}
+		sinkErrorsTotal.WithLabelValues(limits.sink).Add(float64(failed))
+
+		b.mu.Lock()
+		b.pending = append(batch, b.pending...)
+		b.trim(limits)
+		b.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// trim drops the oldest items beyond maxBuffered. Callers must hold mu.
+func (b *batcher[T]) trim(limits batchLimits) {
+	if limits.maxBuffered <= 0 || len(b.pending) <= limits.maxBuffered {
+		return
+	}
+	dropped := len(b.pending) - limits.maxBuffered
+	sinkRecordsDroppedTotal.WithLabelValues(limits.sink).Add(float64(dropped))
+	b.pending = slices.Delete(b.pending, 0, dropped)
+}
+
+// fullCh returns the channel add uses to request an early flush. Callers
+// must hold mu.
+func (b *batcher[T]) fullCh() chan struct{} {
+	if b.full == nil {
+		b.full = make(chan struct{}, 1)
+	}
+	return b.full
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("batcher", func() {
+	unavailable := func(context.Context, []int) error { return errors.New("unavailable") }
+	written := func(b *batcher[int], limits batchLimits) []int {
+		var batch []int
+		Expect(b.flush(context.Background(), limits, func(_ context.Context, items []int) error {
+			batch = items
+			return nil
+		})).To(Succeed())
+		return batch
+	}
+
+	It("should put a failed batch back ahead of the items added meanwhile", func() {
+		limits := batchLimits{sink: "batcher-order", maxBatch: 10}
+		var b batcher[int]
+		b.add(1, limits)
+		b.add(2, limits)
+
+		err := b.flush(context.Background(), limits, func(context.Context, []int) error {
+			b.add(3, limits)
+			return errors.New("unavailable")
+		})
+		Expect(err).To(MatchError("unavailable"))
+		Expect(written(&b, limits)).To(Equal([]int{1, 2, 3}))
+	})
+
+	It("should not call write without buffered items", func() {
+		var b batcher[int]
+		Expect(b.flush(context.Background(), batchLimits{sink: "batcher-empty"}, func(context.Context, []int) error {
+			Fail("write called for an empty batch")
+			return nil
+		})).To(Succeed())
+	})
+
+	It("should count each item's failure once across retries", func() {
+		limits := batchLimits{sink: "batcher-retries", maxBatch: 10}
+		var b batcher[int]
+		b.add(1, limits)
+		Expect(b.flush(context.Background(), limits, unavailable)).NotTo(Succeed())
+		b.add(2, limits)
+		Expect(b.flush(context.Background(), limits, unavailable)).NotTo(Succeed())
+		Expect(b.flush(context.Background(), limits, unavailable)).NotTo(Succeed())
+
+		Expect(testutil.ToFloat64(sinkErrorsTotal.WithLabelValues("batcher-retries"))).To(Equal(2.0))
+		Expect(written(&b, limits)).To(Equal([]int{1, 2}))
+	})
+
+	It("should drop the oldest items beyond maxBuffered", func() {
+		limits := batchLimits{sink: "batcher-capped", maxBatch: 2, maxBuffered: 3}
+		var b batcher[int]
+		for i := range 3 {
+			b.add(i, limits)
+		}
+		Expect(b.flush(context.Background(), limits, unavailable)).NotTo(Succeed())
+		b.add(3, limits)
+		b.add(4, limits)
+
+		Expect(testutil.ToFloat64(sinkRecordsDroppedTotal.WithLabelValues("batcher-capped"))).To(Equal(2.0))
+		Expect(written(&b, limits)).To(Equal([]int{2, 3, 4}))
+	})
+})
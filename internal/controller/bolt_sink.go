@@ -0,0 +1,228 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltOpenTimeout bounds waiting for another process to release the
+// database file.
+const boltOpenTimeout = 5 * time.Second
+
+var (
+	// boltRecordsBucket holds the latest record of each pod as JSON, keyed
+	// like the RecordStore.
+	boltRecordsBucket = []byte("records")
+	// boltCreatedBucket indexes the records by pod creation time: each key
+	// is the creation time in big-endian Unix nanoseconds followed by the
+	// record's key, so a cursor walks the records in creation order.
+	boltCreatedBucket = []byte("created")
+)
+
+// BoltQuery selects records from a BoltSink. Zero fields don't filter.
+type BoltQuery struct {
+	// Namespace restricts the result to pods in this namespace.
+	Namespace string
+	// CreatedFrom and CreatedTo restrict the result to pods created in
+	// [CreatedFrom, CreatedTo). Pods without a creation time are only
+	// returned when neither is set.
+	CreatedFrom, CreatedTo time.Time
+	// Limit caps the number of records returned.
+	Limit int
+}
+
+// ranged reports whether the query selects by creation time.
+func (q BoltQuery) ranged() bool {
+	return !q.CreatedFrom.IsZero() || !q.CreatedTo.IsZero()
+}
+
+// matches reports whether rec is selected by q, ignoring Limit. It is how
+// queries are answered without a BoltSink.
+func (q BoltQuery) matches(rec Record) bool {
+	if q.Namespace != "" && recordString(rec, "namespace") != q.Namespace {
+		return false
+	}
+	if !q.ranged() {
+		return true
+	}
+	created := recordTimestamp(rec, "created")
+	return !created.IsZero() && !created.Before(q.CreatedFrom) &&
+		(q.CreatedTo.IsZero() || created.Before(q.CreatedTo))
+}
+
+// BoltSink keeps the latest record of every pod in an embedded bbolt
+// database, a durable store for single-node deployments that needs no cgo.
+// Writes and queries each run in a transaction, so the sink can be used
+// concurrently; bbolt locks the file against other processes.
+type BoltSink struct {
+	db *bolt.DB
+}
+
+// NewBoltSink opens the database at path, creating it if needed.
+func NewBoltSink(path string) (*BoltSink, error) {
+	db, err := bolt.Open(path, DefaultFileMode, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltRecordsBucket, boltCreatedBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close() //nolint:errcheck
+		return nil, fmt.Errorf("creating buckets in %s: %w", path, err)
+	}
+	return &BoltSink{db: db}, nil
+}
+
+// Name implements Sink.
+func (s *BoltSink) Name() string { return "bolt" }
+
+// Write implements Sink, replacing any earlier record of the pod.
+func (s *BoltSink) Write(_ context.Context, rec Record) error {
+	value, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshalling record: %w", err)
+	}
+	key := []byte(storeKey(rec))
+	return s.db.Update(func(tx *bolt.Tx) error {
+		records, index := tx.Bucket(boltRecordsBucket), tx.Bucket(boltCreatedBucket)
+		// The creation time of a pod doesn't change, but drop the old
+		// index entry in case an earlier record had none
+		if old := records.Get(key); old != nil {
+			var prev Record
+			if err := json.Unmarshal(old, &prev); err == nil {
+				if k := createdKey(prev, key); k != nil {
+					if err := index.Delete(k); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		if err := records.Put(key, value); err != nil {
+			return err
+		}
+		if k := createdKey(rec, key); k != nil {
+			return index.Put(k, nil)
+		}
+		return nil
+	})
+}
+
+// Query returns the latest records matching q, in creation order when q
+// selects by creation time and by key otherwise.
+func (s *BoltSink) Query(_ context.Context, q BoltQuery) ([]Record, error) {
+	var result []Record
+	// add decodes value and keeps it if it matches, reporting whether the
+	// limit is reached
+	add := func(value []byte) (bool, error) {
+		var rec Record
+		if err := json.Unmarshal(value, &rec); err != nil {
+			return false, fmt.Errorf("decoding record: %w", err)
+		}
+		if q.Namespace == "" || recordString(rec, "namespace") == q.Namespace {
+			result = append(result, rec)
+		}
+		return q.Limit > 0 && len(result) >= q.Limit, nil
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		records := tx.Bucket(boltRecordsBucket)
+		if !q.ranged() {
+			c := records.Cursor()
+			for k, v := c.First(); k != nil; k, v = c.Next() {
+				if done, err := add(v); done || err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		var end []byte
+		if !q.CreatedTo.IsZero() {
+			end = createdPrefix(q.CreatedTo)
+		}
+		c := tx.Bucket(boltCreatedBucket).Cursor()
+		for k, _ := c.Seek(createdPrefix(q.CreatedFrom)); k != nil; k, _ = c.Next() {
+			if end != nil && bytes.Compare(k, end) >= 0 {
+				break
+			}
+			v := records.Get(k[8:])
+			if v == nil {
+				continue
+			}
+			if done, err := add(v); done || err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Clear implements ClearingSink.
+func (s *BoltSink) Clear(_ context.Context) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltRecordsBucket, boltCreatedBucket} {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close implements ClosingSink.
+func (s *BoltSink) Close(_ context.Context) error {
+	return s.db.Close()
+}
+
+// createdKey returns the index key of the record stored under key, or nil
+// when the record has no creation time.
+func createdKey(rec Record, key []byte) []byte {
+	created := recordTimestamp(rec, "created")
+	if created.IsZero() {
+		return nil
+	}
+	return append(createdPrefix(created), key...)
+}
+
+// createdPrefix encodes t so that index keys sort by time. Times before the
+// Unix epoch sort first.
+func createdPrefix(t time.Time) []byte {
+	prefix := make([]byte, 8)
+	binary.BigEndian.PutUint64(prefix, uint64(max(t.UnixNano(), 0)))
+	return prefix
+}
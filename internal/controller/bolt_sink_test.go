@@ -0,0 +1,180 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BoltSink", func() {
+	var (
+		ctx  context.Context
+		path string
+		sink *BoltSink
+	)
+	created := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	// boltRecord is a record of pod created offset after created.
+	boltRecord := func(namespace, pod string, offset time.Duration) Record {
+		return Record{
+			"pod": pod, "namespace": namespace, "uid": "uid-" + pod,
+			"timestamps": map[string]string{"created": fmtTime(created.Add(offset))},
+		}
+	}
+	pods := func(records []Record) []string {
+		var names []string
+		for _, rec := range records {
+			names = append(names, recordString(rec, "pod"))
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		path = filepath.Join(GinkgoT().TempDir(), "records.db")
+		var err error
+		sink, err = NewBoltSink(path)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = sink.Close(ctx) })
+	})
+
+	It("should keep the latest record of each pod", func() {
+		first := boltRecord("default", "a", 0)
+		first["phase"] = "Pending"
+		latest := boltRecord("default", "a", 0)
+		latest["phase"] = "Running"
+		Expect(sink.Write(ctx, first)).To(Succeed())
+		Expect(sink.Write(ctx, latest)).To(Succeed())
+		Expect(sink.Write(ctx, boltRecord("kube-system", "b", time.Minute))).To(Succeed())
+
+		records, err := sink.Query(ctx, BoltQuery{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods(records)).To(Equal([]string{"a", "b"}))
+		Expect(recordString(records[0], "phase")).To(Equal("Running"))
+
+		records, err = sink.Query(ctx, BoltQuery{Namespace: "kube-system"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods(records)).To(Equal([]string{"b"}))
+	})
+
+	It("should scan a range of creation times in order", func() {
+		for i, pod := range []string{"d", "c", "b", "a"} {
+			Expect(sink.Write(ctx, boltRecord("default", pod, time.Duration(i)*time.Minute))).To(Succeed())
+		}
+		Expect(sink.Write(ctx, Record{"pod": "uncreated", "namespace": "default", "uid": "uid-uncreated"})).To(Succeed())
+
+		records, err := sink.Query(ctx, BoltQuery{CreatedFrom: created.Add(time.Minute), CreatedTo: created.Add(3 * time.Minute)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods(records)).To(Equal([]string{"c", "b"}), "the end of the range is exclusive")
+
+		records, err = sink.Query(ctx, BoltQuery{CreatedFrom: created.Add(time.Minute), Limit: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods(records)).To(Equal([]string{"c", "b"}))
+
+		records, err = sink.Query(ctx, BoltQuery{CreatedTo: created.Add(time.Minute)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods(records)).To(Equal([]string{"d"}))
+
+		records, err = sink.Query(ctx, BoltQuery{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(5), "records without a creation time are only left out of ranges")
+	})
+
+	It("should keep records across reopening", func() {
+		Expect(sink.Write(ctx, boltRecord("default", "a", 0))).To(Succeed())
+		Expect(sink.Close(ctx)).To(Succeed())
+
+		var err error
+		sink, err = NewBoltSink(path)
+		Expect(err).NotTo(HaveOccurred())
+		records, err := sink.Query(ctx, BoltQuery{CreatedFrom: created})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods(records)).To(Equal([]string{"a"}))
+	})
+
+	It("should take concurrent writes", func() {
+		var wg sync.WaitGroup
+		for i := range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+				Expect(sink.Write(ctx, boltRecord("default", fmt.Sprintf("pod-%02d", i), time.Duration(i)*time.Second))).To(Succeed())
+			}()
+		}
+		wg.Wait()
+
+		records, err := sink.Query(ctx, BoltQuery{CreatedFrom: created})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(20))
+		Expect(recordString(records[19], "pod")).To(Equal("pod-19"))
+	})
+
+	It("should clear every record", func() {
+		Expect(sink.Write(ctx, boltRecord("default", "a", 0))).To(Succeed())
+		Expect(sink.Clear(ctx)).To(Succeed())
+
+		for _, q := range []BoltQuery{{}, {CreatedFrom: created}} {
+			records, err := sink.Query(ctx, q)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(records).To(BeEmpty())
+		}
+	})
+
+	It("should fill the query store on startup", func() {
+		Expect(sink.Write(ctx, boltRecord("default", "a", 0))).To(Succeed())
+		Expect(sink.Write(ctx, boltRecord("default", "b", 0))).To(Succeed())
+
+		r := &PodStartupReconciler{Sinks: []Sink{sink}, Store: NewRecordStore()}
+		Expect(r.loadStore(ctx)).To(Succeed())
+		Expect(pods(r.Store.List())).To(ConsistOf("a", "b"))
+	})
+
+	It("should answer the filters of GET /pods", func() {
+		for i, pod := range []string{"a", "b", "c"} {
+			Expect(sink.Write(ctx, boltRecord("default", pod, time.Duration(i)*time.Minute))).To(Succeed())
+		}
+		Expect(sink.Write(ctx, boltRecord("kube-system", "d", time.Minute))).To(Succeed())
+		// The store holds none of them, so only the sink can answer
+		store := NewRecordStore()
+		store.Put(storedPod("in-memory", "Running", "1s"))
+		server := newQueryServerRunnable("", store, "", nil)
+		server.serveBolt(store, sink)
+
+		var page PodPage
+		target := fmt.Sprintf("/pods?namespace=default&createdFrom=%s&createdTo=%s",
+			fmtTime(created.Add(time.Minute)), fmtTime(created.Add(3*time.Minute)))
+		Expect(getJSON(server.server.Handler, target, &page).Code).To(Equal(http.StatusOK))
+		Expect(pods(page.Items)).To(Equal([]string{"b", "c"}))
+		Expect(page.Total).To(Equal(2))
+
+		Expect(getJSON(server.server.Handler, "/pods?limit=1&namespace=kube-system", &page).Code).To(Equal(http.StatusOK))
+		Expect(pods(page.Items)).To(Equal([]string{"d"}))
+
+		By("Listing the store when nothing is filtered")
+		Expect(getJSON(server.server.Handler, "/pods", &page).Code).To(Equal(http.StatusOK))
+		Expect(pods(page.Items)).To(Equal([]string{"in-memory"}))
+	})
+})
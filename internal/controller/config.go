@@ -0,0 +1,534 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// ClusterNameEnv names the environment variable holding the default
+// ClusterName.
+const ClusterNameEnv = "CLUSTER_NAME"
+
+// PodNamespaceEnv names the environment variable holding the namespace the
+// controller runs in, set through the downward API.
+const PodNamespaceEnv = "POD_NAMESPACE"
+
+// DefaultKafkaTopic is the topic records are produced to unless configured.
+const DefaultKafkaTopic = "pod-startup-times"
+
+// Config holds everything the reconciler and its sinks can be configured
+// with, so it can be loaded from a single file such as a mounted ConfigMap.
+type Config struct {
+	// Sinks configures where records are written.
+	Sinks SinksConfig `json:"sinks"`
+
+	// Rollup configures periodic summaries of the file sink's records.
+	Rollup RollupConfig `json:"rollup"`
+
+	// The fields below configure the PodStartupReconciler field of the
+	// same name.
+
+	MinCompleteness    Completeness    `json:"minCompleteness,omitempty"`
+	DryRun             bool            `json:"dryRun,omitempty"`
+	FailHard           bool            `json:"failHard,omitempty"`
+	MaxBackoff         metav1.Duration `json:"maxBackoff,omitempty"`
+	ClientTimeout      metav1.Duration `json:"clientTimeout,omitempty"`
+	PollInterval       metav1.Duration `json:"pollInterval,omitempty"`
+	DebounceWindow     metav1.Duration `json:"debounceWindow,omitempty"`
+	ExemplarThreshold  metav1.Duration `json:"exemplarThreshold,omitempty"`
+	UnhealthyAfter     int             `json:"unhealthyAfter,omitempty"`
+	Backfill           bool            `json:"backfill,omitempty"`
+	TerminalIgnoreAge  metav1.Duration `json:"terminalIgnoreAge,omitempty"`
+	TerminalOnly       bool            `json:"terminalOnly,omitempty"`
+	EnrichNodeInfo     bool            `json:"enrichNodeInfo,omitempty"`
+	NodeInfoTTL        metav1.Duration `json:"nodeInfoTTL,omitempty"`
+	GRPCBindAddress    string          `json:"grpcBindAddress,omitempty"`
+	QueryBindAddress   string          `json:"queryBindAddress,omitempty"`
+	RecordMeasurements bool            `json:"recordMeasurements,omitempty"`
+	AnnotatePods       bool            `json:"annotatePods,omitempty"`
+
+	// RecordOnlyWhenComplete holds pods back for up to MaxWait until they
+	// are Ready or finished.
+	RecordOnlyWhenComplete bool            `json:"recordOnlyWhenComplete,omitempty"`
+	MaxWait                metav1.Duration `json:"maxWait,omitempty"`
+
+	// ToReadyWindow is the window of the windowed time to ready p99.
+	ToReadyWindow metav1.Duration `json:"toReadyWindow,omitempty"`
+
+	// EnrichJobInfo adds the times of the pod's Job to its records.
+	EnrichJobInfo bool `json:"enrichJobInfo,omitempty"`
+
+	// IgnoreAnnotation opts pods annotated with it set to "true" out.
+	IgnoreAnnotation string `json:"ignoreAnnotation,omitempty"`
+
+	// MaxConcurrentReconciles is how many pods are reconciled in parallel.
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty"`
+
+	// ClusterName is added to every record as cluster.
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// BaselineContainer measures durations from this container's start.
+	BaselineContainer string `json:"baselineContainer,omitempty"`
+
+	// CustomDurations declares extra durations between named timestamps.
+	CustomDurations []DurationSpec `json:"customDurations,omitempty"`
+
+	// SampleRate is the fraction of pods recorded, from 0 (exclusive) to 1.
+	SampleRate float64 `json:"sampleRate,omitempty"`
+
+	// PerNamespaceRateLimit caps the records per second of each namespace.
+	PerNamespaceRateLimit float64 `json:"perNamespaceRateLimit,omitempty"`
+
+	// OwnerKinds restricts recording to pods whose top-level controller is
+	// one of these kinds.
+	OwnerKinds []string `json:"ownerKinds,omitempty"`
+
+	// Phases restricts recording to pods in these phases.
+	Phases []corev1.PodPhase `json:"phases,omitempty"`
+
+	// CaptureLabels lists the pod labels copied into each record.
+	CaptureLabels []string `json:"captureLabels,omitempty"`
+
+	// IncludeRawConditions embeds the raw pod conditions in every record.
+	IncludeRawConditions bool `json:"includeRawConditions,omitempty"`
+
+	// QuerySocketPath also serves the read-only query API on a Unix socket.
+	QuerySocketPath string `json:"querySocketPath,omitempty"`
+
+	// ResetTokenFile is a file, such as a mounted Secret, holding the bearer
+	// token that enables POST /reset on the query server.
+	ResetTokenFile string `json:"resetTokenFile,omitempty"`
+
+	// ServerTLS serves the query and gRPC servers over TLS when set.
+	ServerTLS ServerTLS `json:"serverTLS,omitempty"`
+
+	// WatchNamespace restricts the controller to pods in one namespace, so
+	// it can run with a Role instead of a ClusterRole. Node enrichment
+	// still needs cluster-wide read access to nodes.
+	WatchNamespace string `json:"watchNamespace,omitempty"`
+}
+
+// SinksConfig enables and configures each sink.
+type SinksConfig struct {
+	File    FileSinkConfig    `json:"file"`
+	Kafka   KafkaSinkConfig   `json:"kafka"`
+	S3      S3SinkConfig      `json:"s3"`
+	Influx  InfluxSinkConfig  `json:"influx"`
+	Parquet ParquetSinkConfig `json:"parquet"`
+	OTLP    OTLPSinkConfig    `json:"otlp"`
+	Bolt    BoltSinkConfig    `json:"bolt"`
+}
+
+// FileSinkConfig configures the FileSink.
+type FileSinkConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Path     string `json:"path,omitempty"`
+	Dir      string `json:"dir,omitempty"`
+	Compress bool   `json:"compress,omitempty"`
+	Mode     string `json:"mode,omitempty"`
+	Format   string `json:"format,omitempty"`
+	Compact  bool   `json:"compact,omitempty"`
+}
+
+// KafkaSinkConfig configures the KafkaSink.
+type KafkaSinkConfig struct {
+	Enabled      bool            `json:"enabled"`
+	Brokers      []string        `json:"brokers,omitempty"`
+	Topic        string          `json:"topic,omitempty"`
+	FlushTimeout metav1.Duration `json:"flushTimeout,omitempty"`
+}
+
+// S3SinkConfig configures the S3Sink. Credentials always come from the
+// standard AWS chain.
+type S3SinkConfig struct {
+	Enabled       bool            `json:"enabled"`
+	Bucket        string          `json:"bucket,omitempty"`
+	Prefix        string          `json:"prefix,omitempty"`
+	Region        string          `json:"region,omitempty"`
+	Endpoint      string          `json:"endpoint,omitempty"`
+	FlushInterval metav1.Duration `json:"flushInterval,omitempty"`
+	MaxBatch      int             `json:"maxBatch,omitempty"`
+}
+
+// InfluxSinkConfig configures the InfluxSink. The token is read from
+// TokenFile, such as a mounted Secret, so it stays out of the config.
+type InfluxSinkConfig struct {
+	Enabled       bool            `json:"enabled"`
+	URL           string          `json:"url,omitempty"`
+	TokenFile     string          `json:"tokenFile,omitempty"`
+	FlushInterval metav1.Duration `json:"flushInterval,omitempty"`
+	MaxBatch      int             `json:"maxBatch,omitempty"`
+}
+
+// ParquetSinkConfig configures the ParquetSink.
+type ParquetSinkConfig struct {
+	Enabled       bool            `json:"enabled"`
+	Dir           string          `json:"dir,omitempty"`
+	FlushInterval metav1.Duration `json:"flushInterval,omitempty"`
+	MaxRows       int             `json:"maxRows,omitempty"`
+}
+
+// BoltSinkConfig configures the BoltSink.
+type BoltSinkConfig struct {
+	Enabled bool   `json:"enabled"`
+	Path    string `json:"path,omitempty"`
+}
+
+// OTLPSinkConfig configures the OTLPLogSink.
+type OTLPSinkConfig struct {
+	Enabled       bool            `json:"enabled"`
+	Endpoint      string          `json:"endpoint,omitempty"`
+	FlushInterval metav1.Duration `json:"flushInterval,omitempty"`
+	MaxBatch      int             `json:"maxBatch,omitempty"`
+}
+
+// RollupConfig configures the Rollup of the file sink.
+type RollupConfig struct {
+	Enabled  bool            `json:"enabled"`
+	Path     string          `json:"path,omitempty"`
+	Interval metav1.Duration `json:"interval,omitempty"`
+	Prune    bool            `json:"prune,omitempty"`
+}
+
+// DefaultConfig returns the configuration used when no file is given: only
+// the file sink, at PodStartupLogPath or partitioned into LogDirEnv when
+// that is set and with the mode from FileModeEnv, and the cluster name from
+// ClusterNameEnv.
+func DefaultConfig() Config {
+	return Config{
+		Sinks: SinksConfig{
+			File: FileSinkConfig{
+				Enabled: true,
+				Path:    PodStartupLogPath,
+				Dir:     os.Getenv(LogDirEnv),
+				Mode:    os.Getenv(FileModeEnv),
+			},
+			Kafka: KafkaSinkConfig{
+				Topic:        DefaultKafkaTopic,
+				FlushTimeout: metav1.Duration{Duration: DefaultKafkaFlushTimeout},
+			},
+			S3: S3SinkConfig{
+				FlushInterval: metav1.Duration{Duration: DefaultS3FlushInterval},
+				MaxBatch:      DefaultS3MaxBatch,
+			},
+			Influx: InfluxSinkConfig{
+				FlushInterval: metav1.Duration{Duration: DefaultInfluxFlushInterval},
+				MaxBatch:      DefaultInfluxMaxBatch,
+			},
+			Parquet: ParquetSinkConfig{
+				FlushInterval: metav1.Duration{Duration: DefaultParquetFlushInterval},
+				MaxRows:       DefaultParquetMaxRows,
+			},
+			OTLP: OTLPSinkConfig{
+				FlushInterval: metav1.Duration{Duration: DefaultOTLPFlushInterval},
+				MaxBatch:      DefaultOTLPMaxBatch,
+			},
+		},
+		Rollup: RollupConfig{
+			Interval: metav1.Duration{Duration: DefaultRollupInterval},
+		},
+		MinCompleteness:         CompletenessScheduled,
+		MaxBackoff:              metav1.Duration{Duration: DefaultMaxBackoff},
+		MaxWait:                 metav1.Duration{Duration: DefaultMaxWait},
+		ClientTimeout:           metav1.Duration{Duration: DefaultClientTimeout},
+		ExemplarThreshold:       metav1.Duration{Duration: DefaultExemplarThreshold},
+		ToReadyWindow:           metav1.Duration{Duration: DefaultToReadyWindow},
+		IgnoreAnnotation:        DefaultIgnoreAnnotation,
+		UnhealthyAfter:          DefaultUnhealthyAfter,
+		NodeInfoTTL:             metav1.Duration{Duration: DefaultNodeInfoTTL},
+		GRPCBindAddress:         "0",
+		QueryBindAddress:        "0",
+		ClusterName:             os.Getenv(ClusterNameEnv),
+		MaxConcurrentReconciles: 1,
+		SampleRate:              1,
+	}
+}
+
+// LoadConfig reads a YAML or JSON config file over DefaultConfig, so any
+// field the file leaves out keeps its default. A missing file yields the
+// defaults; unknown fields are rejected. The result is not validated, so that
+// callers can override fields first.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("reading config: %w", err)
+	}
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate reports settings that can't work.
+func (c Config) Validate() error {
+	var errs []error
+	switch c.MinCompleteness {
+	case CompletenessScheduled, CompletenessInitialized, CompletenessReady:
+	default:
+		errs = append(errs, fmt.Errorf("minCompleteness: unknown value %q", c.MinCompleteness))
+	}
+	if c.Sinks.File.Enabled && c.Sinks.File.Path == "" && c.Sinks.File.Dir == "" {
+		errs = append(errs, errors.New("sinks.file: path or dir is required"))
+	}
+	if c.Sinks.File.Mode != "" {
+		if _, err := ParseFileMode(c.Sinks.File.Mode); err != nil {
+			errs = append(errs, fmt.Errorf("sinks.file: mode: %w", err))
+		}
+	}
+	for _, phase := range c.Phases {
+		switch phase {
+		case corev1.PodPending, corev1.PodRunning, corev1.PodSucceeded, corev1.PodFailed, corev1.PodUnknown:
+		default:
+			errs = append(errs, fmt.Errorf("phases: unknown phase %q", phase))
+		}
+	}
+	switch c.Sinks.File.Format {
+	case "", FileFormatJSON, FileFormatJSONLines:
+	default:
+		errs = append(errs, fmt.Errorf("sinks.file: format: unknown value %q", c.Sinks.File.Format))
+	}
+	if c.Sinks.Kafka.Enabled {
+		if len(c.Sinks.Kafka.Brokers) == 0 {
+			errs = append(errs, errors.New("sinks.kafka: brokers are required"))
+		}
+		if c.Sinks.Kafka.Topic == "" {
+			errs = append(errs, errors.New("sinks.kafka: topic is required"))
+		}
+	}
+	if c.Sinks.S3.Enabled && c.Sinks.S3.Bucket == "" {
+		errs = append(errs, errors.New("sinks.s3: bucket is required"))
+	}
+	if c.Sinks.Influx.Enabled && c.Sinks.Influx.URL == "" {
+		errs = append(errs, errors.New("sinks.influx: url is required"))
+	}
+	if c.Sinks.Parquet.Enabled && c.Sinks.Parquet.Dir == "" {
+		errs = append(errs, errors.New("sinks.parquet: dir is required"))
+	}
+	if c.Sinks.OTLP.Enabled && c.Sinks.OTLP.Endpoint == "" {
+		errs = append(errs, errors.New("sinks.otlp: endpoint is required"))
+	}
+	if c.Sinks.Bolt.Enabled && c.Sinks.Bolt.Path == "" {
+		errs = append(errs, errors.New("sinks.bolt: path is required"))
+	}
+	if c.SampleRate <= 0 || c.SampleRate > 1 {
+		errs = append(errs, fmt.Errorf("sampleRate: %v is not in (0, 1]", c.SampleRate))
+	}
+	if err := c.ServerTLS.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("serverTLS: %w", err))
+	}
+	if c.PerNamespaceRateLimit < 0 {
+		errs = append(errs, fmt.Errorf("perNamespaceRateLimit: %v is negative", c.PerNamespaceRateLimit))
+	}
+	names := map[string]bool{}
+	for i, spec := range c.CustomDurations {
+		if err := spec.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("customDurations[%d]: %w", i, err))
+		} else if names[spec.Name] {
+			errs = append(errs, fmt.Errorf("customDurations[%d]: duplicate name %q", i, spec.Name))
+		}
+		names[spec.Name] = true
+	}
+	if c.Rollup.Enabled && !c.Sinks.File.Enabled {
+		errs = append(errs, errors.New("rollup: requires sinks.file"))
+	}
+	return errors.Join(errs...)
+}
+
+// BuildSinks constructs the enabled sinks, in a fixed order.
+func (c Config) BuildSinks() ([]Sink, error) {
+	var sinks []Sink
+	if f := c.Sinks.File; f.Enabled {
+		sink := &FileSink{
+			Path:           f.Path,
+			Dir:            f.Dir,
+			CompressOutput: f.Compress,
+			JSONLines:      f.Format == FileFormatJSONLines,
+			CompactJSON:    f.Compact,
+		}
+		if f.Mode != "" {
+			mode, err := ParseFileMode(f.Mode)
+			if err != nil {
+				return nil, fmt.Errorf("sinks.file: mode: %w", err)
+			}
+			sink.FileMode = mode
+		}
+		sinks = append(sinks, sink)
+	}
+	if k := c.Sinks.Kafka; k.Enabled {
+		sinks = append(sinks, NewKafkaSink(k.Brokers, k.Topic, k.FlushTimeout.Duration))
+	}
+	if o := c.Sinks.S3; o.Enabled {
+		sink, err := NewS3Sink(context.Background(), o.Bucket, o.Prefix, o.Region, o.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("sinks.s3: %w", err)
+		}
+		sink.FlushInterval = o.FlushInterval.Duration
+		sink.MaxBatch = o.MaxBatch
+		sinks = append(sinks, sink)
+	}
+	if i := c.Sinks.Influx; i.Enabled {
+		sink := &InfluxSink{URL: i.URL, FlushInterval: i.FlushInterval.Duration, MaxBatch: i.MaxBatch}
+		if i.TokenFile != "" {
+			token, err := readTokenFile(i.TokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("sinks.influx: tokenFile: %w", err)
+			}
+			sink.Token = token
+		}
+		sinks = append(sinks, sink)
+	}
+	if p := c.Sinks.Parquet; p.Enabled {
+		sinks = append(sinks, &ParquetSink{Dir: p.Dir, FlushInterval: p.FlushInterval.Duration, MaxRows: p.MaxRows})
+	}
+	if o := c.Sinks.OTLP; o.Enabled {
+		sink, err := NewOTLPLogSink(context.Background(), o.Endpoint, o.FlushInterval.Duration, o.MaxBatch)
+		if err != nil {
+			return nil, fmt.Errorf("sinks.otlp: %w", err)
+		}
+		sinks = append(sinks, sink)
+	}
+	if b := c.Sinks.Bolt; b.Enabled {
+		sink, err := NewBoltSink(b.Path)
+		if err != nil {
+			return nil, fmt.Errorf("sinks.bolt: %w", err)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// readTokenFile returns the token held in path, ignoring surrounding
+// whitespace. An empty file is an error.
+func readTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return token, nil
+}
+
+// CacheOptions returns the manager cache options for the configuration,
+// restricting the cache to WatchNamespace when it is set.
+func (c Config) CacheOptions() cache.Options {
+	if c.WatchNamespace == "" {
+		return cache.Options{}
+	}
+	return cache.Options{DefaultNamespaces: map[string]cache.Config{c.WatchNamespace: {}}}
+}
+
+// NewReconciler builds a reconciler from the configuration.
+func (c Config) NewReconciler(cl client.Client, scheme *runtime.Scheme) (*PodStartupReconciler, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	sinks, err := c.BuildSinks()
+	if err != nil {
+		return nil, err
+	}
+	if len(sinks) == 0 {
+		// Without this the reconciler would fall back to the default file
+		sinks = []Sink{discardSink{}}
+	}
+	var rollup *Rollup
+	if c.Rollup.Enabled {
+		// Validate made sure the file sink is enabled, and it is built first
+		rollup = &Rollup{
+			Sink:     sinks[0].(*FileSink),
+			Path:     c.Rollup.Path,
+			Interval: c.Rollup.Interval.Duration,
+			Prune:    c.Rollup.Prune,
+		}
+	}
+	var resetToken string
+	if c.ResetTokenFile != "" {
+		if resetToken, err = readTokenFile(c.ResetTokenFile); err != nil {
+			return nil, fmt.Errorf("resetTokenFile: %w", err)
+		}
+	}
+	return &PodStartupReconciler{
+		Client: cl,
+		Scheme: scheme,
+		Sinks:  sinks,
+
+		DryRun:                  c.DryRun,
+		FailHard:                c.FailHard,
+		MaxBackoff:              c.MaxBackoff.Duration,
+		ClientTimeout:           c.ClientTimeout.Duration,
+		UnhealthyAfter:          c.UnhealthyAfter,
+		PollInterval:            c.PollInterval.Duration,
+		ExemplarThreshold:       c.ExemplarThreshold.Duration,
+		ToReadyWindow:           c.ToReadyWindow.Duration,
+		Backfill:                c.Backfill,
+		TerminalIgnoreAge:       c.TerminalIgnoreAge.Duration,
+		TerminalOnly:            c.TerminalOnly,
+		RecordOnlyWhenComplete:  c.RecordOnlyWhenComplete,
+		PerNamespaceRateLimit:   c.PerNamespaceRateLimit,
+		MaxWait:                 c.MaxWait.Duration,
+		DebounceWindow:          c.DebounceWindow.Duration,
+		EnrichNodeInfo:          c.EnrichNodeInfo,
+		EnrichJobInfo:           c.EnrichJobInfo,
+		IncludeRawConditions:    c.IncludeRawConditions,
+		OwnerKinds:              c.OwnerKinds,
+		Phases:                  c.Phases,
+		CaptureLabels:           c.CaptureLabels,
+		ServerTLS:               c.ServerTLS,
+		SampleRate:              c.SampleRate,
+		CustomDurations:         c.CustomDurations,
+		BaselineContainer:       c.BaselineContainer,
+		ClusterName:             c.ClusterName,
+		MaxConcurrentReconciles: c.MaxConcurrentReconciles,
+		NodeInfoTTL:             c.NodeInfoTTL.Duration,
+		MinCompleteness:         c.MinCompleteness,
+		GRPCBindAddress:         c.GRPCBindAddress,
+		QueryBindAddress:        c.QueryBindAddress,
+		QuerySocketPath:         c.QuerySocketPath,
+		ResetToken:              resetToken,
+		RecordMeasurements:      c.RecordMeasurements,
+		AnnotatePods:            c.AnnotatePods,
+		IgnoreAnnotation:        c.IgnoreAnnotation,
+		Rollup:                  rollup,
+		Namespace:               c.WatchNamespace,
+	}, nil
+}
+
+// discardSink drops every record, for configurations with every sink
+// disabled that only serve the query or streaming APIs.
+type discardSink struct{}
+
+func (discardSink) Name() string { return "discard" }
+
+func (discardSink) Write(context.Context, Record) error { return nil }
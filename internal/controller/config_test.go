@@ -0,0 +1,189 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("Config", func() {
+	It("should build the sinks enabled in a file", func() {
+		cfg, err := LoadConfig(filepath.Join("testdata", "config.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Validate()).To(Succeed())
+
+		sinks, err := cfg.BuildSinks()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sinks).To(HaveLen(5))
+
+		file, ok := sinks[0].(*FileSink)
+		Expect(ok).To(BeTrue())
+		Expect(file.Dir).To(Equal("/data/by-namespace"))
+		Expect(file.CompressOutput).To(BeTrue())
+
+		kafka, ok := sinks[1].(*KafkaSink)
+		Expect(ok).To(BeTrue())
+		Expect(kafka.FlushTimeout).To(Equal(3 * time.Second))
+
+		s3, ok := sinks[2].(*S3Sink)
+		Expect(ok).To(BeTrue())
+		Expect(s3.Bucket).To(Equal("startup-records"))
+		Expect(s3.Prefix).To(Equal("cluster-a"))
+		Expect(s3.MaxBatch).To(Equal(DefaultS3MaxBatch), "fields left out keep their defaults")
+
+		influx, ok := sinks[3].(*InfluxSink)
+		Expect(ok).To(BeTrue())
+		Expect(influx.URL).To(Equal("http://influxdb.monitoring:8086/api/v2/write?org=acme&bucket=pods"))
+		Expect(influx.Token).To(Equal("influx-t0ken"))
+		Expect(influx.MaxBatch).To(Equal(200))
+		Expect(influx.FlushInterval).To(Equal(DefaultInfluxFlushInterval))
+
+		pq, ok := sinks[4].(*ParquetSink)
+		Expect(ok).To(BeTrue())
+		Expect(pq.Dir).To(Equal("/data/parquet"))
+		Expect(pq.FlushInterval).To(Equal(time.Hour))
+		Expect(pq.MaxRows).To(Equal(DefaultParquetMaxRows))
+
+		r, err := cfg.NewReconciler(nil, scheme.Scheme)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Sinks).To(HaveLen(5))
+		Expect(r.MinCompleteness).To(Equal(CompletenessReady))
+		Expect(r.DebounceWindow).To(Equal(2 * time.Second))
+		Expect(r.RecordMeasurements).To(BeTrue())
+		Expect(r.MaxBackoff).To(Equal(DefaultMaxBackoff), "fields left out keep their defaults")
+	})
+
+	It("should default to today's behavior without a file", func() {
+		cfg, err := LoadConfig(filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg).To(Equal(DefaultConfig()))
+
+		sinks, err := cfg.BuildSinks()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sinks).To(HaveLen(1))
+		Expect(sinks[0].(*FileSink).Path).To(Equal(PodStartupLogPath))
+	})
+
+	It("should reject unknown fields and invalid settings", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "config.yaml")
+		Expect(os.WriteFile(path, []byte("sinkz: {}\n"), 0644)).To(Succeed())
+		_, err := LoadConfig(path)
+		Expect(err).To(MatchError(ContainSubstring("sinkz")))
+
+		cfg := DefaultConfig()
+		cfg.MinCompleteness = "Whenever"
+		cfg.Sinks.Kafka.Enabled = true
+		cfg.Sinks.S3.Enabled = true
+		cfg.Sinks.Influx.Enabled = true
+		cfg.Sinks.Parquet.Enabled = true
+		cfg.Sinks.OTLP.Enabled = true
+		cfg.Sinks.Bolt.Enabled = true
+		cfg.Sinks.File.Enabled = false
+		cfg.Rollup.Enabled = true
+		cfg.SampleRate = 1.5
+		cfg.ServerTLS.KeyFile = "tls.key"
+		cfg.Phases = []corev1.PodPhase{corev1.PodRunning, "Done"}
+		err = cfg.Validate()
+		Expect(err).To(MatchError(ContainSubstring("minCompleteness")))
+		Expect(err).To(MatchError(ContainSubstring("brokers are required")))
+		Expect(err).To(MatchError(ContainSubstring("bucket is required")))
+		Expect(err).To(MatchError(ContainSubstring("url is required")))
+		Expect(err).To(MatchError(ContainSubstring("dir is required")))
+		Expect(err).To(MatchError(ContainSubstring("sinks.otlp: endpoint is required")))
+		Expect(err).To(MatchError(ContainSubstring("sinks.bolt: path is required")))
+		Expect(err).To(MatchError(ContainSubstring("rollup: requires sinks.file")))
+		Expect(err).To(MatchError(ContainSubstring("sampleRate: 1.5 is not in (0, 1]")))
+		Expect(err).To(MatchError(ContainSubstring("serverTLS: certFile and keyFile must be set together")))
+		Expect(err).To(MatchError(ContainSubstring(`phases: unknown phase "Done"`)))
+
+		_, err = cfg.NewReconciler(nil, scheme.Scheme)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should read the reset token from a file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(path, []byte("s3cret\n"), 0600)).To(Succeed())
+
+		cfg := DefaultConfig()
+		cfg.ResetTokenFile = path
+		r, err := cfg.NewReconciler(nil, scheme.Scheme)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.ResetToken).To(Equal("s3cret"))
+
+		Expect(os.WriteFile(path, nil, 0600)).To(Succeed())
+		_, err = cfg.NewReconciler(nil, scheme.Scheme)
+		Expect(err).To(MatchError(ContainSubstring("is empty")))
+	})
+
+	It("should take the cluster name from the environment", func() {
+		GinkgoT().Setenv(ClusterNameEnv, "prod-eu-1")
+		r, err := DefaultConfig().NewReconciler(nil, scheme.Scheme)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.ClusterName).To(Equal("prod-eu-1"))
+	})
+
+	It("should validate custom durations", func() {
+		cfg := DefaultConfig()
+		cfg.CustomDurations = []DurationSpec{
+			{Name: "initToReady", From: "initialized", To: "ready"},
+			{Name: "initToReady", From: "scheduled", To: "ready"},
+			{Name: "", From: "created", To: "readyish"},
+			{Name: "toReady", From: "scheduled", To: "ready"},
+			{Name: "init to ready", From: "initialized", To: "ready"},
+		}
+		err := cfg.Validate()
+		Expect(err).To(MatchError(ContainSubstring(`customDurations[4]: name: "init to ready" must be a letter followed by letters and digits`)))
+		Expect(err).To(MatchError(ContainSubstring(`customDurations[3]: name: "toReady" is a built-in duration`)))
+		Expect(err).To(MatchError(ContainSubstring(`customDurations[1]: duplicate name "initToReady"`)))
+		Expect(err).To(MatchError(ContainSubstring("customDurations[2]: name is required")))
+		Expect(err).To(MatchError(ContainSubstring(`to: unknown timestamp "readyish"`)))
+		Expect(err).NotTo(MatchError(ContainSubstring("customDurations[0]")))
+
+		cfg.CustomDurations = cfg.CustomDurations[:1]
+		r, err := cfg.NewReconciler(nil, scheme.Scheme)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.CustomDurations).To(Equal(cfg.CustomDurations))
+	})
+
+	It("should take the file mode from the environment", func() {
+		GinkgoT().Setenv(FileModeEnv, "0600")
+		sinks, err := DefaultConfig().BuildSinks()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sinks[0].(*FileSink).FileMode).To(Equal(os.FileMode(0600)))
+
+		GinkgoT().Setenv(FileModeEnv, "0999")
+		Expect(DefaultConfig().Validate()).To(MatchError(ContainSubstring("sinks.file: mode")))
+	})
+
+	It("should build a JSON Lines file sink", func() {
+		cfg := DefaultConfig()
+		cfg.Sinks.File.Format = FileFormatJSONLines
+		sinks, err := cfg.BuildSinks()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sinks[0].(*FileSink).JSONLines).To(BeTrue())
+
+		cfg.Sinks.File.Format = "csv"
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("sinks.file: format")))
+	})
+})
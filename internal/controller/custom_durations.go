@@ -0,0 +1,167 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// timestampInputs is what the built-in timestamps are read from: the pod,
+// and what the reconciler observed of it.
+type timestampInputs struct {
+	pod corev1.Pod
+	// now is the time of the reconcile.
+	now time.Time
+	// baseline is the time durations are measured from.
+	baseline time.Time
+	// firstObserved is when the reconciler first saw the pod.
+	firstObserved time.Time
+}
+
+// builtinTimestamps are the timestamps the reconciler collects for every
+// pod, each zero until the pod reaches it.
+var builtinTimestamps = []struct {
+	name string
+	at   func(in timestampInputs) time.Time
+}{
+	{"created", func(in timestampInputs) time.Time { return in.pod.CreationTimestamp.Time }},
+	{"pending", func(in timestampInputs) time.Time { return in.pod.CreationTimestamp.Time }},
+	{"scheduled", func(in timestampInputs) time.Time { return getConditionTime(in.pod, corev1.PodScheduled) }},
+	{"initialized", func(in timestampInputs) time.Time { return getConditionTime(in.pod, corev1.PodInitialized) }},
+	{"containersStarted", func(in timestampInputs) time.Time { return getAllContainersStartedTime(in.pod) }},
+	{"anyContainerStarted", func(in timestampInputs) time.Time { return getAllContainersStartedTime(in.pod) }},
+	{"allContainersStarted", func(in timestampInputs) time.Time { return getAllContainersRunningTime(in.pod) }},
+	{"running", func(in timestampInputs) time.Time { return getPhaseTime(in.pod, corev1.PodRunning, in.now) }},
+	{"ready", func(in timestampInputs) time.Time { return getConditionTime(in.pod, corev1.PodReady) }},
+	{"succeeded", func(in timestampInputs) time.Time { return getTerminalTime(in.pod, corev1.PodSucceeded) }},
+	{"failed", func(in timestampInputs) time.Time { return getTerminalTime(in.pod, corev1.PodFailed) }},
+	{"baseline", func(in timestampInputs) time.Time { return in.baseline }},
+	{"firstObserved", func(in timestampInputs) time.Time { return in.firstObserved }},
+}
+
+// TimestampNames are the timestamps of a record that a DurationSpec can
+// refer to. baseline is the creation time unless a backdated baseline was
+// recorded.
+var TimestampNames = func() []string {
+	names := make([]string, len(builtinTimestamps))
+	for i, ts := range builtinTimestamps {
+		names[i] = ts.name
+	}
+	return names
+}()
+
+// collectTimestamps returns the built-in timestamps by name.
+func collectTimestamps(in timestampInputs) map[string]time.Time {
+	times := make(map[string]time.Time, len(builtinTimestamps))
+	for _, ts := range builtinTimestamps {
+		times[ts.name] = ts.at(in)
+	}
+	return times
+}
+
+// builtinDurations are the durations the reconciler measures between two of
+// the built-in timestamps of every record.
+var builtinDurations = []DurationSpec{
+	{Name: "toScheduled", From: "baseline", To: "scheduled"},
+	{Name: "toInitialized", From: "baseline", To: "initialized"},
+	// Kubelet pickup and volume setup. Condition times have second
+	// precision, so a pod initialized right away can appear to have
+	// initialized first, which the clamping in between hides.
+	{Name: "scheduledToInitialized", From: "scheduled", To: "initialized"},
+	{Name: "toContainersStarted", From: "baseline", To: "containersStarted"},
+	// Volume attach and image pulls
+	{Name: "scheduledToContainersStarted", From: "scheduled", To: "containersStarted"},
+	{Name: "toAllContainersStarted", From: "baseline", To: "allContainersStarted"},
+	{Name: "toReady", From: "baseline", To: "ready"},
+	{Name: "toSucceeded", From: "baseline", To: "succeeded"},
+	{Name: "toFailed", From: "baseline", To: "failed"},
+}
+
+// The built-in durations the reconciler measures from other sources than
+// two timestamps.
+const (
+	gatedDurationName       = "gatedDuration"
+	volumeBindLatencyName   = "volumeBindLatency"
+	runtimeName             = "runtime"
+	terminationDurationName = "terminationDuration"
+	jobToPodReadyName       = "jobToPodReady"
+	jobRuntimeName          = "jobRuntime"
+)
+
+// BuiltinDurationNames are the durations the reconciler records itself,
+// which a DurationSpec must not be named after.
+var BuiltinDurationNames = func() []string {
+	names := []string{
+		gatedDurationName, volumeBindLatencyName, runtimeName,
+		terminationDurationName, jobToPodReadyName, jobRuntimeName,
+	}
+	for _, spec := range builtinDurations {
+		names = append(names, spec.Name)
+	}
+	return names
+}()
+
+// DurationSpec declares a duration to add to every record, named Name and
+// measured from one of the record's timestamps to another, e.g. initToReady
+// from initialized to ready.
+type DurationSpec struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// durationNamePattern is what a DurationSpec name must match, so the name
+// can be used as is in every sink, such as an InfluxDB field key.
+var durationNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
+func (s DurationSpec) validate() error {
+	var errs []error
+	switch {
+	case s.Name == "":
+		errs = append(errs, errors.New("name is required"))
+	case !durationNamePattern.MatchString(s.Name):
+		errs = append(errs, fmt.Errorf("name: %q must be a letter followed by letters and digits", s.Name))
+	}
+	if slices.Contains(BuiltinDurationNames, s.Name) {
+		errs = append(errs, fmt.Errorf("name: %q is a built-in duration", s.Name))
+	}
+	if !slices.Contains(TimestampNames, s.From) {
+		errs = append(errs, fmt.Errorf("from: unknown timestamp %q", s.From))
+	}
+	if !slices.Contains(TimestampNames, s.To) {
+		errs = append(errs, fmt.Errorf("to: unknown timestamp %q", s.To))
+	}
+	return errors.Join(errs...)
+}
+
+// between returns the duration between the spec's timestamps, and false
+// when the pod has not reached either of them yet. The timestamps may come
+// from different clocks, such as the API server's and this process's for
+// firstObserved, so a To before From is clamped to zero.
+func (s DurationSpec) between(times map[string]time.Time) (time.Duration, bool) {
+	from, to := times[s.From], times[s.To]
+	if from.IsZero() || to.IsZero() {
+		return 0, false
+	}
+	return max(to.Sub(from), 0), true
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// pendingCall is a debounced function waiting for its timer.
+type pendingCall struct {
+	timer *time.Timer
+	fn    func()
+}
+
+// debouncer delays calls per pod, replacing a pending call whenever a new
+// one is scheduled for the same pod. The zero value is ready to use.
+type debouncer struct {
+	mu      sync.Mutex
+	pending map[types.NamespacedName]*pendingCall
+}
+
+// schedule runs fn once window has passed without another call being
+// scheduled for key. A call still pending for key is dropped.
+func (d *debouncer) schedule(key types.NamespacedName, window time.Duration, fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.pending == nil {
+		d.pending = map[types.NamespacedName]*pendingCall{}
+	}
+	if prev, ok := d.pending[key]; ok {
+		prev.timer.Stop()
+	}
+
+	call := &pendingCall{fn: fn}
+	call.timer = time.AfterFunc(window, func() {
+		if d.take(key, call) {
+			fn()
+		}
+	})
+	d.pending[key] = call
+}
+
+// take removes call from the pending set, reporting whether it was still
+// pending and so should run.
+func (d *debouncer) take(key types.NamespacedName, call *pendingCall) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.pending[key] != call {
+		return false
+	}
+	delete(d.pending, key)
+	return true
+}
+
+// flush runs every pending call immediately.
+func (d *debouncer) flush() {
+	d.mu.Lock()
+	calls := d.pending
+	d.pending = nil
+	d.mu.Unlock()
+
+	// Timers that fire concurrently find their call gone and do nothing
+	for _, call := range calls {
+		call.timer.Stop()
+		call.fn()
+	}
+}
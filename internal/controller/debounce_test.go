@@ -0,0 +1,108 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("DebounceWindow", func() {
+	It("should write only the final state of a burst", func() {
+		ctx := context.Background()
+		pod := newRunningPod("bursty")
+		pod.Status.Phase = corev1.PodPending
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build()
+
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{
+			Client: c, Scheme: scheme.Scheme,
+			Sinks:          []Sink{recorder},
+			DebounceWindow: 200 * time.Millisecond,
+		}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+
+		for _, phase := range []corev1.PodPhase{corev1.PodPending, corev1.PodRunning, corev1.PodSucceeded} {
+			var existing corev1.Pod
+			Expect(c.Get(ctx, req.NamespacedName, &existing)).To(Succeed())
+			existing.Status.Phase = phase
+			Expect(c.Status().Update(ctx, &existing)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(recorder.Records()).To(BeEmpty(), "nothing is written inside the window")
+
+		Eventually(recorder.Records).Should(HaveLen(1))
+		Expect(recorder.Records()[0]["phase"]).To(Equal(string(corev1.PodSucceeded)))
+		Consistently(recorder.Records, 400*time.Millisecond).Should(HaveLen(1))
+	})
+
+	It("should only mark a debounced state recorded once it is written", func() {
+		ctx := context.Background()
+		pod := newRunningPod("debounced-failure")
+		pod.Status.Phase = corev1.PodSucceeded
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build()
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(pod), pod)).To(Succeed())
+
+		recorder := &recordingSink{}
+		toggle := &toggleSink{}
+		toggle.fail.Store(true)
+		r := &PodStartupReconciler{
+			Client: c, Scheme: scheme.Scheme,
+			Sinks:          []Sink{recorder, toggle},
+			DebounceWindow: 50 * time.Millisecond,
+		}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.pods.alreadyRecorded(req.NamespacedName, pod.UID, pod.ResourceVersion)).To(BeFalse(), "nothing is written yet")
+		Eventually(recorder.Records).Should(HaveLen(1))
+		Expect(r.pods.alreadyRecorded(req.NamespacedName, pod.UID, pod.ResourceVersion)).To(BeFalse())
+		Expect(r.terminal.seen(pod.UID, pod.ResourceVersion)).To(BeFalse())
+
+		By("Retrying the same resourceVersion once the sink recovers")
+		toggle.fail.Store(false)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(recorder.Records).Should(HaveLen(2))
+		Eventually(func() bool { return r.terminal.seen(pod.UID, pod.ResourceVersion) }).Should(BeTrue())
+		Expect(r.pods.alreadyRecorded(req.NamespacedName, pod.UID, pod.ResourceVersion)).To(BeTrue())
+	})
+
+	It("should run pending calls on flush", func() {
+		var d debouncer
+		ran := make(chan string, 2)
+		d.schedule(types.NamespacedName{Name: "a"}, time.Hour, func() { ran <- "a" })
+		d.schedule(types.NamespacedName{Name: "b"}, time.Hour, func() { ran <- "b" })
+
+		d.flush()
+		Expect(ran).To(HaveLen(2))
+		d.flush()
+		Expect(ran).To(HaveLen(2), "flushed calls must not run again")
+	})
+})
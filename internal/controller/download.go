@@ -0,0 +1,148 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// newDownloadHandler serves GET /download, which streams the file sink's
+// record file as it is. Partitioned sinks need the namespace query parameter
+// to pick a file. The file is gzipped for clients accepting it and
+// decompressed for those that don't, whichever way it is stored.
+func newDownloadHandler(sink *FileSink) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		namespace := req.URL.Query().Get("namespace")
+		if sink.Dir != "" && namespace == "" {
+			http.Error(w, "records are kept per namespace, set namespace to pick a file", http.StatusBadRequest)
+			return
+		}
+		path := sink.pathFor(Record{"namespace": namespace})
+
+		file, size, err := sink.snapshot(path)
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "no records written yet", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logf.FromContext(req.Context()).Error(err, "Failed to open log file for download", "path", path)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer file.Close() //nolint:errcheck
+
+		contentType := "application/json"
+		if sink.JSONLines {
+			contentType = "application/x-ndjson"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition",
+			fmt.Sprintf("attachment; filename=%q", strings.TrimSuffix(filepath.Base(path), ".gz")))
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		var body io.Reader = io.LimitReader(file, size)
+		gzipped, wantGzip := isGzipped(file), acceptsGzip(req)
+		switch {
+		case gzipped && !wantGzip:
+			zr, err := gzip.NewReader(body)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("reading gzip header: %v", err), http.StatusInternalServerError)
+				return
+			}
+			defer zr.Close() //nolint:errcheck
+			body = zr
+		case !gzipped && wantGzip:
+			w.Header().Set("Content-Encoding", "gzip")
+			zw := gzip.NewWriter(w)
+			defer zw.Close() //nolint:errcheck
+			if _, err := io.Copy(zw, body); err != nil {
+				logf.FromContext(req.Context()).V(1).Info("Download interrupted", "path", path, "error", err.Error())
+			}
+			return
+		default:
+			if gzipped {
+				w.Header().Set("Content-Encoding", "gzip")
+			}
+			w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		}
+		// Headers are sent by now, so a failure can only cut the body short
+		if _, err := io.Copy(w, body); err != nil {
+			logf.FromContext(req.Context()).V(1).Info("Download interrupted", "path", path, "error", err.Error())
+		}
+	}
+}
+
+// snapshot opens path for reading and returns its current size, under the
+// file's lock so no write is half done. Arrays are replaced by renaming, so
+// the open file keeps its contents; JSON Lines only grow, so reading up to
+// the size skips records appended meanwhile. The lock is released before
+// the file is read, not to hold up writes for the length of a download.
+func (f *FileSink) snapshot(path string) (*os.File, int64, error) {
+	lock := f.lockFor(path)
+	lock.Lock()
+	defer lock.Unlock()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close() //nolint:errcheck
+		return nil, 0, err
+	}
+	return file, info.Size(), nil
+}
+
+// isGzipped reports whether file starts with the gzip magic number. Like
+// readFile it sniffs the content rather than trusting the sink's settings,
+// which may have changed since the file was written.
+func isGzipped(file *os.File) bool {
+	magic := make([]byte, len(gzipMagic))
+	n, _ := file.ReadAt(magic, 0)
+	return bytes.Equal(magic[:n], gzipMagic)
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding allows a gzipped
+// response.
+func acceptsGzip(req *http.Request) bool {
+	for _, part := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !ok {
+			return true
+		}
+		if weight, err := strconv.ParseFloat(q, 64); err == nil && weight > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Download", func() {
+	var (
+		dir    string
+		server *httptest.Server
+	)
+
+	// serve starts a query server downloading from sink, after writing two
+	// records to it.
+	serve := func(sink *FileSink) {
+		for _, name := range []string{"a", "b"} {
+			Expect(sink.Write(context.Background(), storedPod(name, "Running", "1s"))).To(Succeed())
+		}
+		runnable := newQueryServerRunnable("", NewRecordStore(), "", nil)
+		runnable.serveDownload(sink)
+		server = httptest.NewServer(runnable.server.Handler)
+		DeferCleanup(server.Close)
+	}
+
+	// download fetches target, asking for gzip when set, and returns the
+	// response with its body as sent.
+	download := func(target string, gzipped bool) (*http.Response, []byte) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+target, nil)
+		Expect(err).NotTo(HaveOccurred())
+		if gzipped {
+			req.Header.Set("Accept-Encoding", "gzip")
+		}
+		// Without this the client asks for gzip itself and hides it
+		client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+		resp, err := client.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close() //nolint:errcheck
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp, body
+	}
+
+	gunzip := func(data []byte) []byte {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		Expect(err).NotTo(HaveOccurred())
+		plain, err := io.ReadAll(zr)
+		Expect(err).NotTo(HaveOccurred())
+		return plain
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("should stream the JSON array as it is on disk", func() {
+		path := filepath.Join(dir, "pod_startup_times.json")
+		serve(&FileSink{Path: path})
+		onDisk, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, body := download("/download", false)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(resp.Header.Get("Content-Disposition")).To(Equal(`attachment; filename="pod_startup_times.json"`))
+		Expect(resp.Header.Get("Content-Encoding")).To(BeEmpty())
+		Expect(body).To(Equal(onDisk))
+	})
+
+	It("should gzip the file for clients accepting it", func() {
+		path := filepath.Join(dir, "pod_startup_times.json")
+		serve(&FileSink{Path: path, JSONLines: true})
+		onDisk, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, body := download("/download", true)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/x-ndjson"))
+		Expect(resp.Header.Get("Content-Encoding")).To(Equal("gzip"))
+		Expect(gunzip(body)).To(Equal(onDisk))
+	})
+
+	It("should send a gzipped file as it is or decompressed", func() {
+		path := filepath.Join(dir, "pod_startup_times.json.gz")
+		serve(&FileSink{Path: path})
+		onDisk, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, body := download("/download", true)
+		Expect(resp.Header.Get("Content-Encoding")).To(Equal("gzip"))
+		Expect(resp.Header.Get("Content-Disposition")).To(Equal(`attachment; filename="pod_startup_times.json"`))
+		Expect(body).To(Equal(onDisk))
+
+		resp, body = download("/download", false)
+		Expect(resp.Header.Get("Content-Encoding")).To(BeEmpty())
+		Expect(body).To(Equal(gunzip(onDisk)))
+	})
+
+	It("should need a namespace for partitioned files", func() {
+		serve(&FileSink{Dir: dir})
+
+		resp, _ := download("/download", false)
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+
+		resp, body := download("/download?namespace=default", false)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Disposition")).To(Equal(`attachment; filename="pod_startup_times_default.json"`))
+		onDisk, err := os.ReadFile(filepath.Join(dir, "pod_startup_times_default.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal(onDisk))
+
+		resp, _ = download("/download?namespace=other", false)
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+})
+
+var _ = Describe("Download source", func() {
+	It("should be the file sink, also in dry runs", func() {
+		file := &FileSink{Path: "records.json"}
+		r := &PodStartupReconciler{Sinks: []Sink{&recordingSink{}, file}, DryRun: true}
+		Expect(r.fileSink()).To(BeIdenticalTo(file))
+
+		r = &PodStartupReconciler{Sinks: []Sink{&recordingSink{}}}
+		Expect(r.fileSink()).To(BeNil())
+	})
+})
+
+var _ = Describe("acceptsGzip", func() {
+	It("should honor the listed codings and their weights", func() {
+		for header, want := range map[string]bool{
+			"":                  false,
+			"gzip":              true,
+			"deflate, gzip;q=1": true,
+			"br, *":             true,
+			"gzip;q=0":          false,
+			"identity":          false,
+		} {
+			req := httptest.NewRequest(http.MethodGet, "/download", nil)
+			req.Header.Set("Accept-Encoding", header)
+			Expect(acceptsGzip(req)).To(Equal(want), header)
+		}
+	})
+})
@@ -0,0 +1,607 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// gzipMagic is the header every gzip stream starts with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// errCorruptFile marks a compressed file that can't be decompressed, which
+// Write resets like one holding invalid JSON.
+var errCorruptFile = errors.New("corrupt compressed file")
+
+// corruptBackupSuffix is appended to the name of a corrupt log file when it
+// is moved aside before the log is reset.
+const corruptBackupSuffix = ".bad"
+
+// arrayBackupSuffix is appended to the name of a JSON array log file when it
+// is kept aside after migrating it to JSON Lines.
+const arrayBackupSuffix = ".array"
+
+// LogDirEnv names the environment variable that switches the file sink to
+// one file per namespace inside the given directory.
+const LogDirEnv = "POD_STARTUP_LOG_DIR"
+
+// FileModeEnv names the environment variable holding the permission bits of
+// the files the sinks write, in octal such as 0640.
+const FileModeEnv = "FILE_MODE"
+
+// The formats the file sink keeps records in: a JSON array rewritten on each
+// write, or JSON Lines appended to.
+const (
+	FileFormatJSON      = "json"
+	FileFormatJSONLines = "jsonl"
+)
+
+// DefaultFileMode is the permission of written files unless configured.
+const DefaultFileMode os.FileMode = 0644
+
+// ParseFileMode parses octal permission bits such as 0640. The owner must be
+// able to read and write the file, since the sink reads its records back.
+func ParseFileMode(s string) (os.FileMode, error) {
+	bits, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an octal file mode", s)
+	}
+	mode := os.FileMode(bits)
+	if mode&^os.ModePerm != 0 {
+		return 0, fmt.Errorf("%q has bits other than permissions", s)
+	}
+	if mode&0600 != 0600 {
+		return 0, fmt.Errorf("%q does not let the owner read and write", s)
+	}
+	return mode, nil
+}
+
+// unsafeFilenameChars matches everything not allowed in a partition file name.
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// FileSink keeps records in JSON arrays on disk, rewriting the whole file on
+// each write, or with JSONLines appends them one per line. Records go to a
+// single file at Path, or, when Dir is set, to one
+// pod_startup_times_<namespace>.json file per namespace.
+type FileSink struct {
+	// Path is the file the JSON array is kept in.
+	Path string
+
+	// Dir, when set, partitions records by namespace into files in this
+	// directory and Path is ignored.
+	Dir string
+
+	// CompressOutput gzips the file. It is implied by a Path ending in ".gz".
+	CompressOutput bool
+
+	// FileMode is the permission of the files. Defaults to DefaultFileMode.
+	FileMode os.FileMode
+
+	// JSONLines appends each record as one line of JSON instead of
+	// rewriting the whole array, so writes don't slow down as the file
+	// grows. Records are then kept in the order they were written. Existing
+	// array files are converted before the first append.
+	JSONLines bool
+
+	// CompactJSON writes JSON arrays without indentation, for large files
+	// read by machines rather than people. JSON Lines are always compact.
+	CompactJSON bool
+
+	// mu guards fileLocks, which serialize writes per file so different
+	// namespaces can be written concurrently, and migrated, the files
+	// already known to be in JSON Lines.
+	mu        sync.Mutex
+	fileLocks map[string]*sync.Mutex
+	migrated  map[string]bool
+}
+
+// Name implements Sink.
+func (f *FileSink) Name() string {
+	return "file"
+}
+
+// Write implements Sink.
+func (f *FileSink) Write(ctx context.Context, rec Record) error {
+	var allData []Record
+	path := f.pathFor(rec)
+
+	// Lock to prevent race conditions
+	lock := f.lockFor(path)
+	lock.Lock()
+	defer lock.Unlock() // Ensures the lock is released even if a panic occurs
+
+	if f.JSONLines {
+		if err := f.migrate(ctx, path); err != nil {
+			return err
+		}
+		if err := f.appendLine(path, rec); err != nil {
+			return err
+		}
+		observeFileSize(ctx, path)
+		return nil
+	}
+
+	// Read the existing records. Only a missing file starts out empty; any
+	// other read error is returned so the history isn't overwritten.
+	existing, err := readFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case errors.Is(err, errCorruptFile):
+		resetCorruptFile(ctx, path, err)
+	case err != nil:
+		return err
+	case len(existing) > 0:
+		if allData, err = decodeRecords(existing); err != nil {
+			resetCorruptFile(ctx, path, err)
+			allData = []Record{}
+		}
+	}
+
+	// Append this new pod event data, keeping the file in a stable order
+	allData = append(allData, rec)
+	sortRecords(allData)
+
+	// Re-marshal everything as a JSON array
+	jsonData, err := f.encode(allData)
+	if err != nil {
+		return err
+	}
+
+	// Replace the file (overwrites but keeps all previous entries)
+	if err := writeFileAtomic(path, jsonData, f.compressed(path), f.fileMode()); err != nil {
+		return err
+	}
+	observeFileSize(ctx, path)
+	return nil
+}
+
+// resetCorruptFile keeps the corrupt file at path aside for inspection and
+// counts the reset, leaving Write to start the log afresh.
+func resetCorruptFile(ctx context.Context, path string, cause error) {
+	logger := logf.FromContext(ctx)
+	logResetsTotal.Inc()
+	backup := path + corruptBackupSuffix
+	if err := replaceFile(path, backup); err != nil {
+		logger.Error(err, "Failed to back up corrupt log file", "path", path)
+		backup = ""
+	}
+	logger.Error(cause, "Failed to unmarshal existing log file, resetting.", "backup", backup)
+}
+
+// observeFileSize sets logFileBytes to the current size of path.
+func observeFileSize(ctx context.Context, path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		logf.FromContext(ctx).V(1).Info("Failed to stat log file", "path", path, "error", err.Error())
+		return
+	}
+	logFileBytes.WithLabelValues(path).Set(float64(info.Size()))
+}
+
+// Migrate converts every file the sink has written as a JSON array to JSON
+// Lines, when JSONLines is set. Write does the same for each file before
+// appending to it; calling Migrate on startup converts them all up front.
+func (f *FileSink) Migrate(ctx context.Context) error {
+	if !f.JSONLines {
+		return nil
+	}
+	files, err := f.files()
+	if err != nil {
+		return fmt.Errorf("listing record files: %w", err)
+	}
+	var errs error
+	for _, path := range files {
+		lock := f.lockFor(path)
+		lock.Lock()
+		errs = errors.Join(errs, f.migrate(ctx, path))
+		lock.Unlock()
+	}
+	return errs
+}
+
+// migrate rewrites path as JSON Lines if it holds a JSON array, keeping the
+// original next to it. The caller holds the lock of path.
+func (f *FileSink) migrate(ctx context.Context, path string) error {
+	f.mu.Lock()
+	done := f.migrated[path]
+	f.mu.Unlock()
+	if done {
+		return nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	data, err := readFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if isJSONArray(data) {
+		records, err := decodeRecords(data)
+		if err != nil {
+			return fmt.Errorf("decoding %s: %w", path, err)
+		}
+		backup := path + arrayBackupSuffix
+		if err := writeFileAtomic(backup, raw, false, f.fileMode()); err != nil {
+			return fmt.Errorf("backing up %s: %w", path, err)
+		}
+		lines, err := encodeLines(records)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(path, lines, f.compressed(path), f.fileMode()); err != nil {
+			return err
+		}
+		logf.FromContext(ctx).Info("Migrated log file to JSON Lines",
+			"path", path, "records", len(records), "backup", backup)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.migrated == nil {
+		f.migrated = map[string]bool{}
+	}
+	f.migrated[path] = true
+	return nil
+}
+
+// appendLine adds rec to the end of path as one line of JSON, gzipped as a
+// separate member when the file is compressed. The caller holds the lock of
+// path.
+func (f *FileSink) appendLine(path string, rec Record) (err error) {
+	line, err := encodeLines([]Record{rec})
+	if err != nil {
+		return err
+	}
+	if f.compressed(path) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(line); err != nil {
+			return fmt.Errorf("compressing record: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("compressing record: %w", err)
+		}
+		line = buf.Bytes()
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, f.fileMode())
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("closing %s: %w", path, closeErr)
+		}
+	}()
+	if err := file.Chmod(f.fileMode()); err != nil {
+		return fmt.Errorf("setting mode of %s: %w", path, err)
+	}
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("appending to %s: %w", path, err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("syncing %s: %w", path, err)
+	}
+	return nil
+}
+
+// encode marshals records in the sink's format.
+func (f *FileSink) encode(records []Record) ([]byte, error) {
+	if f.JSONLines {
+		return encodeLines(records)
+	}
+	if records == nil {
+		records = []Record{}
+	}
+	var data []byte
+	var err error
+	if f.CompactJSON {
+		data, err = json.Marshal(records)
+	} else {
+		data, err = json.MarshalIndent(records, "", "  ")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("marshalling records: %w", err)
+	}
+	return data, nil
+}
+
+// encodeLines marshals records as JSON Lines.
+func encodeLines(records []Record) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return nil, fmt.Errorf("marshalling record: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// pathFor returns the file the record belongs in.
+func (f *FileSink) pathFor(rec Record) string {
+	if f.Dir == "" {
+		return f.Path
+	}
+	namespace, _ := rec["namespace"].(string)
+	name := "pod_startup_times_" + sanitizeFilename(namespace) + ".json"
+	if f.CompressOutput {
+		name += ".gz"
+	}
+	return filepath.Join(f.Dir, name)
+}
+
+// files returns the files the sink has written so far.
+func (f *FileSink) files() ([]string, error) {
+	if f.Dir == "" {
+		if _, err := os.Stat(f.Path); errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		return []string{f.Path}, nil
+	}
+	var files []string
+	for _, pattern := range []string{"pod_startup_times_*.json", "pod_startup_times_*.json.gz"} {
+		matches, err := filepath.Glob(filepath.Join(f.Dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// read returns the records in path, holding the same lock as Write.
+func (f *FileSink) read(path string) ([]Record, error) {
+	lock := f.lockFor(path)
+	lock.Lock()
+	defer lock.Unlock()
+	return ReadRecords(path)
+}
+
+// rewrite replaces the records in path with what fn returns, holding the
+// same lock as Write.
+func (f *FileSink) rewrite(path string, fn func([]Record) []Record) error {
+	lock := f.lockFor(path)
+	lock.Lock()
+	defer lock.Unlock()
+
+	records, err := ReadRecords(path)
+	if err != nil {
+		return err
+	}
+	jsonData, err := f.encode(fn(records))
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, jsonData, f.compressed(path), f.fileMode())
+}
+
+// Clear implements ClearingSink, leaving every file the sink has written
+// holding an empty array, or empty with JSONLines.
+func (f *FileSink) Clear(ctx context.Context) error {
+	files, err := f.files()
+	if err != nil {
+		return fmt.Errorf("listing record files: %w", err)
+	}
+	empty, err := f.encode(nil)
+	if err != nil {
+		return err
+	}
+	var errs error
+	for _, path := range files {
+		lock := f.lockFor(path)
+		lock.Lock()
+		err := writeFileAtomic(path, empty, f.compressed(path), f.fileMode())
+		lock.Unlock()
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("clearing %s: %w", path, err))
+			continue
+		}
+		observeFileSize(ctx, path)
+	}
+	return errs
+}
+
+// lockFor returns the mutex serializing writes to path.
+func (f *FileSink) lockFor(path string) *sync.Mutex {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.fileLocks == nil {
+		f.fileLocks = map[string]*sync.Mutex{}
+	}
+	lock, ok := f.fileLocks[path]
+	if !ok {
+		lock = &sync.Mutex{}
+		f.fileLocks[path] = lock
+	}
+	return lock
+}
+
+// compressed reports whether the file at path is written gzipped.
+func (f *FileSink) compressed(path string) bool {
+	return f.CompressOutput || strings.HasSuffix(path, ".gz")
+}
+
+// sanitizeFilename makes s safe to embed in a file name, replacing anything
+// other than letters, digits, dashes and underscores.
+func sanitizeFilename(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return unsafeFilenameChars.ReplaceAllString(s, "_")
+}
+
+// sortRecords orders records by creation time, then namespace and pod name,
+// so the file diffs cleanly between writes. Records without a creation time
+// sort last.
+func sortRecords(records []Record) {
+	slices.SortStableFunc(records, func(a, b Record) int {
+		ta, tb := recordTimestamp(a, "created"), recordTimestamp(b, "created")
+		switch {
+		case ta.IsZero() != tb.IsZero():
+			if ta.IsZero() {
+				return 1
+			}
+			return -1
+		case !ta.Equal(tb):
+			return ta.Compare(tb)
+		}
+		if c := cmp.Compare(recordString(a, "namespace"), recordString(b, "namespace")); c != 0 {
+			return c
+		}
+		return cmp.Compare(recordString(a, "pod"), recordString(b, "pod"))
+	})
+}
+
+// ReadRecords decodes the records kept in a file written by FileSink,
+// decompressing it first if it is gzipped. Both JSON arrays and JSON Lines
+// are understood.
+func ReadRecords(path string) ([]Record, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	records, err := decodeRecords(data)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return records, nil
+}
+
+// decodeRecords decodes a JSON array of records or, when the first
+// non-whitespace byte is not '[', a stream of JSON objects such as JSON
+// Lines.
+func decodeRecords(data []byte) ([]Record, error) {
+	var records []Record
+	if isJSONArray(data) {
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, err
+		}
+		return records, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var rec Record
+		if err := dec.Decode(&rec); errors.Is(err, io.EOF) {
+			return records, nil
+		} else if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+}
+
+// isJSONArray reports whether data starts with '[' after any whitespace.
+func isJSONArray(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// readFile returns the contents of path, transparently decompressing it if
+// it is gzipped. Sniffing the content rather than trusting the sink's
+// settings lets a file be read back after compression is switched on or off.
+func readFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || !bytes.HasPrefix(data, gzipMagic) {
+		return data, err
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("reading gzip header of %s: %w: %w", path, errCorruptFile, err)
+	}
+	defer zr.Close() //nolint:errcheck
+
+	data, err = io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing %s: %w: %w", path, errCorruptFile, err)
+	}
+	return data, nil
+}
+
+// fileMode returns FileMode, or DefaultFileMode when unset.
+func (f *FileSink) fileMode() os.FileMode {
+	if f.FileMode == 0 {
+		return DefaultFileMode
+	}
+	return f.FileMode
+}
+
+// writeFileAtomic replaces path with data by writing a temporary file in the
+// same directory and renaming it over the original, so readers never see a
+// partially written file. The file gets mode regardless of the umask.
+func writeFileAtomic(path string, data []byte, compress bool, mode os.FileMode) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file for %s: %w", path, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	var w io.Writer = tmp
+	var zw *gzip.Writer
+	if compress {
+		zw = gzip.NewWriter(tmp)
+		w = zw
+	}
+	if _, err = w.Write(data); err != nil {
+		return fmt.Errorf("writing %s: %w", tmp.Name(), err)
+	}
+	if zw != nil {
+		if err = zw.Close(); err != nil {
+			return fmt.Errorf("compressing %s: %w", tmp.Name(), err)
+		}
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("syncing %s: %w", tmp.Name(), err)
+	}
+	if err = tmp.Chmod(mode); err != nil {
+		return fmt.Errorf("setting mode of %s: %w", tmp.Name(), err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("closing %s: %w", tmp.Name(), err)
+	}
+	if err = replaceFile(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replacing %s: %w", path, err)
+	}
+	return nil
+}
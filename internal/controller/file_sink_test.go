@@ -0,0 +1,416 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// readRecordsFile decodes a JSON array of records from disk, gunzipping it
+// first if it is compressed.
+func readRecordsFile(path string) []Record {
+	data, err := os.ReadFile(path)
+	Expect(err).NotTo(HaveOccurred())
+	if bytes.HasPrefix(data, gzipMagic) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		Expect(err).NotTo(HaveOccurred())
+		data, err = io.ReadAll(zr)
+		Expect(err).NotTo(HaveOccurred())
+	}
+	var records []Record
+	Expect(json.Unmarshal(data, &records)).To(Succeed())
+	return records
+}
+
+var _ = Describe("FileSink", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "pod_startup_times.json")
+	})
+
+	It("should append records to a JSON array", func() {
+		sink := &FileSink{Path: path}
+		Expect(sink.Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+		Expect(sink.Write(context.Background(), Record{"pod": "b"})).To(Succeed())
+
+		records := readRecordsFile(path)
+		Expect(records).To(HaveLen(2))
+		Expect(records[0]["pod"]).To(Equal("a"))
+		Expect(records[1]["pod"]).To(Equal("b"))
+	})
+
+	It("should report the file size as it grows", func() {
+		sink := &FileSink{Path: path}
+		fileSize := func() float64 {
+			info, err := os.Stat(path)
+			Expect(err).NotTo(HaveOccurred())
+			return float64(info.Size())
+		}
+
+		Expect(sink.Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+		first := testutil.ToFloat64(logFileBytes.WithLabelValues(path))
+		Expect(first).To(Equal(fileSize()))
+
+		Expect(sink.Write(context.Background(), Record{"pod": "b"})).To(Succeed())
+		Expect(testutil.ToFloat64(logFileBytes.WithLabelValues(path))).To(And(Equal(fileSize()), BeNumerically(">", first)))
+
+		Expect(sink.Clear(context.Background())).To(Succeed())
+		Expect(testutil.ToFloat64(logFileBytes.WithLabelValues(path))).To(Equal(fileSize()))
+	})
+
+	It("should keep records sorted by creation time, then namespace and name", func() {
+		at := func(ts string) map[string]string { return map[string]string{"created": ts} }
+		sink := &FileSink{Path: path}
+		for _, rec := range []Record{
+			{"pod": "late", "namespace": "a", "timestamps": at("2025-01-01T00:00:03Z")},
+			{"pod": "unknown", "namespace": "a", "timestamps": at("")},
+			{"pod": "y", "namespace": "b", "timestamps": at("2025-01-01T00:00:01Z")},
+			{"pod": "x", "namespace": "b", "timestamps": at("2025-01-01T00:00:01Z")},
+			{"pod": "z", "namespace": "a", "timestamps": at("2025-01-01T00:00:01Z")},
+			{"pod": "no-timestamps", "namespace": "a"},
+		} {
+			Expect(sink.Write(context.Background(), rec)).To(Succeed())
+		}
+
+		var order []any
+		for _, rec := range readRecordsFile(path) {
+			order = append(order, rec["pod"])
+		}
+		Expect(order).To(Equal([]any{"z", "x", "y", "late", "no-timestamps", "unknown"}))
+
+		first, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sink.Write(context.Background(), Record{"pod": "no-timestamps-2", "namespace": "b"})).To(Succeed())
+		second, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(second)).To(HavePrefix(strings.TrimSuffix(string(first), "\n]")), "existing order is stable")
+	})
+
+	It("should reset a corrupt file, keeping a backup and counting the reset", func() {
+		Expect(os.WriteFile(path, []byte("not json"), 0644)).To(Succeed())
+		before := testutil.ToFloat64(logResetsTotal)
+
+		sink := &FileSink{Path: path}
+		Expect(sink.Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+		Expect(readRecordsFile(path)).To(HaveLen(1))
+
+		Expect(testutil.ToFloat64(logResetsTotal) - before).To(BeNumerically("==", 1))
+		backup, err := os.ReadFile(path + ".bad")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(backup)).To(Equal("not json"))
+	})
+
+	It("should leave no temp files behind", func() {
+		sink := &FileSink{Path: path}
+		Expect(sink.Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Name()).To(Equal(filepath.Base(path)))
+	})
+
+	It("should report write failures", func() {
+		sink := &FileSink{Path: filepath.Join(path, "missing-dir", "out.json")}
+		Expect(sink.Write(context.Background(), Record{"pod": "a"})).NotTo(Succeed())
+	})
+
+	It("should create the file with the configured mode", func() {
+		if runtime.GOOS == "windows" {
+			Skip("Windows has no permission bits")
+		}
+		Expect((&FileSink{Path: path}).Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(DefaultFileMode))
+
+		strict := filepath.Join(filepath.Dir(path), "strict.json")
+		Expect((&FileSink{Path: strict, FileMode: 0640}).Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+		info, err = os.Stat(strict)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0640)))
+	})
+
+	It("should parse octal file modes", func() {
+		Expect(ParseFileMode("0640")).To(Equal(os.FileMode(0640)))
+		Expect(ParseFileMode("600")).To(Equal(os.FileMode(0600)))
+		_, err := ParseFileMode("rw-r-----")
+		Expect(err).To(MatchError(ContainSubstring("not an octal file mode")))
+		_, err = ParseFileMode("4755")
+		Expect(err).To(MatchError(ContainSubstring("bits other than permissions")))
+		_, err = ParseFileMode("0444")
+		Expect(err).To(MatchError(ContainSubstring("owner read and write")))
+	})
+})
+
+var _ = Describe("FileSink compression", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	expectGzipped := func(path string) {
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data[:2]).To(Equal(gzipMagic))
+	}
+
+	It("should gzip the file when CompressOutput is set", func() {
+		path := filepath.Join(dir, "pod_startup_times.json")
+		sink := &FileSink{Path: path, CompressOutput: true}
+		Expect(sink.Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+		Expect(sink.Write(context.Background(), Record{"pod": "b"})).To(Succeed())
+
+		expectGzipped(path)
+		records := readRecordsFile(path)
+		Expect(records).To(HaveLen(2))
+		Expect(records[1]["pod"]).To(Equal("b"))
+	})
+
+	It("should gzip the file when the path ends in .gz", func() {
+		path := filepath.Join(dir, "pod_startup_times.json.gz")
+		sink := &FileSink{Path: path}
+		Expect(sink.Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+
+		expectGzipped(path)
+		Expect(readRecordsFile(path)).To(HaveLen(1))
+	})
+
+	It("should reset a truncated gzip file, keeping a backup and counting the reset", func() {
+		path := filepath.Join(dir, "pod_startup_times.json.gz")
+		sink := &FileSink{Path: path}
+		Expect(sink.Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		truncated := data[:len(data)/2]
+		Expect(os.WriteFile(path, truncated, 0644)).To(Succeed())
+		before := testutil.ToFloat64(logResetsTotal)
+
+		Expect(sink.Write(context.Background(), Record{"pod": "b"})).To(Succeed())
+		Expect(testutil.ToFloat64(logResetsTotal) - before).To(BeNumerically("==", 1))
+		backup, err := os.ReadFile(path + corruptBackupSuffix)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backup).To(Equal(truncated))
+		Expect(readRecordsFile(path)).To(HaveLen(1))
+	})
+
+	It("should return read errors rather than overwrite the file", func() {
+		// A directory can't be read as a file, whatever the user
+		path := filepath.Join(dir, "unreadable.json")
+		Expect(os.Mkdir(path, 0755)).To(Succeed())
+		before := testutil.ToFloat64(logResetsTotal)
+
+		sink := &FileSink{Path: path}
+		Expect(sink.Write(context.Background(), Record{"pod": "a"})).NotTo(Succeed())
+		Expect(testutil.ToFloat64(logResetsTotal)).To(Equal(before))
+		Expect(path).To(BeADirectory())
+	})
+
+	It("should write compact JSON when CompactJSON is set", func() {
+		path := filepath.Join(dir, "pod_startup_times.json")
+		sink := &FileSink{Path: path, CompactJSON: true}
+		Expect(sink.Write(context.Background(), Record{"pod": "a", "namespace": "default"})).To(Succeed())
+		Expect(sink.Write(context.Background(), Record{"pod": "b", "namespace": "default"})).To(Succeed())
+
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).NotTo(ContainSubstring("\n"))
+		Expect(readRecordsFile(path)).To(HaveLen(2))
+
+		sink.CompactJSON = false
+		Expect(sink.Write(context.Background(), Record{"pod": "c"})).To(Succeed())
+		data, err = os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("\n    \"pod\": \"a\""))
+	})
+
+	It("should keep existing records when compression is switched on or off", func() {
+		path := filepath.Join(dir, "pod_startup_times.json")
+		Expect((&FileSink{Path: path}).Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+		Expect((&FileSink{Path: path, CompressOutput: true}).Write(context.Background(), Record{"pod": "b"})).To(Succeed())
+		expectGzipped(path)
+
+		Expect((&FileSink{Path: path}).Write(context.Background(), Record{"pod": "c"})).To(Succeed())
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bytes.HasPrefix(data, gzipMagic)).To(BeFalse())
+		Expect(readRecordsFile(path)).To(HaveLen(3))
+	})
+})
+
+var _ = Describe("FileSink in JSON Lines", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "pod_startup_times.json")
+	})
+
+	It("should append one record per line", func() {
+		sink := &FileSink{Path: path, JSONLines: true}
+		Expect(sink.Write(context.Background(), Record{"pod": "b"})).To(Succeed())
+		Expect(sink.Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("{\"pod\":\"b\"}\n{\"pod\":\"a\"}\n"))
+	})
+
+	It("should report the file size after each append", func() {
+		sink := &FileSink{Path: path, JSONLines: true}
+		Expect(sink.Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+		Expect(testutil.ToFloat64(logFileBytes.WithLabelValues(path))).To(BeEquivalentTo(len("{\"pod\":\"a\"}\n")))
+		Expect(sink.Write(context.Background(), Record{"pod": "b"})).To(Succeed())
+		Expect(testutil.ToFloat64(logFileBytes.WithLabelValues(path))).To(BeEquivalentTo(2 * len("{\"pod\":\"a\"}\n")))
+	})
+
+	It("should append gzip members that read back as one file", func() {
+		sink := &FileSink{Path: path, JSONLines: true, CompressOutput: true}
+		Expect(sink.Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+		Expect(sink.Write(context.Background(), Record{"pod": "b"})).To(Succeed())
+
+		records, err := ReadRecords(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(2))
+		Expect(records[1]["pod"]).To(Equal("b"))
+	})
+
+	It("should convert an existing array file on startup, keeping a backup", func() {
+		Expect((&FileSink{Path: path}).Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+		original, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		sink := &FileSink{Path: path, JSONLines: true}
+		Expect(sink.Migrate(context.Background())).To(Succeed())
+
+		backup, err := os.ReadFile(path + ".array")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backup).To(Equal(original))
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("{\"pod\":\"a\"}\n"))
+
+		Expect(sink.Write(context.Background(), Record{"pod": "b"})).To(Succeed())
+		records, err := ReadRecords(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(2))
+	})
+
+	It("should convert an array file before the first append", func() {
+		Expect(os.WriteFile(path, []byte(`  [{"pod": "a"}]`), 0644)).To(Succeed())
+
+		sink := &FileSink{Path: path, JSONLines: true}
+		Expect(sink.Write(context.Background(), Record{"pod": "b"})).To(Succeed())
+
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("{\"pod\":\"a\"}\n{\"pod\":\"b\"}\n"))
+		Expect(path + ".array").To(BeAnExistingFile())
+	})
+
+	It("should read JSON Lines back into an array when switched off", func() {
+		Expect((&FileSink{Path: path, JSONLines: true}).Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+		Expect((&FileSink{Path: path}).Write(context.Background(), Record{"pod": "b"})).To(Succeed())
+
+		Expect(readRecordsFile(path)).To(HaveLen(2))
+		Expect(path + corruptBackupSuffix).NotTo(BeAnExistingFile())
+	})
+})
+
+var _ = Describe("FileSink partitioned by namespace", func() {
+	It("should write each namespace to its own file", func() {
+		dir := GinkgoT().TempDir()
+		sink := &FileSink{Dir: dir}
+
+		var wg sync.WaitGroup
+		for _, rec := range []Record{
+			{"pod": "a", "namespace": "team-a"},
+			{"pod": "b", "namespace": "team-b"},
+			{"pod": "c", "namespace": "team-a"},
+		} {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(sink.Write(context.Background(), rec)).To(Succeed())
+			}()
+		}
+		wg.Wait()
+
+		teamA := readRecordsFile(filepath.Join(dir, "pod_startup_times_team-a.json"))
+		Expect(teamA).To(HaveLen(2))
+		Expect([]any{teamA[0]["pod"], teamA[1]["pod"]}).To(ConsistOf("a", "c"))
+
+		teamB := readRecordsFile(filepath.Join(dir, "pod_startup_times_team-b.json"))
+		Expect(teamB).To(HaveLen(1))
+		Expect(teamB[0]["pod"]).To(Equal("b"))
+	})
+
+	It("should keep namespace names from escaping the directory", func() {
+		dir := GinkgoT().TempDir()
+		sink := &FileSink{Dir: dir}
+		Expect(sink.Write(context.Background(), Record{"pod": "a", "namespace": "../etc/x"})).To(Succeed())
+
+		entries, err := os.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Name()).To(Equal("pod_startup_times____etc_x.json"))
+	})
+})
+
+var _ = Describe("FileSink paths", func() {
+	It("should default to the platform data directory", func() {
+		if runtime.GOOS == "windows" {
+			Expect(DefaultDataDir).To(Equal(`C:\data`))
+		} else {
+			Expect(DefaultDataDir).To(Equal("/data"))
+		}
+		Expect(filepath.IsAbs(DefaultDataDir)).To(BeTrue())
+	})
+
+	It("should build namespace file paths with the platform separator", func() {
+		dir := filepath.Join("records", "by-namespace")
+		sink := &FileSink{Dir: dir}
+		Expect(sink.pathFor(Record{"namespace": "team-a"})).To(
+			Equal(dir + string(filepath.Separator) + "pod_startup_times_team-a.json"))
+	})
+
+	It("should replace an existing file", func() {
+		dir := GinkgoT().TempDir()
+		src, dst := filepath.Join(dir, "new.json"), filepath.Join(dir, "records.json")
+		Expect(os.WriteFile(src, []byte("[1]"), 0644)).To(Succeed())
+		Expect(os.WriteFile(dst, []byte("[0]"), 0644)).To(Succeed())
+
+		Expect(replaceFile(src, dst)).To(Succeed())
+		Expect(os.ReadFile(dst)).To(BeEquivalentTo("[1]"))
+		Expect(src).NotTo(BeAnExistingFile())
+	})
+})
@@ -0,0 +1,39 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"os"
+)
+
+// DefaultDataDir is the directory records are kept in by default.
+const DefaultDataDir = `C:\data`
+
+// replaceFile renames src over dst. Renaming over an existing file fails on
+// Windows while the file is open elsewhere, so on failure dst is removed and
+// the rename retried, giving up atomicity rather than the write.
+func replaceFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	if rmErr := os.Remove(dst); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) {
+		return errors.Join(err, rmErr)
+	}
+	return os.Rename(src, dst)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	lifecyclev1 "github.com/karthikbhat19/pod-time-measure-controller/api/lifecycle/v1"
+)
+
+// lifecycleServer implements the LifecycleService by streaming records
+// published on the hub.
+type lifecycleServer struct {
+	lifecyclev1.UnimplementedLifecycleServiceServer
+	hub *Hub
+}
+
+// Watch streams every record published after the subscription is made until
+// the client goes away.
+func (s *lifecycleServer) Watch(req *lifecyclev1.WatchRequest, stream grpc.ServerStreamingServer[lifecyclev1.PodLifecycleEvent]) error {
+	records, unsubscribe := s.hub.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case rec, ok := <-records:
+			if !ok {
+				return nil
+			}
+			if req.GetNamespace() != "" && rec["namespace"] != req.GetNamespace() {
+				continue
+			}
+			event, err := toLifecycleEvent(rec)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toLifecycleEvent converts a record into its wire representation.
+func toLifecycleEvent(rec Record) (*lifecyclev1.PodLifecycleEvent, error) {
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling record: %w", err)
+	}
+
+	event := &lifecyclev1.PodLifecycleEvent{
+		Record:     string(raw),
+		Timestamps: map[string]string{},
+		Durations:  map[string]string{},
+	}
+	event.Pod, _ = rec["pod"].(string)
+	event.Namespace, _ = rec["namespace"].(string)
+	event.Node, _ = rec["node"].(string)
+	event.Phase, _ = rec["phase"].(string)
+	if timestamps, ok := rec["timestamps"].(map[string]string); ok {
+		for k, v := range timestamps {
+			event.Timestamps[k] = v
+		}
+	}
+	if durations, ok := rec["durations"].(map[string]string); ok {
+		for k, v := range durations {
+			event.Durations[k] = v
+		}
+	}
+	return event, nil
+}
+
+// grpcServerRunnable serves the LifecycleService for the lifetime of the
+// manager.
+type grpcServerRunnable struct {
+	addr   string
+	server *grpc.Server
+}
+
+// newGRPCServerRunnable serves the hub's records, over TLS when tlsConfig is
+// set.
+func newGRPCServerRunnable(addr string, hub *Hub, tlsConfig *tls.Config) *grpcServerRunnable {
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	server := grpc.NewServer(opts...)
+	lifecyclev1.RegisterLifecycleServiceServer(server, &lifecycleServer{hub: hub})
+	return &grpcServerRunnable{addr: addr, server: server}
+}
+
+// Start implements manager.Runnable.
+func (g *grpcServerRunnable) Start(ctx context.Context) error {
+	logger := logf.FromContext(ctx)
+
+	lis, err := net.Listen("tcp", g.addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", g.addr, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		// Watch streams never finish on their own, so don't wait for them
+		g.server.Stop()
+	}()
+
+	logger.Info("Starting lifecycle gRPC server", "address", lis.Addr().String())
+	if err := g.server.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Only the
+// leader produces records, but serving on every replica keeps clients from
+// having to track leadership.
+func (g *grpcServerRunnable) NeedLeaderElection() bool {
+	return false
+}
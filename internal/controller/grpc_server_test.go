@@ -0,0 +1,123 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	lifecyclev1 "github.com/karthikbhat19/pod-time-measure-controller/api/lifecycle/v1"
+)
+
+var _ = Describe("lifecycleServer", func() {
+	var (
+		hub    *Hub
+		client lifecyclev1.LifecycleServiceClient
+	)
+
+	BeforeEach(func() {
+		hub = NewHub(DefaultHubBufferSize)
+		server := grpc.NewServer()
+		lifecyclev1.RegisterLifecycleServiceServer(server, &lifecycleServer{hub: hub})
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		go func() {
+			_ = server.Serve(lis)
+		}()
+		DeferCleanup(server.Stop)
+
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		client = lifecyclev1.NewLifecycleServiceClient(conn)
+	})
+
+	// watch opens a stream and keeps publishing the records until the server
+	// has subscribed and delivered one of them.
+	watch := func(ctx context.Context, req *lifecyclev1.WatchRequest, recs ...Record) *lifecyclev1.PodLifecycleEvent {
+		stream, err := client.Watch(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		events := make(chan *lifecyclev1.PodLifecycleEvent, 1)
+		go func() {
+			defer GinkgoRecover()
+			event, err := stream.Recv()
+			if err == nil {
+				events <- event
+			}
+		}()
+
+		var event *lifecyclev1.PodLifecycleEvent
+		Eventually(func() bool {
+			for _, rec := range recs {
+				hub.Publish(rec)
+			}
+			select {
+			case event = <-events:
+				return true
+			case <-time.After(50 * time.Millisecond):
+				return false
+			}
+		}, 5*time.Second).Should(BeTrue())
+		return event
+	}
+
+	It("should stream published records to a subscribing client", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rec := Record{
+			"pod":        "web-1",
+			"namespace":  "default",
+			"node":       "node-a",
+			"phase":      "Running",
+			"timestamps": map[string]string{"ready": "2025-01-01T00:00:03Z"},
+			"durations":  map[string]string{"toReady": "3s"},
+		}
+		event := watch(ctx, &lifecyclev1.WatchRequest{}, rec)
+
+		Expect(event.GetPod()).To(Equal("web-1"))
+		Expect(event.GetNamespace()).To(Equal("default"))
+		Expect(event.GetNode()).To(Equal("node-a"))
+		Expect(event.GetPhase()).To(Equal("Running"))
+		Expect(event.GetDurations()).To(HaveKeyWithValue("toReady", "3s"))
+		Expect(event.GetTimestamps()).To(HaveKeyWithValue("ready", "2025-01-01T00:00:03Z"))
+
+		var decoded map[string]interface{}
+		Expect(json.Unmarshal([]byte(event.GetRecord()), &decoded)).To(Succeed())
+		Expect(decoded).To(HaveKeyWithValue("pod", "web-1"))
+	})
+
+	It("should skip records from other namespaces when filtering", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		event := watch(ctx, &lifecyclev1.WatchRequest{Namespace: "team-a"},
+			Record{"pod": "other", "namespace": "kube-system"},
+			Record{"pod": "mine", "namespace": "team-a"},
+		)
+		Expect(event.GetPod()).To(Equal("mine"))
+	})
+})
@@ -0,0 +1,126 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// DefaultUnhealthyAfter is how many consecutive failed record writes make the
+// readiness check fail.
+const DefaultUnhealthyAfter = 3
+
+// WriteStatus describes the outcome of the most recent attempt to write a
+// record to the sinks.
+type WriteStatus struct {
+	// Time is when the attempt finished.
+	Time time.Time
+	// Err is the joined error of every sink that failed, or nil.
+	Err error
+	// ConsecutiveFailures counts the failed attempts in a row up to and
+	// including this one. It is zero after a successful write.
+	ConsecutiveFailures int
+}
+
+// LastWrite returns the status of the most recent record write, and false if
+// nothing has been written yet.
+func (r *PodStartupReconciler) LastWrite() (WriteStatus, bool) {
+	status := r.lastWrite.Load()
+	if status == nil {
+		return WriteStatus{}, false
+	}
+	return *status, true
+}
+
+// recordWrite updates the last write status after a persist attempt.
+func (r *PodStartupReconciler) recordWrite(err error) {
+	for {
+		prev := r.lastWrite.Load()
+		next := &WriteStatus{Time: time.Now(), Err: err}
+		if err != nil {
+			next.ConsecutiveFailures = 1
+			if prev != nil {
+				next.ConsecutiveFailures = prev.ConsecutiveFailures + 1
+			}
+		}
+		if r.lastWrite.CompareAndSwap(prev, next) {
+			return
+		}
+	}
+}
+
+// writeTotals accumulates the record writes since startup, for the summary
+// logged on shutdown. The zero value is ready to use.
+type writeTotals struct {
+	mu          sync.Mutex
+	written     int64
+	lastSuccess time.Time
+	sinkErrors  map[string]int64
+}
+
+// add counts a record written at now, which failed on the named sinks. Only
+// a record every sink accepted counts as written.
+func (w *writeTotals) add(now time.Time, failed []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(failed) == 0 {
+		w.written++
+		w.lastSuccess = now
+		return
+	}
+	if w.sinkErrors == nil {
+		w.sinkErrors = map[string]int64{}
+	}
+	for _, name := range failed {
+		w.sinkErrors[name]++
+	}
+}
+
+// logSummary logs the totals.
+func (w *writeTotals) logSummary(logger logr.Logger) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	lastWrite := "never"
+	if !w.lastSuccess.IsZero() {
+		lastWrite = w.lastSuccess.Format(time.RFC3339Nano)
+	}
+	sinkErrors := maps.Clone(w.sinkErrors)
+	if sinkErrors == nil {
+		sinkErrors = map[string]int64{}
+	}
+	logger.Info("Record write summary", "recordsWritten", w.written, "lastWrite", lastWrite, "sinkErrors", sinkErrors)
+}
+
+// SinkHealthCheck is a readiness check that fails once the last
+// UnhealthyAfter record writes have all errored.
+func (r *PodStartupReconciler) SinkHealthCheck(_ *http.Request) error {
+	threshold := r.UnhealthyAfter
+	if threshold <= 0 {
+		threshold = DefaultUnhealthyAfter
+	}
+	status, ok := r.LastWrite()
+	if !ok || status.ConsecutiveFailures < threshold {
+		return nil
+	}
+	return fmt.Errorf("last %d record writes failed: %w", status.ConsecutiveFailures, status.Err)
+}
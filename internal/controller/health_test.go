@@ -0,0 +1,124 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// toggleSink fails its writes while fail is set.
+type toggleSink struct {
+	fail atomic.Bool
+}
+
+func (s *toggleSink) Name() string { return "toggle" }
+
+func (s *toggleSink) Write(context.Context, Record) error {
+	if s.fail.Load() {
+		return errors.New("volume detached")
+	}
+	return nil
+}
+
+var _ = Describe("Sink health check", func() {
+	var (
+		sink *toggleSink
+		r    *PodStartupReconciler
+	)
+
+	BeforeEach(func() {
+		sink = &toggleSink{}
+		r = &PodStartupReconciler{Sinks: []Sink{sink}, UnhealthyAfter: 2}
+	})
+
+	// reconcile records a new pod each time, so no write is skipped as a
+	// duplicate.
+	attempt := 0
+	reconcile := func() {
+		attempt++
+		r.Client = nil
+		_, err := reconcilePod(context.Background(), r, newRunningPod(fmt.Sprintf("health-pod-%d", attempt)))
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("should be ready before anything is written", func() {
+		_, ok := r.LastWrite()
+		Expect(ok).To(BeFalse())
+		Expect(r.SinkHealthCheck(nil)).To(Succeed())
+	})
+
+	It("should fail after consecutive write failures and recover on success", func() {
+		reconcile()
+		Expect(r.SinkHealthCheck(nil)).To(Succeed())
+
+		sink.fail.Store(true)
+		reconcile()
+		Expect(r.SinkHealthCheck(nil)).To(Succeed(), "a single failure is tolerated")
+
+		reconcile()
+		Expect(r.SinkHealthCheck(nil)).To(MatchError(ContainSubstring("volume detached")))
+		status, ok := r.LastWrite()
+		Expect(ok).To(BeTrue())
+		Expect(status.ConsecutiveFailures).To(Equal(2))
+
+		sink.fail.Store(false)
+		reconcile()
+		Expect(r.SinkHealthCheck(nil)).To(Succeed())
+		status, _ = r.LastWrite()
+		Expect(status.Err).NotTo(HaveOccurred())
+		Expect(status.ConsecutiveFailures).To(BeZero())
+	})
+
+	It("should log a summary of the writes on shutdown", func() {
+		lastWrite := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+		r.now = func() time.Time { return lastWrite }
+		reconcile()
+		reconcile()
+		sink.fail.Store(true)
+		reconcile()
+
+		var logs strings.Builder
+		ctx := logf.IntoContext(context.Background(), funcr.New(func(prefix, args string) {
+			logs.WriteString(args + "\n")
+		}, funcr.Options{}))
+		Expect(r.Close(ctx)).To(Succeed())
+
+		Expect(logs.String()).To(ContainSubstring(`"msg"="Record write summary"`))
+		Expect(logs.String()).To(ContainSubstring(`"recordsWritten"=2`))
+		Expect(logs.String()).To(ContainSubstring(`"lastWrite"="2025-03-01T09:30:00Z"`))
+		Expect(logs.String()).To(ContainSubstring(`"sinkErrors"={"toggle"=1}`))
+	})
+
+	It("should report no writes when nothing was written", func() {
+		var logs strings.Builder
+		ctx := logf.IntoContext(context.Background(), funcr.New(func(prefix, args string) {
+			logs.WriteString(args + "\n")
+		}, funcr.Options{}))
+		Expect(r.Close(ctx)).To(Succeed())
+		Expect(logs.String()).To(ContainSubstring(`"recordsWritten"=0 "lastWrite"="never" "sinkErrors"={}`))
+	})
+})
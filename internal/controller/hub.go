@@ -0,0 +1,79 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import "sync"
+
+// DefaultHubBufferSize is the number of records buffered per subscriber
+// before the hub starts dropping records for that subscriber.
+const DefaultHubBufferSize = 256
+
+// Hub fans records out to any number of subscribers. Every subscriber gets
+// every record, but each one only has a bounded buffer: records are dropped
+// for a subscriber that falls behind so that a slow consumer can never block
+// Reconcile.
+type Hub struct {
+	mu         sync.RWMutex
+	subs       map[chan Record]struct{}
+	bufferSize int
+}
+
+// NewHub returns a Hub whose subscribers buffer up to bufferSize records.
+func NewHub(bufferSize int) *Hub {
+	if bufferSize <= 0 {
+		bufferSize = DefaultHubBufferSize
+	}
+	return &Hub{
+		subs:       map[chan Record]struct{}{},
+		bufferSize: bufferSize,
+	}
+}
+
+// Subscribe registers a new subscriber. The returned function unsubscribes
+// and closes the channel; it is safe to call more than once.
+func (h *Hub) Subscribe() (<-chan Record, func()) {
+	ch := make(chan Record, h.bufferSize)
+
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, ch)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers the record to every subscriber with room in its buffer.
+// Records must not be modified once published.
+func (h *Hub) Publish(rec Record) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch := range h.subs {
+		select {
+		case ch <- rec:
+		default:
+			// Subscriber is too slow, drop rather than block the reconcile path
+		}
+	}
+}
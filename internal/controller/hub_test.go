@@ -0,0 +1,116 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Hub", func() {
+	It("should deliver every record to every subscriber", func() {
+		hub := NewHub(4)
+		first, unsubFirst := hub.Subscribe()
+		defer unsubFirst()
+		second, unsubSecond := hub.Subscribe()
+		defer unsubSecond()
+
+		hub.Publish(Record{"pod": "a"})
+		hub.Publish(Record{"pod": "b"})
+
+		for _, ch := range []<-chan Record{first, second} {
+			Expect((<-ch)["pod"]).To(Equal("a"))
+			Expect((<-ch)["pod"]).To(Equal("b"))
+		}
+	})
+
+	It("should drop records for a slow subscriber without blocking", func() {
+		hub := NewHub(2)
+		slow, unsubscribe := hub.Subscribe()
+		defer unsubscribe()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := range 10 {
+				hub.Publish(Record{"pod": fmt.Sprintf("pod-%d", i)})
+			}
+		}()
+		Eventually(done).Should(BeClosed())
+
+		Expect(slow).To(HaveLen(2))
+		Expect((<-slow)["pod"]).To(Equal("pod-0"))
+		Expect((<-slow)["pod"]).To(Equal("pod-1"))
+	})
+
+	It("should close the channel on unsubscribe", func() {
+		hub := NewHub(1)
+		ch, unsubscribe := hub.Subscribe()
+		unsubscribe()
+		unsubscribe()
+
+		Expect(ch).To(BeClosed())
+		Expect(func() { hub.Publish(Record{"pod": "a"}) }).NotTo(Panic())
+	})
+})
+
+var _ = Describe("Events", func() {
+	It("should deliver the records produced by reconciles", func() {
+		r := &PodStartupReconciler{Sinks: []Sink{&recordingSink{}}}
+		events := r.Events()
+
+		_, err := reconcilePod(context.Background(), r, newRunningPod("evented"))
+		Expect(err).NotTo(HaveOccurred())
+		var rec Record
+		Eventually(events).Should(Receive(&rec))
+		Expect(rec).To(HaveKeyWithValue("pod", "evented"))
+
+		Expect(r.Close(context.Background())).To(Succeed())
+		Expect(events).To(BeClosed())
+	})
+
+	It("should not block reconciles when the consumer falls behind", func() {
+		builder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+		for i := range 3 {
+			builder = builder.WithObjects(newRunningPod(fmt.Sprintf("unread-%d", i)))
+		}
+		r := &PodStartupReconciler{Client: builder.Build(), Scheme: scheme.Scheme, Sinks: []Sink{&recordingSink{}}, Hub: NewHub(1)}
+		events := r.Events()
+
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			for i := range 3 {
+				req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("unread-%d", i)}}
+				_, err := r.Reconcile(context.Background(), req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+		}()
+		Eventually(done).Should(BeClosed())
+
+		Expect(events).To(HaveLen(1))
+		Expect((<-events)["pod"]).To(Equal("unread-0"))
+	})
+})
@@ -0,0 +1,228 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// DefaultInfluxFlushInterval is how often buffered points are written.
+	DefaultInfluxFlushInterval = 10 * time.Second
+
+	// DefaultInfluxMaxBatch is how many buffered points trigger a write
+	// before the interval passes.
+	DefaultInfluxMaxBatch = 1000
+
+	// InfluxMeasurement is the measurement every point is written to.
+	InfluxMeasurement = "pod_startup"
+)
+
+// InfluxSink batches records as InfluxDB line protocol points and writes
+// them over HTTP. Each record becomes one point of InfluxMeasurement, tagged
+// with its namespace, node and cluster, with a field per duration in seconds
+// named in snake case, e.g.
+//
+//	pod_startup,namespace=default,node=node-1 pod="web",to_ready=3.4,to_scheduled=0.2 1700000000000000000
+//
+// A batch is written every FlushInterval, once it holds MaxBatch points, and
+// on Close. Records without durations are skipped.
+type InfluxSink struct {
+	// URL is the write endpoint including its query, such as
+	// http://influxdb:8086/api/v2/write?org=acme&bucket=pods for InfluxDB 2
+	// or http://influxdb:8086/write?db=pods for InfluxDB 1. Timestamps are
+	// in nanoseconds, the default precision of both.
+	URL string
+
+	// Token, when set, is sent as the authorization token.
+	Token string
+
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+
+	// FlushInterval defaults to DefaultInfluxFlushInterval.
+	FlushInterval time.Duration
+
+	// MaxBatch defaults to DefaultInfluxMaxBatch.
+	MaxBatch int
+
+	// MaxBuffered caps the points held while writes fail, dropping the
+	// oldest beyond it. It defaults to batchBufferFactor times MaxBatch.
+	MaxBuffered int
+
+	batch batcher[string]
+	now   func() time.Time
+}
+
+// Name implements Sink.
+func (s *InfluxSink) Name() string { return "influx" }
+
+// Write implements Sink. It only buffers the point; write failures are
+// counted on the sink error metric and retried with the next batch.
+func (s *InfluxSink) Write(_ context.Context, rec Record) error {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	line, ok := influxLine(rec, now())
+	if !ok {
+		return nil
+	}
+
+	s.batch.add(line, s.limits())
+	return nil
+}
+
+// Start implements manager.Runnable, writing batches until ctx is done.
+// The last batch is written by Close.
+func (s *InfluxSink) Start(ctx context.Context) error {
+	interval := s.FlushInterval
+	if interval <= 0 {
+		interval = DefaultInfluxFlushInterval
+	}
+	return s.batch.run(ctx, interval, s.flush, "Failed to write points to InfluxDB")
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every
+// replica buffers the points it writes, so every replica must send them.
+func (s *InfluxSink) NeedLeaderElection() bool {
+	return false
+}
+
+// Close implements ClosingSink, writing whatever is still buffered.
+func (s *InfluxSink) Close(ctx context.Context) error {
+	return s.flush(ctx)
+}
+
+// flush writes the buffered points in one request. On failure the points
+// are put back to be retried with the next batch.
+func (s *InfluxSink) flush(ctx context.Context) error {
+	return s.batch.flush(ctx, s.limits(), func(ctx context.Context, lines []string) error {
+		if err := s.post(ctx, []byte(strings.Join(lines, "\n")+"\n")); err != nil {
+			return fmt.Errorf("writing %d points: %w", len(lines), err)
+		}
+		return nil
+	})
+}
+
+// post sends one batch of points to URL.
+func (s *InfluxSink) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Token "+s.Token)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// limits returns the batcher configuration of the sink.
+func (s *InfluxSink) limits() batchLimits {
+	limits := batchLimits{sink: s.Name(), maxBatch: s.MaxBatch, maxBuffered: s.MaxBuffered}
+	if limits.maxBatch <= 0 {
+		limits.maxBatch = DefaultInfluxMaxBatch
+	}
+	if limits.maxBuffered <= 0 {
+		limits.maxBuffered = batchBufferFactor * limits.maxBatch
+	}
+	return limits
+}
+
+// influxLine formats the record as a line protocol point at t, and false
+// when it has no durations to write.
+func influxLine(rec Record, t time.Time) (string, bool) {
+	durations := recordDurations(rec)
+	if len(durations) == 0 {
+		return "", false
+	}
+
+	var b strings.Builder
+	b.WriteString(InfluxMeasurement)
+	for _, tag := range []string{"cluster", "namespace", "node"} {
+		// Empty tag values are not allowed, so missing tags are left out
+		if value := recordString(rec, tag); value != "" {
+			b.WriteString("," + tag + "=" + influxTagEscaper.Replace(value))
+		}
+	}
+
+	b.WriteString(` pod="` + influxStringEscaper.Replace(recordString(rec, "pod")) + `"`)
+	names := make([]string, 0, len(durations))
+	for name := range durations {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		key := snakeCase(name)
+		if key == "pod" {
+			// Would duplicate the pod field, failing the whole batch
+			continue
+		}
+		// Field keys are escaped like tag values
+		b.WriteString("," + influxTagEscaper.Replace(key) + "=" + strconv.FormatFloat(durations[name].Seconds(), 'f', -1, 64))
+	}
+
+	b.WriteString(" " + strconv.FormatInt(t.UnixNano(), 10))
+	return b.String(), true
+}
+
+// influxTagEscaper escapes the characters that are special in tag values
+// and field keys.
+var influxTagEscaper = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `)
+
+// influxStringEscaper escapes the characters that are special in string
+// field values.
+var influxStringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// snakeCase converts a camel case record field name such as toReady to the
+// snake case InfluxDB convention, to_ready.
+func snakeCase(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			b.WriteByte('_')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
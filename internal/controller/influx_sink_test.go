@@ -0,0 +1,155 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeInflux records the body of every write it accepts, rejecting writes
+// while failing is set.
+type fakeInflux struct {
+	mu      sync.Mutex
+	writes  []string
+	auth    string
+	failing bool
+}
+
+func (f *fakeInflux) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failing {
+		http.Error(w, `{"message":"bucket not found"}`, http.StatusNotFound)
+		return
+	}
+	f.auth = req.Header.Get("Authorization")
+	f.writes = append(f.writes, string(body))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeInflux) Writes() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.writes...)
+}
+
+var _ = Describe("InfluxSink", func() {
+	writtenAt := time.Unix(1700000000, 0)
+
+	It("should format a record as a line protocol point", func() {
+		rec := Record{
+			"pod":       "web-0",
+			"namespace": "default",
+			"node":      "node 1",
+			"durations": map[string]string{"toReady": "3.4s", "toScheduled": "200ms", "scheduledToInitialized": "1s"},
+		}
+		line, ok := influxLine(rec, writtenAt)
+		Expect(ok).To(BeTrue())
+		Expect(line).To(Equal(`pod_startup,namespace=default,node=node\ 1 pod="web-0",` +
+			`scheduled_to_initialized=1,to_ready=3.4,to_scheduled=0.2 1700000000000000000`))
+	})
+
+	It("should escape field keys and skip a duration named pod", func() {
+		// Records written before custom duration names were restricted
+		line, ok := influxLine(Record{
+			"pod": "web-0", "namespace": "default",
+			"durations": map[string]string{"a b": "1s", "x,y": "2s", "k=v": "3s", "pod": "4s"},
+		}, writtenAt)
+		Expect(ok).To(BeTrue())
+		Expect(line).To(Equal(`pod_startup,namespace=default pod="web-0",a\ b=1,k\=v=3,x\,y=2 1700000000000000000`))
+	})
+
+	It("should tag the cluster and skip records without durations", func() {
+		line, ok := influxLine(Record{
+			"pod": "a", "namespace": "default", "cluster": "prod",
+			"durations": map[string]interface{}{"toReady": "2s"},
+		}, writtenAt)
+		Expect(ok).To(BeTrue())
+		Expect(line).To(HavePrefix("pod_startup,cluster=prod,namespace=default "))
+
+		_, ok = influxLine(Record{"pod": "b", "durations": map[string]string{}}, writtenAt)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should write a batch in one request", func() {
+		influx := &fakeInflux{}
+		server := httptest.NewServer(influx)
+		defer server.Close()
+		sink := &InfluxSink{URL: server.URL + "/api/v2/write?org=acme&bucket=pods", Token: "t0ken",
+			now: func() time.Time { return writtenAt }}
+
+		for _, name := range []string{"a", "b"} {
+			Expect(sink.Write(context.Background(), storedPod(name, "Running", "1s"))).To(Succeed())
+		}
+		Expect(influx.Writes()).To(BeEmpty(), "writes are only buffered")
+
+		Expect(sink.Close(context.Background())).To(Succeed())
+		Expect(influx.Writes()).To(HaveLen(1))
+		Expect(strings.Split(strings.TrimSpace(influx.Writes()[0]), "\n")).To(Equal([]string{
+			`pod_startup,namespace=default pod="a",to_ready=1 1700000000000000000`,
+			`pod_startup,namespace=default pod="b",to_ready=1 1700000000000000000`,
+		}))
+		Expect(influx.auth).To(Equal("Token t0ken"))
+	})
+
+	It("should write early once a batch is full", func() {
+		influx := &fakeInflux{}
+		server := httptest.NewServer(influx)
+		defer server.Close()
+		sink := &InfluxSink{URL: server.URL, FlushInterval: time.Hour, MaxBatch: 2}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() { _ = sink.Start(ctx) }()
+
+		Expect(sink.Write(ctx, storedPod("a", "Running", "1s"))).To(Succeed())
+		Consistently(influx.Writes, 100*time.Millisecond).Should(BeEmpty())
+		Expect(sink.Write(ctx, storedPod("b", "Running", "1s"))).To(Succeed())
+		Eventually(influx.Writes).Should(HaveLen(1))
+	})
+
+	It("should count failed writes and retry them with the next batch", func() {
+		influx := &fakeInflux{failing: true}
+		server := httptest.NewServer(influx)
+		defer server.Close()
+		sink := &InfluxSink{URL: server.URL}
+		before := testutil.ToFloat64(sinkErrorsTotal.WithLabelValues("influx"))
+
+		Expect(sink.Write(context.Background(), storedPod("a", "Running", "1s"))).To(Succeed())
+		Expect(sink.Close(context.Background())).To(MatchError(ContainSubstring("bucket not found")))
+		Expect(testutil.ToFloat64(sinkErrorsTotal.WithLabelValues("influx")) - before).To(BeNumerically("==", 1))
+
+		influx.mu.Lock()
+		influx.failing = false
+		influx.mu.Unlock()
+		Expect(sink.Write(context.Background(), storedPod("b", "Running", "1s"))).To(Succeed())
+		Expect(sink.Close(context.Background())).To(Succeed())
+		Expect(influx.Writes()).To(HaveLen(1))
+		Expect(strings.Count(influx.Writes()[0], "\n")).To(Equal(2))
+	})
+})
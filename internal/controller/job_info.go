@@ -0,0 +1,79 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// owningJob returns the Job controlling the pod, or nil for pods of anything
+// else and Jobs that can't be fetched.
+func (r *PodStartupReconciler) owningJob(ctx context.Context, pod corev1.Pod) *batchv1.Job {
+	ref := metav1.GetControllerOf(&pod)
+	if ref == nil || ref.Kind != "Job" {
+		return nil
+	}
+	if gv, err := schema.ParseGroupVersion(ref.APIVersion); err != nil || gv.Group != batchv1.GroupName {
+		return nil
+	}
+
+	var job batchv1.Job
+	getCtx, cancel := r.clientContext(ctx)
+	defer cancel()
+	if err := r.Get(getCtx, types.NamespacedName{Namespace: pod.Namespace, Name: ref.Name}, &job); err != nil {
+		logf.FromContext(ctx).V(1).Info("Failed to fetch the pod's Job, recording without Job times",
+			"job", ref.Name, "error", err.Error())
+		return nil
+	}
+	if job.UID != ref.UID {
+		return nil
+	}
+	return &job
+}
+
+// enrichWithJob adds the creation and start times of the pod's Job, and
+// measures from the Job's creation to the pod becoming ready and finishing,
+// as jobToPodReady and jobRuntime. These include the time the Job
+// controller took to create the pod, which pod durations leave out. Pods
+// not owned by a Job are left alone.
+func (r *PodStartupReconciler) enrichWithJob(ctx context.Context, pod corev1.Pod, ready, finished time.Time,
+	timestamps, durations map[string]string) {
+	job := r.owningJob(ctx, pod)
+	if job == nil || job.CreationTimestamp.IsZero() {
+		return
+	}
+	created := job.CreationTimestamp.Time
+	timestamps["jobCreated"] = fmtTime(created)
+	if job.Status.StartTime != nil {
+		timestamps["jobStarted"] = fmtTime(job.Status.StartTime.Time)
+	}
+	if !ready.IsZero() {
+		durations[jobToPodReadyName] = fmt.Sprintf("%v", max(ready.Sub(created), 0))
+	}
+	if !finished.IsZero() {
+		durations[jobRuntimeName] = fmt.Sprintf("%v", max(finished.Sub(created), 0))
+	}
+}
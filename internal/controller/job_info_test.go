@@ -0,0 +1,131 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Job enrichment", func() {
+	// createFinishedPod creates a pod that became ready and succeeded,
+	// optionally controlled by owner.
+	createFinishedPod := func(ctx context.Context, name string, owner *batchv1.Job) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: corev1.PodSpec{
+				NodeName:      "fake-node",
+				RestartPolicy: corev1.RestartPolicyNever,
+				Containers:    []corev1.Container{{Name: "c1", Image: "busybox"}},
+			},
+		}
+		if owner != nil {
+			pod.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(owner, batchv1.SchemeGroupVersion.WithKind("Job"))}
+		}
+		Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		DeferCleanup(func() { _ = k8sClient.Delete(context.Background(), pod) })
+
+		now := time.Now()
+		pod.Status = corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(now.Add(time.Second))},
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(now.Add(2 * time.Second))},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "c1",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					StartedAt:  metav1.NewTime(now.Add(time.Second)),
+					FinishedAt: metav1.NewTime(now.Add(4 * time.Second)),
+				}},
+			}},
+		}
+		Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+		return pod
+	}
+
+	reconcile := func(ctx context.Context, name string) Record {
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), Sinks: []Sink{recorder}, EnrichJobInfo: true}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: name}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).NotTo(BeEmpty())
+		return recorder.Records()[len(recorder.Records())-1]
+	}
+
+	It("should record the Job's times for a Job-owned pod", func() {
+		ctx := context.Background()
+
+		By("Creating a Job")
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "enrich-job",
+				Namespace: "default",
+				// Ignored by the API server, which sets its own
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-5 * time.Second).Truncate(time.Second)),
+			},
+			Spec: batchv1.JobSpec{
+				ManualSelector: ptr.To(true),
+				Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"job": "enrich-job"}},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"job": "enrich-job"}},
+					Spec: corev1.PodSpec{
+						RestartPolicy: corev1.RestartPolicyNever,
+						Containers:    []corev1.Container{{Name: "c1", Image: "busybox"}},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, job)).To(Succeed())
+		DeferCleanup(func() { _ = k8sClient.Delete(context.Background(), job) })
+		if job.Status.StartTime == nil {
+			job.Status.StartTime = ptr.To(metav1.NewTime(job.CreationTimestamp.Add(time.Second)))
+			Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+		}
+
+		createFinishedPod(ctx, "enrich-job-pod", job)
+
+		rec := reconcile(ctx, "enrich-job-pod")
+		Expect(recordTimestamp(rec, "jobCreated")).To(BeTemporally("==", job.CreationTimestamp.Time))
+		Expect(recordTimestamp(rec, "jobStarted")).To(BeTemporally("==", job.Status.StartTime.Time))
+
+		durations := recordDurations(rec)
+		Expect(durations).To(HaveKey("jobToPodReady"))
+		Expect(durations).To(HaveKey("jobRuntime"))
+		Expect(durations["jobToPodReady"]).To(BeNumerically(">=", 2*time.Second))
+		Expect(durations["jobRuntime"]).To(BeNumerically(">", durations["jobToPodReady"]))
+	})
+
+	It("should omit Job fields for pods not owned by a Job", func() {
+		ctx := context.Background()
+		createFinishedPod(ctx, "enrich-bare-pod", nil)
+
+		rec := reconcile(ctx, "enrich-bare-pod")
+		Expect(rec["timestamps"]).NotTo(HaveKey("jobCreated"))
+		Expect(recordDurations(rec)).NotTo(HaveKey("jobToPodReady"))
+		Expect(recordDurations(rec)).NotTo(HaveKey("jobRuntime"))
+	})
+})
@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// DefaultKafkaFlushTimeout bounds how long shutdown waits for Kafka messages
+// in flight to be delivered.
+const DefaultKafkaFlushTimeout = 10 * time.Second
+
+// KafkaProducer is the part of *kafka.Writer the KafkaSink uses, so tests can
+// substitute a fake.
+type KafkaProducer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaSink produces every record as a JSON message keyed by pod UID, or by
+// the id set by PodStartupReconciler.IDFunc.
+type KafkaSink struct {
+	// Producer delivers the messages.
+	Producer KafkaProducer
+
+	// FlushTimeout bounds how long Close waits for messages in flight.
+	// Defaults to DefaultKafkaFlushTimeout.
+	FlushTimeout time.Duration
+}
+
+// kafkaBatchTimeout is how long a write waits for others to share its
+// batch. Writes are synchronous, so this adds to every reconcile that
+// produces a record.
+const kafkaBatchTimeout = 10 * time.Millisecond
+
+// NewKafkaSink returns a sink producing to topic on the given brokers.
+// Messages are written synchronously with acknowledgement from all in-sync
+// replicas, so a message that can't be delivered fails Write like any other
+// sink: it is counted on the sink error metric and the pod state is not
+// marked recorded, so its next reconcile produces it again, giving
+// at-least-once delivery. Writes still in flight at shutdown are waited for
+// for up to flushTimeout.
+func NewKafkaSink(brokers []string, topic string, flushTimeout time.Duration) *KafkaSink {
+	return &KafkaSink{
+		Producer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: kafkaBatchTimeout,
+		},
+		FlushTimeout: flushTimeout,
+	}
+}
+
+// Name implements Sink.
+func (k *KafkaSink) Name() string {
+	return "kafka"
+}
+
+// Write implements Sink, returning once the message is acknowledged.
+func (k *KafkaSink) Write(ctx context.Context, rec Record) error {
+	value, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshalling record: %w", err)
+	}
+	key, _ := rec["id"].(string)
+	if key == "" {
+		key, _ = rec["uid"].(string)
+	}
+	return k.Producer.WriteMessages(ctx, kafka.Message{Key: []byte(key), Value: value})
+}
+
+// Close implements ClosingSink, waiting for messages in flight and closing
+// the producer. It gives up after FlushTimeout or when ctx is done, whichever
+// comes first.
+func (k *KafkaSink) Close(ctx context.Context) error {
+	timeout := k.FlushTimeout
+	if timeout <= 0 {
+		timeout = DefaultKafkaFlushTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- k.Producer.Close() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("kafka producer did not flush: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeProducer records produced messages instead of sending them.
+type fakeProducer struct {
+	mu       sync.Mutex
+	messages []kafka.Message
+	err      error
+	closed   bool
+	closeFor time.Duration
+}
+
+func (p *fakeProducer) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.messages = append(p.messages, msgs...)
+	return nil
+}
+
+func (p *fakeProducer) Close() error {
+	time.Sleep(p.closeFor)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+func (p *fakeProducer) Messages() []kafka.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]kafka.Message(nil), p.messages...)
+}
+
+var _ = Describe("KafkaSink", func() {
+	It("should produce the record keyed by pod UID", func() {
+		producer := &fakeProducer{}
+		r := &PodStartupReconciler{Sinks: []Sink{&KafkaSink{Producer: producer}}}
+
+		_, err := reconcilePod(context.Background(), r, newRunningPod("kafka-pod"))
+		Expect(err).NotTo(HaveOccurred())
+
+		msgs := producer.Messages()
+		Expect(msgs).To(HaveLen(1))
+		Expect(string(msgs[0].Key)).To(Equal("uid-kafka-pod"))
+
+		var payload Record
+		Expect(json.Unmarshal(msgs[0].Value, &payload)).To(Succeed())
+		Expect(payload).To(HaveKeyWithValue("pod", "kafka-pod"))
+		Expect(payload).To(HaveKeyWithValue("uid", "uid-kafka-pod"))
+		Expect(payload).To(HaveKey("durations"))
+	})
+
+	It("should count producer errors without failing the reconcile", func() {
+		producer := &fakeProducer{err: errors.New("broker unavailable")}
+		r := &PodStartupReconciler{Sinks: []Sink{&KafkaSink{Producer: producer}}}
+		before := testutil.ToFloat64(sinkErrorsTotal.WithLabelValues("kafka"))
+
+		_, err := reconcilePod(context.Background(), r, newRunningPod("kafka-down"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(testutil.ToFloat64(sinkErrorsTotal.WithLabelValues("kafka"))).To(Equal(before + 1))
+	})
+
+	It("should write synchronously so delivery errors reach the reconcile", func() {
+		writer, ok := NewKafkaSink([]string{"localhost:9092"}, "topic", time.Second).Producer.(*kafka.Writer)
+		Expect(ok).To(BeTrue())
+		Expect(writer.Async).To(BeFalse())
+		Expect(writer.RequiredAcks).To(Equal(kafka.RequireAll))
+	})
+
+	It("should produce a record again after a failed delivery", func() {
+		producer := &fakeProducer{err: errors.New("broker unavailable")}
+		r := &PodStartupReconciler{Sinks: []Sink{&KafkaSink{Producer: producer}}}
+		pod := newRunningPod("kafka-retry")
+
+		_, err := reconcilePod(context.Background(), r, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(producer.Messages()).To(BeEmpty())
+
+		producer.mu.Lock()
+		producer.err = nil
+		producer.mu.Unlock()
+
+		_, err = reconcilePod(context.Background(), r, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(producer.Messages()).To(HaveLen(1))
+		Expect(string(producer.Messages()[0].Key)).To(Equal("uid-kafka-retry"))
+
+		_, err = reconcilePod(context.Background(), r, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(producer.Messages()).To(HaveLen(1), "a delivered record must not be produced again")
+	})
+
+	It("should fail the reconcile on a failed delivery with FailHard", func() {
+		producer := &fakeProducer{err: errors.New("broker unavailable")}
+		r := &PodStartupReconciler{Sinks: []Sink{&KafkaSink{Producer: producer}}, FailHard: true}
+
+		_, err := reconcilePod(context.Background(), r, newRunningPod("kafka-fail-hard"))
+		Expect(err).To(MatchError(ContainSubstring("broker unavailable")))
+	})
+
+	It("should flush on Close within the timeout", func() {
+		producer := &fakeProducer{}
+		Expect((&KafkaSink{Producer: producer}).Close(context.Background())).To(Succeed())
+		Expect(producer.closed).To(BeTrue())
+
+		slow := &fakeProducer{closeFor: time.Second}
+		Expect((&KafkaSink{Producer: slow, FlushTimeout: 50 * time.Millisecond}).Close(context.Background())).
+			To(MatchError(ContainSubstring("did not flush")))
+	})
+})
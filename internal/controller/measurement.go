@@ -0,0 +1,122 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	monitoringv1alpha1 "github.com/karthikbhat19/pod-time-measure-controller/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=monitoring.karthik.dev,resources=podstartupmeasurements,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.karthik.dev,resources=podstartupmeasurements/status,verbs=get;update;patch
+
+// persistMeasurement creates or patches the PodStartupMeasurement named after
+// the pod so that its status mirrors the record. The measurement is owned by
+// the pod and is garbage collected along with it. The status is only written
+// when it changed.
+func (r *PodStartupReconciler) persistMeasurement(ctx context.Context, pod *corev1.Pod, rec Record) error {
+	m := &monitoringv1alpha1.PodStartupMeasurement{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+	}
+
+	createCtx, cancel := r.clientContext(ctx)
+	defer cancel()
+	if _, err := controllerutil.CreateOrPatch(createCtx, r.Client, m, func() error {
+		m.Spec.PodName = pod.Name
+		m.Spec.PodUID = pod.UID
+		// A recreated pod with the same name takes over the measurement
+		m.OwnerReferences = nil
+		return controllerutil.SetControllerReference(pod, m, r.Scheme)
+	}); err != nil {
+		return fmt.Errorf("creating or patching measurement: %w", err)
+	}
+
+	// Most reconciles leave the record unchanged, and writing the same
+	// status again would only bump the resourceVersion and wake watchers
+	status := measurementStatusFromRecord(rec)
+	if statusHash(m.Status) == statusHash(status) {
+		return nil
+	}
+
+	// Status is dropped on create, so it always goes through the
+	// subresource. The lock makes a concurrent writer show up as a
+	// conflict, rather than the comparison above being made against a
+	// stale status.
+	patch := client.MergeFromWithOptions(m.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	m.Status = status
+	statusCtx, cancelStatus := r.clientContext(ctx)
+	defer cancelStatus()
+	if err := r.Status().Patch(statusCtx, m, patch); err != nil {
+		return fmt.Errorf("patching measurement status: %w", err)
+	}
+	return nil
+}
+
+// observedTimestamps are taken from the reconcile time rather than the pod.
+var observedTimestamps = map[string]bool{"running": true}
+
+// measurementStatusFromRecord converts a record into its typed status form.
+// Empty timestamps are skipped, as are observedTimestamps, which move on
+// every reconcile and would defeat the unchanged-status check.
+func measurementStatusFromRecord(rec Record) monitoringv1alpha1.PodStartupMeasurementStatus {
+	status := monitoringv1alpha1.PodStartupMeasurementStatus{
+		Timestamps: map[string]metav1.Time{},
+		Durations:  map[string]metav1.Duration{},
+	}
+	status.Node, _ = rec["node"].(string)
+	if phase, ok := rec["phase"].(string); ok {
+		status.Phase = corev1.PodPhase(phase)
+	}
+
+	if timestamps, ok := rec["timestamps"].(map[string]string); ok {
+		for name, value := range timestamps {
+			if observedTimestamps[name] {
+				continue
+			}
+			if t, err := time.Parse(time.RFC3339, value); err == nil {
+				status.Timestamps[name] = metav1.NewTime(t)
+			}
+		}
+	}
+	if durations, ok := rec["durations"].(map[string]string); ok {
+		for name, value := range durations {
+			if d, err := time.ParseDuration(value); err == nil {
+				status.Durations[name] = metav1.Duration{Duration: d}
+			}
+		}
+	}
+	return status
+}
+
+// statusHash returns a content hash of the status as it is serialized, so
+// statuses that would be stored the same compare equal.
+func statusHash(status monitoringv1alpha1.PodStartupMeasurementStatus) string {
+	data, _ := json.Marshal(status)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	monitoringv1alpha1 "github.com/karthikbhat19/pod-time-measure-controller/api/v1alpha1"
+)
+
+var _ = Describe("PodStartupMeasurement", func() {
+	It("should be created for a reconciled pod", func() {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "measured-pod", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				NodeName:   "fake-node",
+				Containers: []corev1.Container{{Name: "c1", Image: "busybox"}},
+			},
+		}
+		Expect(k8sClient.Create(context.Background(), pod)).To(Succeed())
+
+		By("Marking the pod Ready")
+		Eventually(func() error {
+			var existing corev1.Pod
+			if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), &existing); err != nil {
+				return err
+			}
+			existing.Status = corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()},
+					{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()},
+				},
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "c1",
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.Now()}},
+				}},
+			}
+			return k8sClient.Status().Update(context.Background(), &existing)
+		}, 5*time.Second, 500*time.Millisecond).Should(Succeed())
+
+		By("Fetching the measurement named after the pod")
+		Eventually(func(g Gomega) {
+			var m monitoringv1alpha1.PodStartupMeasurement
+			g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), &m)).To(Succeed())
+			g.Expect(m.Spec.PodName).To(Equal("measured-pod"))
+			g.Expect(m.Status.Phase).To(Equal(corev1.PodRunning))
+			g.Expect(m.Status.Node).To(Equal("fake-node"))
+			g.Expect(m.Status.Durations).To(HaveKey("toReady"))
+			g.Expect(m.OwnerReferences).To(HaveLen(1))
+			g.Expect(m.OwnerReferences[0].Name).To(Equal("measured-pod"))
+		}, 10*time.Second, 500*time.Millisecond).Should(Succeed())
+	})
+
+	It("should patch the status of an existing measurement", func() {
+		ctx := context.Background()
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "uid-1"}}
+		c := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithStatusSubresource(&monitoringv1alpha1.PodStartupMeasurement{}).
+			Build()
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme}
+
+		Expect(r.persistMeasurement(ctx, pod, Record{"phase": "Pending", "node": "node-a"})).To(Succeed())
+		Expect(r.persistMeasurement(ctx, pod, Record{
+			"phase":      "Running",
+			"node":       "node-a",
+			"timestamps": map[string]string{"ready": "2025-01-01T00:00:03Z", "failed": ""},
+			"durations":  map[string]string{"toReady": "3.5s"},
+		})).To(Succeed())
+
+		var m monitoringv1alpha1.PodStartupMeasurement
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(pod), &m)).To(Succeed())
+		Expect(m.Spec.PodUID).To(BeEquivalentTo("uid-1"))
+		Expect(m.Status.Phase).To(Equal(corev1.PodRunning))
+		Expect(m.Status.Timestamps).To(HaveKey("ready"))
+		Expect(m.Status.Timestamps).NotTo(HaveKey("failed"))
+		Expect(m.Status.Durations["toReady"].Duration).To(Equal(3500 * time.Millisecond))
+	})
+
+	It("should not write an unchanged status", func() {
+		ctx := context.Background()
+		pod := newRunningPod("unchanged")
+		statusPatches := 0
+		c := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(pod).
+			WithStatusSubresource(&monitoringv1alpha1.PodStartupMeasurement{}).
+			WithInterceptorFuncs(interceptor.Funcs{
+				SubResourcePatch: func(ctx context.Context, c client.Client, subResource string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+					statusPatches++
+					return c.SubResource(subResource).Patch(ctx, obj, patch, opts...)
+				},
+			}).
+			Build()
+		clock := time.Now()
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, Sinks: []Sink{&recordingSink{}}, RecordMeasurements: true,
+			now: func() time.Time { return clock }}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statusPatches).To(Equal(1))
+
+		// A change that doesn't affect the record, such as a label, still
+		// triggers a reconcile of a new resourceVersion
+		var existing corev1.Pod
+		Expect(c.Get(ctx, req.NamespacedName, &existing)).To(Succeed())
+		existing.Labels = map[string]string{"team": "web"}
+		Expect(c.Update(ctx, &existing)).To(Succeed())
+		// Far enough apart that a reconcile-time timestamp would differ
+		// once truncated to RFC3339 seconds
+		clock = clock.Add(5 * time.Second)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statusPatches).To(Equal(1), "a no-op reconcile must not update the status")
+
+		var m monitoringv1alpha1.PodStartupMeasurement
+		Expect(c.Get(ctx, req.NamespacedName, &m)).To(Succeed())
+		Expect(m.Status.Durations).To(HaveKey("toReady"))
+	})
+
+	It("should requeue when the status patch conflicts", func() {
+		pod := newRunningPod("status-conflict")
+		c := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(pod).
+			WithStatusSubresource(&monitoringv1alpha1.PodStartupMeasurement{}).
+			WithInterceptorFuncs(interceptor.Funcs{
+				SubResourcePatch: func(context.Context, client.Client, string, client.Object, client.Patch, ...client.SubResourcePatchOption) error {
+					return apierrors.NewConflict(monitoringv1alpha1.GroupVersion.WithResource("podstartupmeasurements").GroupResource(), pod.Name, nil)
+				},
+			}).
+			Build()
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, Sinks: []Sink{&recordingSink{}}, RecordMeasurements: true}
+
+		result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(conflictRequeueDelay))
+	})
+})
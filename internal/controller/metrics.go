@@ -0,0 +1,200 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// DefaultExemplarThreshold is the time to ready above which an observation
+// carries an exemplar naming the pod.
+const DefaultExemplarThreshold = 30 * time.Second
+
+var (
+	// toReadyHistogram tracks time to ready across the cluster. Slow
+	// observations carry an exemplar with the pod's name and UID, so a
+	// latency spike can be traced back to the pod that caused it.
+	toReadyHistogram = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:                        "pod_startup_to_ready_seconds",
+		Help:                        "Time from pod creation to the Ready condition.",
+		Buckets:                     prometheus.ExponentialBuckets(0.5, 2, 10),
+		NativeHistogramBucketFactor: 1.1,
+		NativeHistogramMaxExemplars: 10,
+		NativeHistogramExemplarTTL:  10 * time.Minute,
+	})
+
+	// toReadyNodeSummary tracks time to ready per node, for spotting nodes
+	// that consistently start pods slowly.
+	toReadyNodeSummary = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Name:       "pod_startup_to_ready_node_seconds",
+		Help:       "Time from pod creation to the Ready condition, by node.",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+	}, []string{"node"})
+
+	// toReadyWindowP99 is the p99 time to ready per namespace over the last
+	// ToReadyWindow, which surfaces a recent regression the cumulative
+	// histogram would average away.
+	toReadyWindowP99 = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pod_startup_to_ready_p99_window_seconds",
+		Help: "99th percentile of the time from pod creation to Ready over a sliding window, by namespace.",
+	}, []string{"namespace"})
+
+	// scheduledToContainersStartedHistogram tracks the time from scheduling
+	// to the containers starting per node, which is mostly volume attach
+	// and image pulls, for spotting nodes that are slow to set pods up.
+	scheduledToContainersStartedHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pod_startup_scheduled_to_containers_started_seconds",
+		Help:    "Time from the PodScheduled condition to the pod's containers starting, by node.",
+		Buckets: prometheus.ExponentialBuckets(0.5, 2, 10),
+	}, []string{"node"})
+
+	// sinkErrorsTotal counts failed record writes, so persistent failures
+	// such as a full or read-only volume show up in dashboards.
+	sinkErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pod_startup_sink_errors_total",
+		Help: "Number of records a sink failed to write, by sink.",
+	}, []string{"sink"})
+
+	// sinkRecordsDroppedTotal counts records a batching sink gave up on
+	// because its buffer was full, which happens once writes have failed
+	// for long enough.
+	sinkRecordsDroppedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pod_startup_sink_records_dropped_total",
+		Help: "Number of buffered records a sink dropped because its buffer was full, by sink.",
+	}, []string{"sink"})
+
+	// recordsThrottledTotal counts records held back by the per-namespace
+	// rate limit, each retried once the namespace's bucket refills.
+	recordsThrottledTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pod_startup_records_throttled_total",
+		Help: "Number of records delayed by the per-namespace rate limit, by namespace.",
+	}, []string{"namespace"})
+
+	// podsNeverReadyTotal counts pods that failed or were deleted without
+	// ever being seen ready, a direct measure of failed startups.
+	podsNeverReadyTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pods_never_ready_total",
+		Help: "Number of pods that failed or were deleted without becoming ready, by namespace and reason.",
+	}, []string{"namespace", "reason"})
+
+	// logResetsTotal counts record files that could not be decoded and were
+	// started afresh, losing their history.
+	logResetsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "pod_startup_log_reset_total",
+		Help: "Number of times a corrupt record file was reset.",
+	})
+
+	// logFileBytes is the size of each FileSink file after its last write,
+	// for alerting before the volume holding it fills up.
+	logFileBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pod_startup_log_file_bytes",
+		Help: "Size in bytes of each record file written by the file sink, by path.",
+	}, []string{"path"})
+
+	// reconcileDuration tracks how long each Reconcile takes, including the
+	// synchronous sink writes, so the cost of rewriting a growing log file
+	// shows up as it grows.
+	reconcileDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "reconcile_duration_seconds",
+		Help:    "Time taken by each pod reconcile, including sink writes.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+	})
+
+	// podsPendingTotal is the number of pods currently in Pending, by why
+	// they are waiting.
+	podsPendingTotal = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pods_pending_total",
+		Help: "Number of pods currently Pending, by namespace and pending reason.",
+	}, []string{"namespace", "pendingReason"})
+
+	// recordsInMemory is the size of the query server's store, for watching
+	// it grow with the number of pods retained.
+	recordsInMemory = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "pod_startup_records_in_memory",
+		Help: "Number of records held in memory for the query server.",
+	}, func() float64 {
+		if store := metricsStore.Load(); store != nil {
+			return float64(store.Len())
+		}
+		return 0
+	})
+)
+
+// The reasons a pod counts in podsNeverReadyTotal.
+const (
+	neverReadyFailed  = "failed"
+	neverReadyDeleted = "deleted"
+)
+
+// metricsStore is the store recordsInMemory reports on, set by
+// SetupWithManager once the store exists.
+var metricsStore atomic.Pointer[RecordStore]
+
+func init() {
+	metrics.Registry.MustRegister(toReadyHistogram, toReadyNodeSummary, toReadyWindowP99, scheduledToContainersStartedHistogram,
+		sinkErrorsTotal, sinkRecordsDroppedTotal, recordsThrottledTotal, podsNeverReadyTotal, logResetsTotal, reconcileDuration, podsPendingTotal, recordsInMemory,
+		logFileBytes)
+}
+
+// observeToReady records a pod's time to ready, attaching an exemplar when it
+// exceeds exemplarThreshold. A non-positive threshold uses
+// DefaultExemplarThreshold. Callers must make sure each pod is only observed
+// once.
+func observeToReady(pod corev1.Pod, toReady, exemplarThreshold time.Duration) {
+	if exemplarThreshold <= 0 {
+		exemplarThreshold = DefaultExemplarThreshold
+	}
+	seconds := toReady.Seconds()
+
+	if toReady > exemplarThreshold {
+		toReadyHistogram.(prometheus.ExemplarObserver).ObserveWithExemplar(seconds, exemplarLabels(pod))
+	} else {
+		toReadyHistogram.Observe(seconds)
+	}
+	toReadyNodeSummary.WithLabelValues(pod.Spec.NodeName).Observe(seconds)
+}
+
+// observeToReadyWindow adds a pod's time to ready to the namespace's sliding
+// window and updates the windowed p99, removing the gauges of namespaces
+// without any pod ready within the window.
+func (r *PodStartupReconciler) observeToReadyWindow(namespace string, toReady time.Duration) {
+	window := r.ToReadyWindow
+	if window <= 0 {
+		window = DefaultToReadyWindow
+	}
+	p99, expired := r.toReadyP99.observe(namespace, toReady, r.clock(), window, 0.99)
+	toReadyWindowP99.WithLabelValues(namespace).Set(p99.Seconds())
+	for _, ns := range expired {
+		toReadyWindowP99.DeleteLabelValues(ns)
+	}
+}
+
+// exemplarLabels names the pod in an exemplar, truncating the name so the
+// labels stay within the exemplar size limit.
+func exemplarLabels(pod corev1.Pod) prometheus.Labels {
+	uid := string(pod.UID)
+	name := []rune(pod.Name)
+	if room := prometheus.ExemplarMaxRunes - len("pod") - len("uid") - len([]rune(uid)); len(name) > room {
+		name = name[:max(room, 0)]
+	}
+	return prometheus.Labels{"pod": string(name), "uid": uid}
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// gatherMetric returns the series of the named metric whose labels include
+// all of the given ones, or nil if there is none.
+func gatherMetric(name string, labels map[string]string) *dto.Metric {
+	families, err := metrics.Registry.Gather()
+	Expect(err).NotTo(HaveOccurred())
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	series:
+		for _, m := range family.GetMetric() {
+			got := map[string]string{}
+			for _, lp := range m.GetLabel() {
+				got[lp.GetName()] = lp.GetValue()
+			}
+			for k, v := range labels {
+				if got[k] != v {
+					continue series
+				}
+			}
+			return m
+		}
+	}
+	return nil
+}
+
+var _ = Describe("Node readiness summary", func() {
+	It("should collect samples per node", func() {
+		pod := corev1.Pod{Spec: corev1.PodSpec{NodeName: "summary-node"}}
+		observeToReady(pod, 2*time.Second, 0)
+		observeToReady(pod, 4*time.Second, 0)
+
+		Expect(testutil.CollectAndCount(toReadyNodeSummary, "pod_startup_to_ready_node_seconds")).To(BeNumerically(">", 0))
+
+		m := gatherMetric("pod_startup_to_ready_node_seconds", map[string]string{"node": "summary-node"})
+		Expect(m).NotTo(BeNil())
+		Expect(m.GetSummary().GetSampleCount()).To(BeEquivalentTo(2))
+		Expect(m.GetSummary().GetSampleSum()).To(BeNumerically("==", 6))
+		Expect(m.GetSummary().GetQuantile()).To(HaveLen(3))
+	})
+
+	It("should observe each ready pod only once", func() {
+		now := time.Now()
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "ready-once", Namespace: "default", UID: "ready-once-uid",
+				CreationTimestamp: metav1.NewTime(now.Add(-3 * time.Second)),
+			},
+			Spec: corev1.PodSpec{NodeName: "dedup-node"},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(now)},
+				},
+			},
+		}
+		r := &PodStartupReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build(),
+			Scheme: scheme.Scheme,
+		}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+
+		for range 3 {
+			_, err := r.Reconcile(context.Background(), req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		m := gatherMetric("pod_startup_to_ready_node_seconds", map[string]string{"node": "dedup-node"})
+		Expect(m).NotTo(BeNil())
+		Expect(m.GetSummary().GetSampleCount()).To(BeEquivalentTo(1))
+	})
+})
+
+var _ = Describe("Time to ready exemplars", func() {
+	// exemplarPods returns the pod names carried by the histogram's bucket
+	// exemplars.
+	exemplarPods := func() []string {
+		m := gatherMetric("pod_startup_to_ready_seconds", nil)
+		Expect(m).NotTo(BeNil())
+		var pods []string
+		for _, b := range m.GetHistogram().GetBucket() {
+			for _, lp := range b.GetExemplar().GetLabel() {
+				if lp.GetName() == "pod" {
+					pods = append(pods, lp.GetValue())
+				}
+			}
+		}
+		return pods
+	}
+
+	It("should attach an exemplar to slow pods only", func() {
+		slow := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "exemplar-slow", UID: "slow-uid"}}
+		fast := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "exemplar-fast", UID: "fast-uid"}}
+
+		observeToReady(slow, 90*time.Second, time.Minute)
+		observeToReady(fast, 2*time.Second, time.Minute)
+
+		Expect(exemplarPods()).To(ContainElement("exemplar-slow"))
+		Expect(exemplarPods()).NotTo(ContainElement("exemplar-fast"))
+	})
+
+	It("should keep exemplar labels within the size limit", func() {
+		pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: strings.Repeat("p", 253), UID: "0b9f6c1e-65a4-4c8e-9a3f-5a7c2f1d3e4b",
+		}}
+		labels := exemplarLabels(pod)
+
+		runes := 0
+		for k, v := range labels {
+			runes += len([]rune(k)) + len([]rune(v))
+		}
+		Expect(runes).To(BeNumerically("<=", prometheus.ExemplarMaxRunes))
+		Expect(labels).To(HaveKeyWithValue("uid", string(pod.UID)))
+		Expect(func() { observeToReady(pod, 2*time.Minute, time.Minute) }).NotTo(Panic())
+	})
+})
+
+var _ = Describe("Pending gauge", func() {
+	pendingFor := func(reason string) float64 {
+		return testutil.ToFloat64(podsPendingTotal.WithLabelValues("pending-gauge", reason))
+	}
+
+	It("should rise while a pod is Pending and fall once it runs", func() {
+		ctx := context.Background()
+		pod := newRunningPod("pending-to-running")
+		pod.Namespace = "pending-gauge"
+		pod.Status.Phase = corev1.PodPending
+		pod.Status.Conditions = []corev1.PodCondition{
+			{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable"},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build()
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, Sinks: []Sink{&recordingSink{}}}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pendingFor(PendingUnschedulable)).To(BeNumerically("==", 1))
+
+		// Reconciling the same state again must not count it twice
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pendingFor(PendingUnschedulable)).To(BeNumerically("==", 1))
+
+		var existing corev1.Pod
+		Expect(c.Get(ctx, req.NamespacedName, &existing)).To(Succeed())
+		existing.Status = newRunningPod("pending-to-running").Status
+		Expect(c.Status().Update(ctx, &existing)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pendingFor(PendingUnschedulable)).To(BeNumerically("==", 0))
+	})
+
+	It("should fall when a Pending pod is deleted", func() {
+		ctx := context.Background()
+		pod := newRunningPod("pending-deleted")
+		pod.Namespace = "pending-gauge"
+		pod.Status.Phase = corev1.PodPending
+		pod.Status.Conditions = nil
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build()
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, Sinks: []Sink{&recordingSink{}}}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pendingFor(PendingScheduling)).To(BeNumerically("==", 1))
+
+		Expect(c.Delete(ctx, pod)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pendingFor(PendingScheduling)).To(BeNumerically("==", 0))
+	})
+})
+
+var _ = Describe("Never ready counter", func() {
+	neverReady := func(reason string) float64 {
+		return testutil.ToFloat64(podsNeverReadyTotal.WithLabelValues("never-ready", reason))
+	}
+
+	var (
+		ctx context.Context
+		c   client.Client
+		r   *PodStartupReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		c = fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		r = &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, Sinks: []Sink{&recordingSink{}}}
+	})
+
+	reconcile := func(pod *corev1.Pod) {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("should count a pod deleted while Pending", func() {
+		pod := newRunningPod("deleted-pending")
+		pod.Namespace = "never-ready"
+		pod.Status.Phase = corev1.PodPending
+		pod.Status.Conditions = nil
+		Expect(c.Create(ctx, pod)).To(Succeed())
+		before := neverReady(neverReadyDeleted)
+
+		reconcile(pod)
+		Expect(neverReady(neverReadyDeleted)).To(Equal(before))
+
+		Expect(c.Delete(ctx, pod)).To(Succeed())
+		reconcile(pod)
+		reconcile(pod)
+		Expect(neverReady(neverReadyDeleted) - before).To(BeNumerically("==", 1))
+	})
+
+	It("should count a pod that Failed without becoming ready, once", func() {
+		pod := newRunningPod("failed-unready")
+		pod.Namespace = "never-ready"
+		pod.Status.Phase = corev1.PodFailed
+		pod.Status.Conditions = pod.Status.Conditions[:1]
+		Expect(c.Create(ctx, pod)).To(Succeed())
+		failedBefore, deletedBefore := neverReady(neverReadyFailed), neverReady(neverReadyDeleted)
+
+		reconcile(pod)
+		reconcile(pod)
+		Expect(neverReady(neverReadyFailed) - failedBefore).To(BeNumerically("==", 1))
+
+		// Deleting it afterwards is the same outcome
+		Expect(c.Delete(ctx, pod)).To(Succeed())
+		reconcile(pod)
+		Expect(neverReady(neverReadyDeleted)).To(Equal(deletedBefore))
+	})
+
+	It("should not count pods that were ready", func() {
+		pod := newRunningPod("ready-then-failed")
+		pod.Namespace = "never-ready"
+		Expect(c.Create(ctx, pod)).To(Succeed())
+		failedBefore, deletedBefore := neverReady(neverReadyFailed), neverReady(neverReadyDeleted)
+		reconcile(pod)
+
+		pod.Status.Phase = corev1.PodFailed
+		pod.Status.Conditions = pod.Status.Conditions[:1]
+		Expect(c.Status().Update(ctx, pod)).To(Succeed())
+		reconcile(pod)
+		Expect(c.Delete(ctx, pod)).To(Succeed())
+		reconcile(pod)
+
+		Expect(neverReady(neverReadyFailed)).To(Equal(failedBefore))
+		Expect(neverReady(neverReadyDeleted)).To(Equal(deletedBefore))
+	})
+})
+
+var _ = Describe("Reconcile duration", func() {
+	It("should collect a sample per reconcile", func() {
+		sampleCount := func() uint64 {
+			m := gatherMetric("reconcile_duration_seconds", nil)
+			Expect(m).NotTo(BeNil())
+			return m.GetHistogram().GetSampleCount()
+		}
+		before := sampleCount()
+
+		_, err := reconcilePod(context.Background(),
+			&PodStartupReconciler{Sinks: []Sink{&recordingSink{}}}, newRunningPod("timed"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sampleCount()).To(Equal(before + 1))
+	})
+})
+
+var _ = Describe("Records in memory gauge", func() {
+	It("should report the size of the store", func() {
+		previous := metricsStore.Load()
+		DeferCleanup(func() { metricsStore.Store(previous) })
+
+		store := NewRecordStore()
+		metricsStore.Store(store)
+		Expect(testutil.ToFloat64(recordsInMemory)).To(BeZero())
+
+		store.Put(storedPod("a", "Running", "1s"))
+		store.Put(storedPod("b", "Running", "2s"))
+		store.Put(storedPod("a", "Running", "1s"))
+		Expect(testutil.ToFloat64(recordsInMemory)).To(Equal(2.0))
+
+		store.Clear()
+		Expect(testutil.ToFloat64(recordsInMemory)).To(BeZero())
+	})
+})
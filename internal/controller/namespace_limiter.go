@@ -0,0 +1,57 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// namespaceLimiter keeps a token bucket per namespace, so a burst of records
+// in one namespace is throttled without holding back the others. The zero
+// value is ready to use.
+type namespaceLimiter struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// allow takes a token from the namespace's bucket, which refills at limit
+// records per second and holds a second's worth. When the bucket is empty it
+// reports false and how long until the next token.
+func (l *namespaceLimiter) allow(namespace string, limit float64, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limiters == nil {
+		l.limiters = map[string]*rate.Limiter{}
+	}
+	limiter, ok := l.limiters[namespace]
+	if !ok || float64(limiter.Limit()) != limit {
+		limiter = rate.NewLimiter(rate.Limit(limit), max(int(math.Ceil(limit)), 1))
+		l.limiters[namespace] = limiter
+	}
+	if limiter.AllowN(now, 1) {
+		return true, 0
+	}
+	reservation := limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	reservation.CancelAt(now)
+	return false, delay
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Per-namespace rate limit", func() {
+	now := time.Now()
+
+	It("should refill each namespace's bucket independently", func() {
+		var l namespaceLimiter
+		for range 2 {
+			Expect(l.allow("busy", 2, now)).To(BeTrue())
+		}
+		ok, delay := l.allow("busy", 2, now)
+		Expect(ok).To(BeFalse())
+		Expect(delay).To(Equal(500 * time.Millisecond))
+		Expect(l.allow("quiet", 2, now)).To(BeTrue())
+
+		ok, _ = l.allow("busy", 2, now.Add(500*time.Millisecond))
+		Expect(ok).To(BeTrue(), "a token is back after 1/limit seconds")
+	})
+
+	It("should throttle a bursty namespace without holding back others", func() {
+		var pods []*corev1.Pod
+		var objects []client.Object
+		for _, name := range []string{"burst-1", "burst-2", "burst-3"} {
+			pod := newRunningPod(name)
+			pod.Namespace = "rollout"
+			pods = append(pods, pod)
+			objects = append(objects, pod)
+		}
+		quiet := newRunningPod("quiet")
+		quiet.Namespace = "other"
+		objects = append(objects, quiet)
+
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build(),
+			Scheme: scheme.Scheme,
+			Sinks:  []Sink{recorder},
+			now:    func() time.Time { return now },
+
+			PerNamespaceRateLimit: 2,
+		}
+		before := testutil.ToFloat64(recordsThrottledTotal.WithLabelValues("rollout"))
+
+		var results []ctrl.Result
+		for _, pod := range append(pods, quiet) {
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)})
+			Expect(err).NotTo(HaveOccurred())
+			results = append(results, result)
+		}
+
+		Expect(recorder.Records()).To(HaveLen(3))
+		Expect(recorder.Records()[2]).To(HaveKeyWithValue("pod", "quiet"))
+		Expect(results[2].RequeueAfter).To(Equal(500*time.Millisecond), "the throttled record is retried")
+		Expect(testutil.ToFloat64(recordsThrottledTotal.WithLabelValues("rollout")) - before).To(BeNumerically("==", 1))
+		Expect(testutil.ToFloat64(recordsThrottledTotal.WithLabelValues("other"))).To(BeZero())
+	})
+})
@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+var _ = Describe("Namespace scope", func() {
+	// namespacesOf returns the namespaces of the recorded pods.
+	namespacesOf := func(recorder *recordingSink) []string {
+		var namespaces []string
+		for _, rec := range recorder.Records() {
+			namespaces = append(namespaces, recordString(rec, "namespace"))
+		}
+		return namespaces
+	}
+
+	It("should ignore pods outside the namespace when reconciling and backfilling", func() {
+		inside := newRunningPod("scoped-in")
+		inside.Namespace = "team-a"
+		outside := newRunningPod("scoped-out")
+		outside.Namespace = "team-b"
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(inside, outside).Build()
+
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, Sinks: []Sink{recorder}, Namespace: "team-a"}
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(outside)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(BeEmpty())
+
+		Expect(r.BackfillExisting(context.Background())).To(Succeed())
+		Expect(namespacesOf(recorder)).To(Equal([]string{"team-a"}))
+	})
+
+	It("should never reconcile pods outside the watched namespace", func() {
+		ctx := context.Background()
+
+		createReadyPod := func(namespace string) {
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})).To(Succeed())
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "scoped", Namespace: namespace},
+				Spec: corev1.PodSpec{
+					NodeName:   "fake-node",
+					Containers: []corev1.Container{{Name: "c1", Image: "busybox"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			pod.Status = newRunningPod("scoped").Status
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+		}
+
+		scoped := Config{WatchNamespace: "scope-in"}
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+			Scheme:                 scheme.Scheme,
+			Metrics:                metricsserver.Options{BindAddress: "0"},
+			HealthProbeBindAddress: "0",
+			Cache:                  scoped.CacheOptions(),
+			Controller:             config.Controller{SkipNameValidation: ptr.To(true)},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{
+			Client: mgr.GetClient(), Scheme: mgr.GetScheme(),
+			Sinks:     []Sink{recorder},
+			Namespace: scoped.WatchNamespace,
+		}
+		Expect(r.SetupWithManager(mgr)).To(Succeed())
+
+		mgrCtx, cancel := context.WithCancel(ctx)
+		DeferCleanup(cancel)
+		go func() {
+			defer GinkgoRecover()
+			Expect(mgr.Start(mgrCtx)).To(Succeed())
+		}()
+
+		createReadyPod("scope-in")
+		createReadyPod("scope-out")
+
+		Eventually(func() []string { return namespacesOf(recorder) }, 10*time.Second).Should(ContainElement("scope-in"))
+		Consistently(func() []string { return namespacesOf(recorder) }, 2*time.Second).ShouldNot(ContainElement("scope-out"))
+	})
+})
@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultNodeInfoTTL is how long a node's details are reused before the node
+// is fetched again.
+const DefaultNodeInfoTTL = time.Minute
+
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+
+// nodeInfo is the part of a node's status recorded alongside its pods.
+type nodeInfo struct {
+	KubeletVersion          string
+	OSImage                 string
+	ContainerRuntimeVersion string
+
+	// Created is when the node joined the cluster.
+	Created time.Time
+}
+
+// nodeInfoEntry is a cached lookup, successful or not.
+type nodeInfoEntry struct {
+	info    nodeInfo
+	fetched time.Time
+}
+
+// nodeInfoCache remembers node details for a short while so pods on the same
+// node don't each cost an API call. The zero value is ready to use.
+type nodeInfoCache struct {
+	mu      sync.Mutex
+	entries map[string]nodeInfoEntry
+}
+
+// lookup returns the details of the named node, fetching them when the
+// cached entry is older than ttl. Nodes that can't be fetched yield empty
+// details, which are cached too so a missing node isn't retried on every pod.
+func (c *nodeInfoCache) lookup(ctx context.Context, reader client.Reader, name string, ttl time.Duration) nodeInfo {
+	if ttl <= 0 {
+		ttl = DefaultNodeInfoTTL
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[name]
+	c.mu.Unlock()
+	if ok && time.Since(entry.fetched) < ttl {
+		return entry.info
+	}
+
+	var info nodeInfo
+	var node corev1.Node
+	if err := reader.Get(ctx, client.ObjectKey{Name: name}, &node); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to fetch node, recording without node details", "node", name)
+	} else {
+		info = nodeInfo{
+			KubeletVersion:          node.Status.NodeInfo.KubeletVersion,
+			OSImage:                 node.Status.NodeInfo.OSImage,
+			ContainerRuntimeVersion: node.Status.NodeInfo.ContainerRuntimeVersion,
+			Created:                 node.CreationTimestamp.Time,
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]nodeInfoEntry{}
+	}
+	c.entries[name] = nodeInfoEntry{info: info, fetched: time.Now()}
+	return info
+}
+
+// enrichWithNode adds the details of the pod's node to the record, along with
+// the node's age when the pod was scheduled onto it, since pods on freshly
+// joined nodes tend to start slower on a cold image cache.
+func (r *PodStartupReconciler) enrichWithNode(ctx context.Context, pod corev1.Pod, data Record) {
+	var info nodeInfo
+	if pod.Spec.NodeName != "" {
+		lookupCtx, cancel := r.clientContext(ctx)
+		defer cancel()
+		info = r.nodes.lookup(lookupCtx, r, pod.Spec.NodeName, r.NodeInfoTTL)
+	}
+	data["kubeletVersion"] = info.KubeletVersion
+	data["osImage"] = info.OSImage
+	data["containerRuntimeVersion"] = info.ContainerRuntimeVersion
+	if scheduled := getConditionTime(pod, corev1.PodScheduled); !info.Created.IsZero() && !scheduled.IsZero() {
+		data["nodeAgeAtSchedule"] = fmt.Sprintf("%v", max(scheduled.Sub(info.Created), 0))
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Node enrichment", func() {
+	var (
+		nodeGets atomic.Int32
+		recorder *recordingSink
+		r        *PodStartupReconciler
+	)
+
+	BeforeEach(func() {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "fake-node",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-5 * time.Second).Truncate(time.Second)),
+			},
+			Status: corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{
+				KubeletVersion:          "v1.34.0",
+				OSImage:                 "Ubuntu 24.04 LTS",
+				ContainerRuntimeVersion: "containerd://2.1.0",
+			}},
+		}
+		first, second, elsewhere := newRunningPod("node-a"), newRunningPod("node-b"), newRunningPod("node-c")
+		elsewhere.Spec.NodeName = "missing-node"
+
+		nodeGets.Store(0)
+		c := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(node, first, second, elsewhere).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if _, ok := obj.(*corev1.Node); ok {
+						nodeGets.Add(1)
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}).
+			Build()
+
+		recorder = &recordingSink{}
+		r = &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, Sinks: []Sink{recorder}, EnrichNodeInfo: true}
+	})
+
+	It("should record node details and reuse them for pods on the same node", func() {
+		for _, name := range []string{"node-a", "node-b"} {
+			_, err := reconcilePod(context.Background(), r, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(recorder.Records()).To(HaveLen(2))
+		for _, rec := range recorder.Records() {
+			Expect(rec).To(HaveKeyWithValue("kubeletVersion", "v1.34.0"))
+			Expect(rec).To(HaveKeyWithValue("osImage", "Ubuntu 24.04 LTS"))
+			Expect(rec).To(HaveKeyWithValue("containerRuntimeVersion", "containerd://2.1.0"))
+		}
+		Expect(nodeGets.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should record the age of a freshly joined node at scheduling time", func() {
+		_, err := reconcilePod(context.Background(), r, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "node-a", Namespace: "default"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(recorder.Records()).To(HaveLen(1))
+		Expect(recorder.Records()[0]).To(HaveKey("nodeAgeAtSchedule"))
+		age, err := time.ParseDuration(recorder.Records()[0]["nodeAgeAtSchedule"].(string))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(age).To(BeNumerically(">=", 0))
+		Expect(age).To(BeNumerically("<", 10*time.Second))
+	})
+
+	It("should leave the fields empty when the node can't be fetched", func() {
+		_, err := reconcilePod(context.Background(), r, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "node-c", Namespace: "default"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(recorder.Records()).To(HaveLen(1))
+		rec := recorder.Records()[0]
+		Expect(rec).To(HaveKeyWithValue("kubeletVersion", ""))
+		Expect(rec).To(HaveKeyWithValue("osImage", ""))
+		Expect(rec).To(HaveKeyWithValue("containerRuntimeVersion", ""))
+		Expect(rec).NotTo(HaveKey("nodeAgeAtSchedule"))
+	})
+})
@@ -0,0 +1,164 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+const (
+	// DefaultOTLPFlushInterval is how often buffered log records are
+	// exported.
+	DefaultOTLPFlushInterval = 5 * time.Second
+
+	// DefaultOTLPMaxBatch is the most log records exported at once.
+	DefaultOTLPMaxBatch = 512
+
+	// OTLPEventName is the event name of every exported log record.
+	OTLPEventName = "pod_startup"
+)
+
+// otlpAttributes maps record fields to the log record attributes they are
+// exported as, following the Kubernetes semantic conventions where there is
+// one.
+var otlpAttributes = []struct{ field, key string }{
+	{"cluster", "k8s.cluster.name"},
+	{"namespace", "k8s.namespace.name"},
+	{"node", "k8s.node.name"},
+	{"pod", "k8s.pod.name"},
+	{"uid", "k8s.pod.uid"},
+	{"ownerKind", "k8s.pod.owner.kind"},
+	{"ownerName", "k8s.pod.owner.name"},
+	{"phase", "k8s.pod.phase"},
+}
+
+// OTLPLogSink exports every record as an OTLP log record named
+// OTLPEventName, with the record as a JSON body. The pod's cluster,
+// namespace, node and owner are attributes, as is each duration in seconds
+// under pod_startup.duration, e.g. pod_startup.duration.toReady. Records are
+// exported in batches by the OpenTelemetry SDK and flushed on Close.
+type OTLPLogSink struct {
+	provider *sdklog.LoggerProvider
+	logger   otellog.Logger
+	now      func() time.Time
+}
+
+// NewOTLPLogSink exports to the OTLP/HTTP logs endpoint, such as
+// http://otel-collector:4318/v1/logs, every flushInterval or once maxBatch
+// records are buffered. Zero values use DefaultOTLPFlushInterval and
+// DefaultOTLPMaxBatch.
+func NewOTLPLogSink(ctx context.Context, endpoint string, flushInterval time.Duration, maxBatch int) (*OTLPLogSink, error) {
+	exporter, err := otlploghttp.New(ctx, otlploghttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP log exporter: %w", err)
+	}
+	return newOTLPLogSink(exporter, flushInterval, maxBatch), nil
+}
+
+// newOTLPLogSink exports through exporter, which tests replace.
+func newOTLPLogSink(exporter sdklog.Exporter, flushInterval time.Duration, maxBatch int) *OTLPLogSink {
+	if flushInterval <= 0 {
+		flushInterval = DefaultOTLPFlushInterval
+	}
+	if maxBatch <= 0 {
+		maxBatch = DefaultOTLPMaxBatch
+	}
+	processor := sdklog.NewBatchProcessor(countingExporter{exporter},
+		sdklog.WithExportInterval(flushInterval),
+		sdklog.WithExportMaxBatchSize(maxBatch))
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	return &OTLPLogSink{
+		provider: provider,
+		logger:   provider.Logger("github.com/karthikbhat19/pod-time-measure-controller"),
+	}
+}
+
+// Name implements Sink.
+func (s *OTLPLogSink) Name() string { return "otlp" }
+
+// Write implements Sink. It only hands the record to the batch processor;
+// export failures are counted on the sink error metric.
+func (s *OTLPLogSink) Write(ctx context.Context, rec Record) error {
+	body, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshalling record: %w", err)
+	}
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+
+	var lr otellog.Record
+	lr.SetEventName(OTLPEventName)
+	lr.SetTimestamp(now())
+	lr.SetSeverity(otellog.SeverityInfo)
+	lr.SetSeverityText("INFO")
+	lr.SetBody(otellog.StringValue(string(body)))
+	lr.AddAttributes(otlpRecordAttributes(rec)...)
+	s.logger.Emit(ctx, lr)
+	return nil
+}
+
+// Close implements ClosingSink, exporting whatever is still buffered.
+func (s *OTLPLogSink) Close(ctx context.Context) error {
+	return s.provider.Shutdown(ctx)
+}
+
+// otlpRecordAttributes returns the log record attributes of rec, leaving
+// out fields the record lacks.
+func otlpRecordAttributes(rec Record) []otellog.KeyValue {
+	var attrs []otellog.KeyValue
+	for _, a := range otlpAttributes {
+		if value := recordString(rec, a.field); value != "" {
+			attrs = append(attrs, otellog.String(a.key, value))
+		}
+	}
+	durations := recordDurations(rec)
+	names := make([]string, 0, len(durations))
+	for name := range durations {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		attrs = append(attrs, otellog.Float64("pod_startup.duration."+name, durations[name].Seconds()))
+	}
+	return attrs
+}
+
+// countingExporter counts the records of failed exports on the sink error
+// metric, as the batch processor only reports the error to the global
+// OpenTelemetry error handler.
+type countingExporter struct {
+	sdklog.Exporter
+}
+
+// Export implements sdklog.Exporter.
+func (e countingExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	err := e.Exporter.Export(ctx, records)
+	if err != nil {
+		sinkErrorsTotal.WithLabelValues("otlp").Add(float64(len(records)))
+	}
+	return err
+}
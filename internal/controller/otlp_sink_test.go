@@ -0,0 +1,134 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// memoryLogExporter keeps the log records it is given, failing exports
+// while failing is set.
+type memoryLogExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+	failing bool
+}
+
+func (e *memoryLogExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.failing {
+		return errors.New("collector unavailable")
+	}
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *memoryLogExporter) Shutdown(context.Context) error   { return nil }
+func (e *memoryLogExporter) ForceFlush(context.Context) error { return nil }
+
+func (e *memoryLogExporter) Records() []sdklog.Record {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]sdklog.Record(nil), e.records...)
+}
+
+// logAttributes returns the attributes of a log record by key.
+func logAttributes(r sdklog.Record) map[string]otellog.Value {
+	attrs := map[string]otellog.Value{}
+	r.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	return attrs
+}
+
+var _ = Describe("OTLPLogSink", func() {
+	rec := Record{
+		"pod":       "web-0",
+		"namespace": "default",
+		"node":      "node-1",
+		"uid":       "uid-web-0",
+		"ownerKind": "ReplicaSet",
+		"ownerName": "web-5d8f7",
+		"durations": map[string]string{"toReady": "3.4s", "toScheduled": "200ms"},
+	}
+
+	It("should export records as log records with the pod as attributes", func() {
+		exporter := &memoryLogExporter{}
+		sink := newOTLPLogSink(exporter, time.Hour, 0)
+		writtenAt := time.Unix(1700000000, 0)
+		sink.now = func() time.Time { return writtenAt }
+
+		Expect(sink.Write(context.Background(), rec)).To(Succeed())
+		Expect(exporter.Records()).To(BeEmpty(), "only exported on flush")
+		Expect(sink.Close(context.Background())).To(Succeed())
+
+		Expect(exporter.Records()).To(HaveLen(1))
+		lr := exporter.Records()[0]
+		Expect(lr.EventName()).To(Equal(OTLPEventName))
+		Expect(lr.Timestamp()).To(Equal(writtenAt))
+		Expect(lr.Severity()).To(Equal(otellog.SeverityInfo))
+
+		attrs := logAttributes(lr)
+		Expect(attrs["k8s.namespace.name"].AsString()).To(Equal("default"))
+		Expect(attrs["k8s.node.name"].AsString()).To(Equal("node-1"))
+		Expect(attrs["k8s.pod.name"].AsString()).To(Equal("web-0"))
+		Expect(attrs["k8s.pod.owner.kind"].AsString()).To(Equal("ReplicaSet"))
+		Expect(attrs["k8s.pod.owner.name"].AsString()).To(Equal("web-5d8f7"))
+		Expect(attrs["pod_startup.duration.toReady"].AsFloat64()).To(BeNumerically("~", 3.4))
+		Expect(attrs["pod_startup.duration.toScheduled"].AsFloat64()).To(BeNumerically("~", 0.2))
+		Expect(attrs).NotTo(HaveKey("k8s.cluster.name"), "missing fields are left out")
+
+		var body Record
+		Expect(json.Unmarshal([]byte(lr.Body().AsString()), &body)).To(Succeed())
+		Expect(body).To(HaveKeyWithValue("pod", "web-0"))
+	})
+
+	It("should export once a batch is full", func() {
+		exporter := &memoryLogExporter{}
+		sink := newOTLPLogSink(exporter, time.Hour, 2)
+		DeferCleanup(sink.Close, context.Background())
+
+		for range 2 {
+			Expect(sink.Write(context.Background(), rec)).To(Succeed())
+		}
+		Eventually(exporter.Records).Should(HaveLen(2))
+	})
+
+	It("should count records that failed to export", func() {
+		exporter := &memoryLogExporter{failing: true}
+		sink := newOTLPLogSink(exporter, time.Hour, 0)
+		before := testutil.ToFloat64(sinkErrorsTotal.WithLabelValues("otlp"))
+
+		Expect(sink.Write(context.Background(), rec)).To(Succeed())
+		Expect(sink.Close(context.Background())).NotTo(Succeed())
+		Expect(testutil.ToFloat64(sinkErrorsTotal.WithLabelValues("otlp")) - before).To(BeEquivalentTo(1))
+	})
+})
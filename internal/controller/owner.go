@@ -0,0 +1,125 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
+
+// intermediateOwners are the kinds that are usually managed by another
+// controller, so the owner chain is followed through them: ReplicaSets to
+// their Deployment and Jobs to their CronJob.
+var intermediateOwners = map[schema.GroupKind]bool{
+	{Group: "apps", Kind: "ReplicaSet"}: true,
+	{Group: "batch", Kind: "Job"}:       true,
+}
+
+// maxOwnerDepth bounds how far the owner chain is followed.
+const maxOwnerDepth = 4
+
+// topLevelOwnerKind returns the kind of the controller at the top of the
+// pod's owner chain, such as Deployment for a pod of a ReplicaSet, or "" for
+// a pod without a controller. Only owners' metadata is fetched. When an
+// intermediate owner is gone, its own kind is returned.
+func (r *PodStartupReconciler) topLevelOwnerKind(ctx context.Context, pod corev1.Pod) (string, error) {
+	ref := metav1.GetControllerOf(&pod)
+	for depth := 0; ref != nil; depth++ {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			return "", fmt.Errorf("parsing owner %s/%s: %w", ref.Kind, ref.Name, err)
+		}
+		if depth == maxOwnerDepth || !intermediateOwners[gv.WithKind(ref.Kind).GroupKind()] {
+			return ref.Kind, nil
+		}
+
+		owner := &metav1.PartialObjectMetadata{}
+		owner.SetGroupVersionKind(gv.WithKind(ref.Kind))
+		getCtx, cancel := r.clientContext(ctx)
+		err = r.Get(getCtx, types.NamespacedName{Namespace: pod.Namespace, Name: ref.Name}, owner)
+		cancel()
+		if apierrors.IsNotFound(err) {
+			return ref.Kind, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("fetching owner %s/%s: %w", ref.Kind, ref.Name, err)
+		}
+		next := metav1.GetControllerOfNoCopy(owner)
+		if next == nil {
+			return ref.Kind, nil
+		}
+		ref = next
+	}
+	return "", nil
+}
+
+// ownerAllowed reports whether the pod's top-level owner kind is one of
+// OwnerKinds. Every pod is allowed when OwnerKinds is empty, otherwise pods
+// without a controller are not.
+func (r *PodStartupReconciler) ownerAllowed(ctx context.Context, pod corev1.Pod) (bool, error) {
+	if len(r.OwnerKinds) == 0 {
+		return true, nil
+	}
+	kind, err := r.topLevelOwnerKind(ctx, pod)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(r.OwnerKinds, kind), nil
+}
+
+// podTemplateHash returns the revision of the pod's Deployment from its
+// pod-template-hash label, or when the pod lacks it, from the same label on
+// the ReplicaSet controlling it. It is "" for pods not managed by a
+// Deployment, or when the ReplicaSet can't be fetched.
+func (r *PodStartupReconciler) podTemplateHash(ctx context.Context, pod corev1.Pod) string {
+	if hash := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; hash != "" {
+		return hash
+	}
+	ref := metav1.GetControllerOf(&pod)
+	if ref == nil || ref.Kind != "ReplicaSet" {
+		return ""
+	}
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil || gv.Group != appsv1.GroupName {
+		return ""
+	}
+
+	owner := &metav1.PartialObjectMetadata{}
+	owner.SetGroupVersionKind(gv.WithKind(ref.Kind))
+	getCtx, cancel := r.clientContext(ctx)
+	defer cancel()
+	if err := r.Get(getCtx, types.NamespacedName{Namespace: pod.Namespace, Name: ref.Name}, owner); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logf.FromContext(ctx).V(1).Info("Failed to fetch ReplicaSet for the pod template hash",
+				"replicaSet", ref.Name, "error", err.Error())
+		}
+		return ""
+	}
+	return owner.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+}
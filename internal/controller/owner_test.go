@@ -0,0 +1,137 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Owner kind filter", func() {
+	controlledBy := func(pod *corev1.Pod, apiVersion, kind, name string) *corev1.Pod {
+		pod.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Name:       name,
+			UID:        types.UID(name + "-uid"),
+			Controller: ptr.To(true),
+		}}
+		return pod
+	}
+
+	var (
+		deploymentPod, daemonSetPod, unownedPod *corev1.Pod
+		recorder                                *recordingSink
+		r                                       *PodStartupReconciler
+	)
+
+	BeforeEach(func() {
+		replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name:      "web-5d8f7",
+			Namespace: "default",
+			Labels:    map[string]string{"pod-template-hash": "5d8f7"},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "web",
+				UID:        "web-uid",
+				Controller: ptr.To(true),
+			}},
+		}}
+		deploymentPod = controlledBy(newRunningPod("web-5d8f7-abcde"), "apps/v1", "ReplicaSet", replicaSet.Name)
+		daemonSetPod = controlledBy(newRunningPod("agent-xyz12"), "apps/v1", "DaemonSet", "agent")
+		unownedPod = newRunningPod("debug")
+
+		recorder = &recordingSink{}
+		r = &PodStartupReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(replicaSet, deploymentPod, daemonSetPod, unownedPod).
+				Build(),
+			Scheme:     scheme.Scheme,
+			Sinks:      []Sink{recorder},
+			OwnerKinds: []string{"Deployment"},
+		}
+	})
+
+	It("should resolve the top-level owner through ReplicaSets", func() {
+		ctx := context.Background()
+		Expect(r.topLevelOwnerKind(ctx, *deploymentPod)).To(Equal("Deployment"))
+		Expect(r.topLevelOwnerKind(ctx, *daemonSetPod)).To(Equal("DaemonSet"))
+		Expect(r.topLevelOwnerKind(ctx, *unownedPod)).To(BeEmpty())
+	})
+
+	It("should record pods of allowed owners only", func() {
+		for _, pod := range []*corev1.Pod{deploymentPod, daemonSetPod, unownedPod} {
+			_, err := reconcilePod(context.Background(), r, pod)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(recorder.Records()).To(HaveLen(1))
+		Expect(recorder.Records()[0]).To(HaveKeyWithValue("pod", deploymentPod.Name))
+	})
+
+	It("should record every pod without a filter", func() {
+		r.OwnerKinds = nil
+		for _, pod := range []*corev1.Pod{deploymentPod, daemonSetPod, unownedPod} {
+			_, err := reconcilePod(context.Background(), r, pod)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(recorder.Records()).To(HaveLen(3))
+	})
+
+	It("should capture the pod template hash", func() {
+		ctx := context.Background()
+		labelled := newRunningPod("web-7c9d4-fghij")
+		labelled.Labels = map[string]string{"pod-template-hash": "7c9d4"}
+		Expect(r.podTemplateHash(ctx, *labelled)).To(Equal("7c9d4"))
+
+		// Without its own label the pod takes the ReplicaSet's
+		Expect(r.podTemplateHash(ctx, *deploymentPod)).To(Equal("5d8f7"))
+		Expect(r.podTemplateHash(ctx, *daemonSetPod)).To(BeEmpty())
+		Expect(r.podTemplateHash(ctx, *unownedPod)).To(BeEmpty())
+	})
+
+	It("should record the pod template hash", func() {
+		r.OwnerKinds = nil
+		deploymentPod.Labels = map[string]string{"pod-template-hash": "5d8f7"}
+		_, err := reconcilePod(context.Background(), r, deploymentPod)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = reconcilePod(context.Background(), r, unownedPod)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(recorder.Records()).To(HaveLen(2))
+		Expect(recorder.Records()[0]).To(HaveKeyWithValue("podTemplateHash", "5d8f7"))
+		Expect(recorder.Records()[1]).To(HaveKeyWithValue("podTemplateHash", ""))
+		Expect(recorder.Records()[0]).To(HaveKeyWithValue("ownerKind", "ReplicaSet"))
+		Expect(recorder.Records()[0]).To(HaveKeyWithValue("ownerName", "web-5d8f7"))
+		Expect(recorder.Records()[1]).NotTo(HaveKey("ownerKind"))
+	})
+
+	It("should fall back to the ReplicaSet when it is gone", func() {
+		orphan := controlledBy(newRunningPod("orphan"), "apps/v1", "ReplicaSet", "deleted-rs")
+		Expect(r.topLevelOwnerKind(context.Background(), *orphan)).To(Equal("ReplicaSet"))
+	})
+})
@@ -0,0 +1,243 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+const (
+	// DefaultParquetFlushInterval is how often buffered rows are written
+	// out as a new file.
+	DefaultParquetFlushInterval = 5 * time.Minute
+
+	// DefaultParquetMaxRows is how many buffered rows trigger a new file
+	// before the interval passes.
+	DefaultParquetMaxRows = 10000
+)
+
+// parquetRow is the schema of the ParquetSink's files. It mirrors the
+// record, with timestamps as Unix milliseconds and durations as seconds.
+// Missing values are null.
+type parquetRow struct {
+	Pod                  string            `parquet:"pod"`
+	Namespace            string            `parquet:"namespace"`
+	UID                  string            `parquet:"uid"`
+	Node                 string            `parquet:"node"`
+	Phase                string            `parquet:"phase"`
+	Cluster              string            `parquet:"cluster"`
+	SchemaVersion        string            `parquet:"schemaVersion"`
+	QOSClass             string            `parquet:"qosClass"`
+	CPURequestMillicores int64             `parquet:"cpuRequestMillicores"`
+	MemoryRequestBytes   int64             `parquet:"memoryRequestBytes"`
+	Timestamps           parquetTimestamps `parquet:"timestamps"`
+	Durations            parquetDurations  `parquet:"durations"`
+}
+
+type parquetTimestamps struct {
+	Created              *int64 `parquet:"created,optional"`
+	Scheduled            *int64 `parquet:"scheduled,optional"`
+	Initialized          *int64 `parquet:"initialized,optional"`
+	ContainersStarted    *int64 `parquet:"containersStarted,optional"`
+	AllContainersStarted *int64 `parquet:"allContainersStarted,optional"`
+	Running              *int64 `parquet:"running,optional"`
+	Ready                *int64 `parquet:"ready,optional"`
+	Succeeded            *int64 `parquet:"succeeded,optional"`
+	Failed               *int64 `parquet:"failed,optional"`
+	FirstObserved        *int64 `parquet:"firstObserved,optional"`
+}
+
+type parquetDurations struct {
+	ToScheduled                  *float64 `parquet:"toScheduled,optional"`
+	ToInitialized                *float64 `parquet:"toInitialized,optional"`
+	ScheduledToInitialized       *float64 `parquet:"scheduledToInitialized,optional"`
+	ToContainersStarted          *float64 `parquet:"toContainersStarted,optional"`
+	ScheduledToContainersStarted *float64 `parquet:"scheduledToContainersStarted,optional"`
+	ToAllContainersStarted       *float64 `parquet:"toAllContainersStarted,optional"`
+	ToReady                      *float64 `parquet:"toReady,optional"`
+	ToSucceeded                  *float64 `parquet:"toSucceeded,optional"`
+	ToFailed                     *float64 `parquet:"toFailed,optional"`
+	Runtime                      *float64 `parquet:"runtime,optional"`
+	TerminationDuration          *float64 `parquet:"terminationDuration,optional"`
+}
+
+// ParquetSink batches records and writes each batch to Dir as a Parquet
+// file named pod_startup_<yyyymmdd>-<hhmmss>-<seq>.parquet, for loading
+// into a data warehouse. A batch is written every FlushInterval, once it
+// holds MaxRows rows, and on Close. Only the fields of parquetRow are kept;
+// custom durations and other optional parts of the record are dropped.
+type ParquetSink struct {
+	// Dir is where files are written. It must exist.
+	Dir string
+
+	// FlushInterval defaults to DefaultParquetFlushInterval.
+	FlushInterval time.Duration
+
+	// MaxRows defaults to DefaultParquetMaxRows.
+	MaxRows int
+
+	// MaxBuffered caps the rows held while writes fail, dropping the
+	// oldest beyond it. It defaults to batchBufferFactor times MaxRows.
+	MaxBuffered int
+
+	batch batcher[parquetRow]
+	mu    sync.Mutex
+	seq   int
+	now   func() time.Time
+}
+
+// Name implements Sink.
+func (s *ParquetSink) Name() string { return "parquet" }
+
+// Write implements Sink. It only buffers the row; write failures are
+// counted on the sink error metric and retried with the next batch.
+func (s *ParquetSink) Write(_ context.Context, rec Record) error {
+	s.batch.add(newParquetRow(rec), s.limits())
+	return nil
+}
+
+// Start implements manager.Runnable, writing batches until ctx is done.
+// The last batch is written by Close.
+func (s *ParquetSink) Start(ctx context.Context) error {
+	interval := s.FlushInterval
+	if interval <= 0 {
+		interval = DefaultParquetFlushInterval
+	}
+	return s.batch.run(ctx, interval, s.flush, "Failed to write Parquet file", "dir", s.Dir)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every
+// replica buffers the rows it writes, so every replica must write them.
+func (s *ParquetSink) NeedLeaderElection() bool {
+	return false
+}
+
+// Close implements ClosingSink, writing whatever is still buffered.
+func (s *ParquetSink) Close(ctx context.Context) error {
+	return s.flush(ctx)
+}
+
+// flush writes the buffered rows as one file. On failure the rows are put
+// back to be retried with the next batch.
+func (s *ParquetSink) flush(ctx context.Context) error {
+	return s.batch.flush(ctx, s.limits(), func(_ context.Context, rows []parquetRow) error {
+		return writeParquetFile(filepath.Join(s.Dir, s.nextName()), rows)
+	})
+}
+
+// writeParquetFile writes rows to path, replacing it atomically so readers
+// never see a partial file.
+func writeParquetFile(path string, rows []parquetRow) error {
+	var buf bytes.Buffer
+	w := parquet.NewGenericWriter[parquetRow](&buf)
+	if _, err := w.Write(rows); err != nil {
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+	return writeFileAtomic(path, buf.Bytes(), false, DefaultFileMode)
+}
+
+// nextName names the next file. The sequence number keeps names unique
+// within the same second.
+func (s *ParquetSink) nextName() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	s.seq++
+	return fmt.Sprintf("pod_startup_%s-%06d.parquet", now().UTC().Format("20060102-150405"), s.seq)
+}
+
+// limits returns the batcher configuration of the sink.
+func (s *ParquetSink) limits() batchLimits {
+	limits := batchLimits{sink: s.Name(), maxBatch: s.MaxRows, maxBuffered: s.MaxBuffered}
+	if limits.maxBatch <= 0 {
+		limits.maxBatch = DefaultParquetMaxRows
+	}
+	if limits.maxBuffered <= 0 {
+		limits.maxBuffered = batchBufferFactor * limits.maxBatch
+	}
+	return limits
+}
+
+// newParquetRow converts a record to its row.
+func newParquetRow(rec Record) parquetRow {
+	millis := func(name string) *int64 {
+		t := recordTimestamp(rec, name)
+		if t.IsZero() {
+			return nil
+		}
+		ms := t.UnixMilli()
+		return &ms
+	}
+	seconds := func(name string) *float64 {
+		d, ok := recordDuration(rec, name)
+		if !ok {
+			return nil
+		}
+		s := d.Seconds()
+		return &s
+	}
+	return parquetRow{
+		Pod:                  recordString(rec, "pod"),
+		Namespace:            recordString(rec, "namespace"),
+		UID:                  recordString(rec, "uid"),
+		Node:                 recordString(rec, "node"),
+		Phase:                recordString(rec, "phase"),
+		Cluster:              recordString(rec, "cluster"),
+		SchemaVersion:        recordString(rec, "schemaVersion"),
+		QOSClass:             recordString(rec, "qosClass"),
+		CPURequestMillicores: recordInt64(rec, "cpuRequestMillicores"),
+		MemoryRequestBytes:   recordInt64(rec, "memoryRequestBytes"),
+		Timestamps: parquetTimestamps{
+			Created:              millis("created"),
+			Scheduled:            millis("scheduled"),
+			Initialized:          millis("initialized"),
+			ContainersStarted:    millis("containersStarted"),
+			AllContainersStarted: millis("allContainersStarted"),
+			Running:              millis("running"),
+			Ready:                millis("ready"),
+			Succeeded:            millis("succeeded"),
+			Failed:               millis("failed"),
+			FirstObserved:        millis("firstObserved"),
+		},
+		Durations: parquetDurations{
+			ToScheduled:                  seconds("toScheduled"),
+			ToInitialized:                seconds("toInitialized"),
+			ScheduledToInitialized:       seconds("scheduledToInitialized"),
+			ToContainersStarted:          seconds("toContainersStarted"),
+			ScheduledToContainersStarted: seconds("scheduledToContainersStarted"),
+			ToAllContainersStarted:       seconds("toAllContainersStarted"),
+			ToReady:                      seconds("toReady"),
+			ToSucceeded:                  seconds("toSucceeded"),
+			ToFailed:                     seconds("toFailed"),
+			Runtime:                      seconds("runtime"),
+			TerminationDuration:          seconds("terminationDuration"),
+		},
+	}
+}
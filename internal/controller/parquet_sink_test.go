@@ -0,0 +1,107 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/parquet-go/parquet-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/utils/ptr"
+)
+
+// readParquetDir returns the rows of every Parquet file in dir, in file
+// name order.
+func readParquetDir(dir string) [][]parquetRow {
+	files, err := filepath.Glob(filepath.Join(dir, "*.parquet"))
+	Expect(err).NotTo(HaveOccurred())
+	var batches [][]parquetRow
+	for _, file := range files {
+		rows, err := parquet.ReadFile[parquetRow](file)
+		Expect(err).NotTo(HaveOccurred())
+		batches = append(batches, rows)
+	}
+	return batches
+}
+
+var _ = Describe("ParquetSink", func() {
+	It("should write typed rows on Close", func() {
+		sink := &ParquetSink{Dir: GinkgoT().TempDir()}
+		pod := newRunningPod("parquet")
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{sink}, ClusterName: "prod"}, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(readParquetDir(sink.Dir)).To(BeEmpty(), "rows are only buffered")
+
+		Expect(sink.Close(context.Background())).To(Succeed())
+		batches := readParquetDir(sink.Dir)
+		Expect(batches).To(HaveLen(1))
+		Expect(batches[0]).To(HaveLen(1))
+		row := batches[0][0]
+		Expect(row.Pod).To(Equal("parquet"))
+		Expect(row.Namespace).To(Equal("default"))
+		Expect(row.Node).To(Equal("fake-node"))
+		Expect(row.Cluster).To(Equal("prod"))
+		Expect(row.Phase).To(Equal("Running"))
+		Expect(row.Timestamps.Created).To(Equal(ptr.To(pod.CreationTimestamp.UnixMilli())))
+		Expect(row.Timestamps.Ready).To(Equal(ptr.To(getConditionTime(*pod, "Ready").UnixMilli())))
+		Expect(row.Timestamps.Succeeded).To(BeNil())
+		Expect(row.Durations.ToScheduled).To(Equal(ptr.To(1.0)))
+		Expect(row.Durations.ToReady).To(Equal(ptr.To(3.0)))
+		Expect(row.Durations.ToFailed).To(BeNil())
+	})
+
+	It("should start a new file once a batch is full", func() {
+		sink := &ParquetSink{Dir: GinkgoT().TempDir(), FlushInterval: time.Hour, MaxRows: 2}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() { _ = sink.Start(ctx) }()
+
+		Expect(sink.Write(ctx, storedPod("a", "Running", "1s"))).To(Succeed())
+		Consistently(func() [][]parquetRow { return readParquetDir(sink.Dir) }, 100*time.Millisecond).Should(BeEmpty())
+		Expect(sink.Write(ctx, storedPod("b", "Running", "1.5s"))).To(Succeed())
+		Eventually(func() [][]parquetRow { return readParquetDir(sink.Dir) }).Should(HaveLen(1))
+
+		Expect(sink.Write(ctx, storedPod("c", "Running", "2s"))).To(Succeed())
+		Expect(sink.Close(ctx)).To(Succeed())
+		batches := readParquetDir(sink.Dir)
+		Expect(batches).To(HaveLen(2))
+		Expect(batches[0][1].Durations.ToReady).To(Equal(ptr.To(1.5)))
+		Expect(batches[1][0].Pod).To(Equal("c"))
+	})
+
+	It("should count failed writes and retry them with the next batch", func() {
+		dir := filepath.Join(GinkgoT().TempDir(), "missing")
+		sink := &ParquetSink{Dir: dir}
+		before := testutil.ToFloat64(sinkErrorsTotal.WithLabelValues("parquet"))
+
+		Expect(sink.Write(context.Background(), storedPod("a", "Running", "1s"))).To(Succeed())
+		Expect(sink.Close(context.Background())).NotTo(Succeed())
+		Expect(testutil.ToFloat64(sinkErrorsTotal.WithLabelValues("parquet")) - before).To(BeNumerically("==", 1))
+
+		Expect(os.Mkdir(dir, 0755)).To(Succeed())
+		Expect(sink.Write(context.Background(), storedPod("b", "Running", "1s"))).To(Succeed())
+		Expect(sink.Close(context.Background())).To(Succeed())
+		batches := readParquetDir(dir)
+		Expect(batches).To(HaveLen(1))
+		Expect(batches[0]).To(HaveLen(2))
+	})
+})
@@ -18,26 +18,310 @@ package controller
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"hash/fnv"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"golang.org/x/time/rate"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
-var PodStartupLogPath = "/data/pod_startup_times.json"
+var PodStartupLogPath = filepath.Join(DefaultDataDir, "pod_startup_times.json")
+
+// DefaultMaxBackoff is the longest a failing pod waits between retries.
+const DefaultMaxBackoff = 5 * time.Minute
+
+// DefaultClientTimeout bounds each API call made while reconciling.
+const DefaultClientTimeout = 10 * time.Second
+
+// DefaultMaxWait is how long RecordOnlyWhenComplete holds back a pod.
+const DefaultMaxWait = 10 * time.Minute
+
+// completeWaitBaseDelay is the first delay before checking again whether a
+// pod held back by RecordOnlyWhenComplete is complete. Each further check
+// doubles it.
+const completeWaitBaseDelay = time.Second
+
+// A Running pod whose container statuses haven't been reported is requeued
+// this many times, containerStatusLagDelay apart, before it is recorded
+// without them.
+const (
+	containerStatusLagRetries = 5
+	containerStatusLagDelay   = 2 * time.Second
+)
+
+// Completeness is the lifecycle state a pod must have reached before its
+// record is persisted.
+type Completeness string
+
+const (
+	// CompletenessScheduled requires the pod to be assigned to a node.
+	CompletenessScheduled Completeness = "Scheduled"
+	// CompletenessInitialized additionally requires the Initialized condition.
+	CompletenessInitialized Completeness = "Initialized"
+	// CompletenessReady additionally requires Running pods to be Ready.
+	CompletenessReady Completeness = "Ready"
+)
+
+// Record is a single structured pod lifecycle measurement as persisted and
+// published by the reconciler.
+type Record map[string]interface{}
+
+// RecordSchemaVersion is written to every record as schemaVersion so
+// consumers can tell record shapes apart. Bump it whenever the shape of a
+// record changes.
+const RecordSchemaVersion = "v1"
 
 // PodStartupReconciler reconciles a PodStartup object
 type PodStartupReconciler struct {
 	client.Client
-	Scheme   *runtime.Scheme
-	FileLock sync.Mutex
+	Scheme *runtime.Scheme
+
+	// Sinks receive every persisted record. When empty, records go to a
+	// FileSink at PodStartupLogPath.
+	Sinks []Sink
+
+	// DryRun makes every sink log what it would write instead of writing
+	// it, and skips writing measurements and annotations.
+	DryRun bool
+
+	// FailHard returns sink write errors from Reconcile so the pod is
+	// retried, instead of only logging and counting them.
+	FailHard bool
+
+	// MaxBackoff caps the delay between retries of a failing pod.
+	// Defaults to DefaultMaxBackoff.
+	MaxBackoff time.Duration
+
+	// MaxConcurrentReconciles is how many pods are reconciled in parallel.
+	// Different pods may then be written concurrently, which every sink
+	// supports. Defaults to 1.
+	MaxConcurrentReconciles int
+
+	// ClientTimeout bounds each API call, so a hung API server can't stall
+	// a reconcile worker. Defaults to DefaultClientTimeout.
+	ClientTimeout time.Duration
+
+	// PollInterval requeues Running pods that are not ready yet, so their
+	// later state is captured even if no watch event arrives. Zero relies
+	// on watch events alone.
+	PollInterval time.Duration
+
+	// ExemplarThreshold is the time to ready above which the toReady
+	// histogram observation carries an exemplar naming the pod, and the
+	// pod's records are logged at info level rather than V(1). Defaults to
+	// DefaultExemplarThreshold.
+	ExemplarThreshold time.Duration
+
+	// ToReadyWindow is how far back pod_startup_to_ready_p99_window_seconds
+	// looks. Defaults to DefaultToReadyWindow.
+	ToReadyWindow time.Duration
+
+	// DebounceWindow delays writing a pod's record until it has not been
+	// reconciled for this long, so only the final state of a burst is
+	// written. Debounced write errors are logged and counted but never
+	// retried, even under FailHard. Zero writes every reconcile immediately.
+	DebounceWindow time.Duration
+
+	// EnrichNodeInfo adds the kubelet version, OS image and container
+	// runtime version of the pod's node to every record, and the node's age
+	// when the pod was scheduled as nodeAgeAtSchedule.
+	EnrichNodeInfo bool
+
+	// EnrichJobInfo adds the creation and start times of the pod's Job to
+	// its records, and the jobToPodReady and jobRuntime durations measured
+	// from the Job's creation.
+	EnrichJobInfo bool
+
+	// IncludeRawConditions embeds the pod's conditions in every record under
+	// conditions, for debugging durations that look wrong. It is off by
+	// default as it makes records considerably larger.
+	IncludeRawConditions bool
+
+	// NodeInfoTTL is how long fetched node details are reused. Defaults to
+	// DefaultNodeInfoTTL.
+	NodeInfoTTL time.Duration
+
+	// TerminalIgnoreAge stops reconciling Succeeded and Failed pods that
+	// finished longer ago than this, once they have been recorded. Zero
+	// reconciles them for as long as they exist.
+	TerminalIgnoreAge time.Duration
+
+	// TerminalOnly records pods only once they have Succeeded or Failed,
+	// for clusters where only the final timings of batch pods matter.
+	TerminalOnly bool
+
+	// Phases, when set, records pods only while they are in one of these
+	// phases, such as to leave out Pending churn. Pods in other phases are
+	// still tracked, so their timestamps appear once they are recorded.
+	Phases []corev1.PodPhase
+
+	// RecordOnlyWhenComplete holds a pod back until it is Ready or has
+	// finished, requeueing it with a growing delay. A pod still incomplete
+	// MaxWait after it was first observed is recorded as it is and marked
+	// incomplete, so stuck pods are not lost.
+	RecordOnlyWhenComplete bool
+
+	// MaxWait bounds how long RecordOnlyWhenComplete holds a pod back.
+	// Defaults to DefaultMaxWait.
+	MaxWait time.Duration
+
+	// PerNamespaceRateLimit caps the records written per second for each
+	// namespace, allowing bursts of up to a second's worth. Records over the
+	// limit are requeued until their namespace has capacity again, so a
+	// large rollout doesn't hold back other namespaces. Zero is unlimited.
+	PerNamespaceRateLimit float64
+
+	// BaselineContainer, when set, measures durations from when the
+	// container of this name started rather than from pod creation, for pods
+	// whose real start is a particular container. Pods without it, or where
+	// it hasn't started yet, are measured from creation. BaselineAnnotation
+	// takes precedence.
+	BaselineContainer string
+
+	// CustomDurations adds a duration between two of the record's
+	// timestamps for each spec, alongside the built-in ones.
+	CustomDurations []DurationSpec
+
+	// SampleRate is the fraction of pods recorded, between 0 and 1. Pods
+	// are chosen by a hash of their UID, so a pod is either recorded on
+	// every reconcile or never. The pending gauge still counts every pod.
+	// Zero, like 1, records every pod.
+	SampleRate float64
+
+	// CaptureLabels lists the pod labels copied into each record's labels
+	// map, for slicing downstream. Only these are copied, to keep records
+	// small, and labels the pod lacks are left out.
+	CaptureLabels []string
+
+	// IDFunc returns the identity records of a pod are keyed and deduplicated
+	// on, such as namespace/name/revision, for callers that need something
+	// other than the pod UID. When set, records carry it as id. Nil uses the
+	// UID.
+	IDFunc func(pod corev1.Pod) string
+
+	// OwnerKinds, when set, restricts recording to pods whose top-level
+	// controller is one of these kinds, e.g. Deployment or StatefulSet.
+	// Pods without a controller are skipped. Empty records every pod.
+	OwnerKinds []string
+
+	// Backfill records every existing pod once when the manager starts, so
+	// pods that produce no further events are not missed.
+	Backfill bool
+
+	// ClusterName, when set, is added to every record as cluster, so records
+	// from several clusters can be told apart once aggregated.
+	ClusterName string
+
+	// Namespace, when set, restricts the reconciler to pods in this
+	// namespace. The manager cache should be restricted to match, see
+	// Config.CacheOptions.
+	Namespace string
+
+	// Rollup, when set, condenses the records of a file sink into periodic
+	// summaries for as long as the manager runs.
+	Rollup *Rollup
+
+	// UnhealthyAfter is how many consecutive failed record writes make
+	// SinkHealthCheck fail. Defaults to DefaultUnhealthyAfter.
+	UnhealthyAfter int
+
+	// MinCompleteness is the least complete state a pod must reach before it
+	// is recorded. Failed pods are always recorded. Defaults to Scheduled.
+	MinCompleteness Completeness
+
+	// Hub receives every record produced by Reconcile. It is created by
+	// SetupWithManager when streaming is enabled and left nil otherwise.
+	Hub *Hub
+
+	// GRPCBindAddress is the address the lifecycle gRPC server listens on.
+	// Empty or "0" disables the server.
+	GRPCBindAddress string
+
+	// Store keeps the latest record of every pod for the query server. It
+	// is created by SetupWithManager when the query server is enabled and
+	// left nil otherwise.
+	Store *RecordStore
+
+	// QueryBindAddress is the address the HTTP query server listens on.
+	// Empty or "0" disables the server.
+	QueryBindAddress string
+
+	// QuerySocketPath, when set, also serves the read-only query API on a
+	// Unix socket at this path, which is created with QuerySocketMode and
+	// removed on shutdown.
+	QuerySocketPath string
+
+	// ServerTLS, when enabled, serves the TCP query server and the gRPC
+	// server over TLS, optionally requiring client certificates. The query
+	// socket is left as it is, being protected by its file mode.
+	ServerTLS ServerTLS
+
+	// ResetToken enables POST /reset on the query server, which calls
+	// ClearStore, for requests bearing it as their bearer token. Empty
+	// leaves the endpoint disabled.
+	ResetToken string
+
+	// RecordMeasurements mirrors every record into a PodStartupMeasurement
+	// resource named after the pod, in addition to the log file.
+	RecordMeasurements bool
+
+	// AnnotatePods writes the measured toReady duration back onto ready pods
+	// as the ToReadyAnnotation.
+	AnnotatePods bool
+
+	// IgnoreAnnotation names the annotation that, set to "true", makes the
+	// reconciler skip a pod entirely regardless of the other filters, such
+	// as for debug pods. Defaults to DefaultIgnoreAnnotation.
+	IgnoreAnnotation string
+
+	pods     podTracker
+	debounce debouncer
+	limits   namespaceLimiter
+	nodes    nodeInfoCache
+
+	// terminal skips resyncs of finished pods already processed.
+	terminal terminalCache
+
+	// toReadyP99 holds the recent times to ready behind toReadyWindowP99.
+	toReadyP99 slidingQuantile
+
+	getRetries retryBackoff
+
+	sinksOnce sync.Once
+	sinks     []Sink
+
+	lastWrite atomic.Pointer[WriteStatus]
+	totals    writeTotals
+
+	eventsMu    sync.Mutex
+	unsubscribe []func()
+
+	// now defaults to time.Now.
+	now func() time.Time
 }
 
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;patch;delete
@@ -55,113 +339,970 @@ type PodStartupReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.1/pkg/reconcile
 func (r *PodStartupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := logf.FromContext(ctx)
+	// Measured on the monotonic clock, so clock steps don't skew it
+	start := time.Now()
+	defer func() { reconcileDuration.Observe(time.Since(start).Seconds()) }()
+
+	if r.Namespace != "" && req.Namespace != r.Namespace {
+		return ctrl.Result{}, nil
+	}
 
 	var pod corev1.Pod
-	if err := r.Get(ctx, req.NamespacedName, &pod); err != nil {
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+	getCtx, cancel := r.clientContext(ctx)
+	defer cancel()
+	if err := r.Get(getCtx, req.NamespacedName, &pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.getRetries.reset(req.NamespacedName)
+			if state := r.pods.forget(req.NamespacedName); state != nil && !state.deletionRequested.IsZero() {
+				r.recordTermination(ctx, req.NamespacedName, state, r.clock())
+			}
+			return ctrl.Result{}, nil
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			// A Get that outlived ClientTimeout is returned, so the
+			// workqueue requeues the pod with its backoff and the hung
+			// API server shows up in the reconcile error metrics
+			return ctrl.Result{}, fmt.Errorf("fetching pod: %w", err)
+		}
+		if isTransient(err) {
+			delay := r.getRetries.next(req.NamespacedName, err, r.MaxBackoff)
+			logger.Info("Transient error fetching pod, retrying", "error", err.Error(), "after", delay)
+			return ctrl.Result{RequeueAfter: delay}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	r.getRetries.reset(req.NamespacedName)
+	if isTerminal(pod) && r.terminal.seen(pod.UID, pod.ResourceVersion) {
+		return ctrl.Result{}, nil
+	}
+
+	if r.ignored(pod) {
+		return ctrl.Result{}, nil
+	}
+	if allowed, err := r.ownerAllowed(ctx, pod); err != nil || !allowed {
+		return ctrl.Result{}, err
+	}
+	id := r.recordID(pod)
+	firstObserved := r.pods.observe(req.NamespacedName, id, r.clock())
+	// A pod the filters drop must not produce a termination record either
+	recordable := r.recordable(pod, id)
+	deletionRequested := time.Time{}
+	if recordable {
+		deletionRequested = getDeletionRequestedTime(pod)
+	}
+	r.pods.trackDeletion(req.NamespacedName, id, deletionRequested, pod.Spec.NodeName)
+
+	// Keep the pending gauge current even for pods that are not recorded yet
+	pendingReason := ""
+	if pod.Status.Phase == corev1.PodPending {
+		pendingReason = getPendingReason(pod)
 	}
+	r.pods.trackPending(req.NamespacedName, id, pendingReason)
+	containersReady := r.pods.trackContainersReady(req.NamespacedName, id, getContainersReady(pod), r.clock())
+	r.pods.trackReadiness(req.NamespacedName, id, !getConditionTime(pod, corev1.PodReady).IsZero(), pod.Status.Phase)
+	wasGated := r.pods.trackGating(req.NamespacedName, id, schedulingGated(pod))
 
-	// We only care about Pods that are scheduled (assigned to a node)
-	if pod.Spec.NodeName == "" {
+	if !sampled(id, r.SampleRate) {
+		return ctrl.Result{}, nil
+	}
+	// Skip transient pods that never got far enough to be worth recording
+	if !meetsCompleteness(pod, r.MinCompleteness) {
+		return r.pollResult(pod), nil
+	}
+	if !recordable {
 		return ctrl.Result{}, nil
 	}
+	incomplete := false
+	if r.RecordOnlyWhenComplete && !meetsCompleteness(pod, CompletenessReady) {
+		maxWait := r.MaxWait
+		if maxWait <= 0 {
+			maxWait = DefaultMaxWait
+		}
+		// Both times come from this process's clock, so their monotonic
+		// readings keep the wait right across clock steps
+		if waited := r.clock().Sub(firstObserved); waited < maxWait {
+			delay := r.pods.completeWait(req.NamespacedName, id, completeWaitBaseDelay)
+			return ctrl.Result{RequeueAfter: min(delay, maxWait-waited)}, nil
+		}
+		incomplete = true
+	}
+
+	// The kubelet can report the pod Running before its containers, which
+	// would leave the container timestamps out of the record
+	if containerStatusesLag(pod) && r.pods.waitForContainerStatuses(req.NamespacedName, id, containerStatusLagRetries) {
+		return ctrl.Result{RequeueAfter: containerStatusLagDelay}, nil
+	}
+
+	// Skip states that were already recorded, e.g. by the backfill
+	if r.pods.alreadyRecorded(req.NamespacedName, id, pod.ResourceVersion) {
+		if isTerminal(pod) {
+			r.terminal.add(pod.UID, pod.ResourceVersion)
+		}
+		return r.pollResult(pod), nil
+	}
+	if r.PerNamespaceRateLimit > 0 {
+		if ok, delay := r.limits.allow(pod.Namespace, r.PerNamespaceRateLimit, r.clock()); !ok {
+			recordsThrottledTotal.WithLabelValues(pod.Namespace).Inc()
+			return ctrl.Result{RequeueAfter: delay}, nil
+		}
+	}
 
 	// Collect important timestamps
-	created := pod.CreationTimestamp.Time
-	pending := timeZeroSafe(created)
-	initialized := getConditionTime(pod, corev1.PodInitialized)
-	scheduled := getConditionTime(pod, corev1.PodScheduled)
-	containersStarted := getAllContainersStartedTime(pod)
-	running := getPhaseTime(pod, corev1.PodRunning)
-	ready := getConditionTime(pod, corev1.PodReady)
-	succeeded := getPhaseTime(pod, corev1.PodSucceeded)
-	failed := getPhaseTime(pod, corev1.PodFailed)
+	times := collectTimestamps(timestampInputs{
+		pod:      pod,
+		now:      r.clock(),
+		baseline: measurementBaseline(ctx, pod, r.BaselineContainer),
+		// Pods that existed before the controller started were not
+		// observed at their own pace, which this makes visible
+		firstObserved: firstObserved,
+	})
+	created, baseline := times["created"], times["baseline"]
+	scheduled, initialized := times["scheduled"], times["initialized"]
+	containersStarted, ready := times["containersStarted"], times["ready"]
+	succeeded, failed := times["succeeded"], times["failed"]
+	sidecarsStarted := getSidecarsStartedTimes(pod)
+	gatesPassed := getReadinessGateTimes(pod)
+	ephemeralStarted := getEphemeralContainersStartedTimes(pod)
+
+	timestamps := map[string]string{}
+	for name, t := range times {
+		timestamps[name] = fmtTime(t)
+	}
+	if baseline.Equal(created) {
+		delete(timestamps, "baseline")
+	}
+	// Gates are gone from the spec once cleared, so only pods seen gated
+	// are known to have been. Clearance is approximated by scheduling, as
+	// the scheduler only considers the pod after its gates are removed.
+	gatesCleared := time.Time{}
+	if wasGated && !scheduled.IsZero() {
+		gatesCleared = scheduled
+		timestamps["schedulingGatesClearedAt"] = fmtTime(gatesCleared)
+	}
 
 	// Build a structured record
-	data := map[string]interface{}{
+	data := Record{
 		"pod":       pod.Name,
 		"namespace": pod.Namespace,
+		"uid":       string(pod.UID),
 		"node":      pod.Spec.NodeName,
 		"phase":     string(pod.Status.Phase),
-		"timestamps": map[string]string{
-			"created":           fmtTime(created),
-			"pending":           fmtTime(pending),
-			"initialized":       fmtTime(initialized),
-			"scheduled":         fmtTime(scheduled),
-			"containersStarted": fmtTime(containersStarted),
-			"running":           fmtTime(running),
-			"ready":             fmtTime(ready),
-			"succeeded":         fmtTime(succeeded),
-			"failed":            fmtTime(failed),
-		},
+		// Scheduling constraints, for slicing scheduling latency downstream
+		"hasNodeSelector": len(pod.Spec.NodeSelector) > 0,
+		"hasAffinity":     hasAffinity(pod),
+		"hasTolerations":  len(pod.Spec.Tolerations) > 0,
+		"hasPVC":          hasPVC(pod),
+		// Low-priority pods wait longer and may only fit after preemption
+		"priorityClassName": pod.Spec.PriorityClassName,
+		"preempted":         preempted(pod),
+		"timestamps":        timestamps,
+	}
+	if pod.Spec.Priority != nil {
+		data["priority"] = int64(*pod.Spec.Priority)
+	}
+	// The pod's direct controller, such as its ReplicaSet
+	if ref := metav1.GetControllerOf(&pod); ref != nil {
+		data["ownerKind"] = ref.Kind
+		data["ownerName"] = ref.Name
+	}
+	// The Deployment revision, for comparing startup times across rollouts
+	data["podTemplateHash"] = r.podTemplateHash(ctx, pod)
+	if labels := capturedLabels(pod, r.CaptureLabels); len(labels) > 0 {
+		data["labels"] = labels
+	}
+	r.stampRecord(data)
+	if r.IDFunc != nil {
+		data["id"] = string(id)
+	}
+	cpu, memory := totalRequests(pod)
+	data["qosClass"] = string(pod.Status.QOSClass)
+	data["cpuRequestMillicores"] = cpu.MilliValue()
+	data["memoryRequestBytes"] = memory.Value()
+	if pendingReason != "" {
+		data["pendingReason"] = pendingReason
+	}
+	if incomplete {
+		data["incomplete"] = true
+	}
+	if r.EnrichNodeInfo {
+		r.enrichWithNode(ctx, pod, data)
+	}
+	if r.IncludeRawConditions {
+		data["conditions"] = rawConditions(pod)
+	}
+	if len(sidecarsStarted) > 0 {
+		started := map[string]string{}
+		for name, t := range sidecarsStarted {
+			started[name] = fmtTime(t)
+		}
+		data["sidecarsStarted"] = started
+	}
+	// Debug containers attached with kubectl debug can skew timings
+	data["hasEphemeralContainers"] = len(pod.Status.EphemeralContainerStatuses) > 0
+	if len(ephemeralStarted) > 0 {
+		started := map[string]string{}
+		for name, t := range ephemeralStarted {
+			started[name] = fmtTime(t)
+		}
+		data["ephemeralContainersStarted"] = started
+	}
+	if containers := getContainerTimes(pod, containersReady, ready); len(containers) > 0 {
+		data["containers"] = containers
+	}
+	if inits := getInitContainerTimes(pod); len(inits) > 0 {
+		data["initContainers"] = inits
+	}
+	if reasons := getTerminationReasons(pod); len(reasons) > 0 {
+		data["terminationReasons"] = reasons
+	}
+	if len(gatesPassed) > 0 {
+		// Time from the containers starting to each gate passing, which
+		// points at slow external dependencies
+		passed := map[string]string{}
+		sinceStarted := map[string]string{}
+		for gate, t := range gatesPassed {
+			passed[gate] = fmtTime(t)
+			if !containersStarted.IsZero() {
+				sinceStarted[gate] = fmt.Sprintf("%v", max(t.Sub(containersStarted), 0))
+			}
+		}
+		data["readinessGates"] = passed
+		if len(sinceStarted) > 0 {
+			data["readinessGateDurations"] = sinceStarted
+		}
+	}
+
+	if probed := getStartupProbeDurations(pod, ready); len(probed) > 0 {
+		startupProbe := map[string]string{}
+		for name, d := range probed {
+			startupProbe[name] = fmt.Sprintf("%v", d)
+		}
+		data["startupProbeDuration"] = startupProbe
 	}
 
-	// Calculate durations between states
+	// Calculate durations between states. The timestamps are wall-clock
+	// times set by the API server, the scheduler and the kubelet, each on
+	// its own host, so a clock step or skew between them can put an end
+	// before its start. Such durations are clamped to zero rather than
+	// recorded negative.
 	durations := map[string]string{}
-	if !scheduled.IsZero() {
-		durations["toScheduled"] = fmt.Sprintf("%v", scheduled.Sub(created))
+	// Without a creation time there is nothing to measure from, and
+	// substituting one would make every duration from the baseline garbage
+	baselineMissing := baseline.IsZero()
+	if baselineMissing {
+		data["baselineMissing"] = true
+	}
+	for _, spec := range builtinDurations {
+		if d, ok := spec.between(times); ok {
+			durations[spec.Name] = fmt.Sprintf("%v", d)
+		}
 	}
-	if !initialized.IsZero() {
-		durations["toInitialized"] = fmt.Sprintf("%v", initialized.Sub(created))
+	if !gatesCleared.IsZero() && !created.IsZero() {
+		durations[gatedDurationName] = fmt.Sprintf("%v", max(gatesCleared.Sub(created), 0))
 	}
-	if !containersStarted.IsZero() {
-		durations["toContainersStarted"] = fmt.Sprintf("%v", containersStarted.Sub(created))
+	if hasPVC(pod) {
+		if latency, ok := volumeBindLatency(pod, scheduled, initialized); ok {
+			durations[volumeBindLatencyName] = fmt.Sprintf("%v", latency)
+		}
 	}
-	if !ready.IsZero() {
-		durations["toReady"] = fmt.Sprintf("%v", ready.Sub(created))
+	if !scheduled.IsZero() && !containersStarted.IsZero() && r.pods.firstContainersStarted(req.NamespacedName, id) {
+		scheduledToContainersStarted := max(containersStarted.Sub(scheduled), 0)
+		scheduledToContainersStartedHistogram.WithLabelValues(pod.Spec.NodeName).Observe(scheduledToContainersStarted.Seconds())
 	}
-	if !succeeded.IsZero() {
-		durations["toSucceeded"] = fmt.Sprintf("%v", succeeded.Sub(created))
+	if runtime := getRuntime(pod); runtime > 0 {
+		durations[runtimeName] = fmt.Sprintf("%v", runtime)
 	}
-	if !failed.IsZero() {
-		durations["toFailed"] = fmt.Sprintf("%v", failed.Sub(created))
+	if r.EnrichJobInfo {
+		finished := succeeded
+		if finished.IsZero() {
+			finished = failed
+		}
+		r.enrichWithJob(ctx, pod, ready, finished, timestamps, durations)
+	}
+	for _, spec := range r.CustomDurations {
+		if d, ok := spec.between(times); ok {
+			durations[spec.Name] = fmt.Sprintf("%v", d)
+		}
 	}
 	data["durations"] = durations
 
+	if !ready.IsZero() && !baselineMissing && r.pods.firstReady(req.NamespacedName, id) {
+		toReady := max(ready.Sub(baseline), 0)
+		observeToReady(pod, toReady, r.ExemplarThreshold)
+		r.observeToReadyWindow(pod.Namespace, toReady)
+	}
+
+	// Every reconcile of a starting pod produces a record, so only the
+	// final state and slow starts are logged by default
+	if eventLogger := logger.V(lifecycleLogLevel(pod, durations, r.ExemplarThreshold)); eventLogger.Enabled() {
+		jsonData, _ := json.MarshalIndent(data, "", "  ")
+		eventLogger.Info("Pod lifecycle event", "json", string(jsonData))
+	}
+
+	var sinkErr error
+	if r.DebounceWindow > 0 {
+		// Only the last state of a burst is written, after the window
+		// passes without another reconcile of the pod. It only counts as
+		// recorded once written, so a failed write is retried.
+		writeCtx := context.WithoutCancel(ctx)
+		r.debounce.schedule(req.NamespacedName, r.DebounceWindow, func() {
+			if r.writeSinks(writeCtx, data) == nil {
+				r.markWritten(req.NamespacedName, id, pod)
+			}
+		})
+	} else {
+		sinkErr = r.writeSinks(ctx, data)
+	}
+
+	if r.RecordMeasurements && !r.DryRun {
+		if err := r.persistMeasurement(ctx, &pod, data); err != nil {
+			if apierrors.IsConflict(err) {
+				return ctrl.Result{RequeueAfter: conflictRequeueDelay}, nil
+			}
+			logger.Error(err, "Failed to record measurement")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if r.AnnotatePods && !r.DryRun {
+		if toReady, ok := durations["toReady"]; ok {
+			if err := r.annotateToReady(ctx, &pod, toReady); err != nil {
+				if apierrors.IsConflict(err) {
+					return ctrl.Result{RequeueAfter: conflictRequeueDelay}, nil
+				}
+				logger.Error(err, "Failed to annotate pod")
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	if r.Hub != nil {
+		r.Hub.Publish(data)
+	}
+	if r.Store != nil {
+		r.Store.Put(data)
+	}
+
+	if sinkErr != nil {
+		if r.FailHard {
+			return ctrl.Result{}, sinkErr
+		}
+		return r.pollResult(pod), nil
+	}
+	if r.DebounceWindow <= 0 {
+		r.markWritten(req.NamespacedName, id, pod)
+	}
+	return r.pollResult(pod), nil
+}
+
+// markWritten remembers that the pod's current state reached the sinks, so
+// later reconciles of the same resourceVersion skip it.
+func (r *PodStartupReconciler) markWritten(key types.NamespacedName, id types.UID, pod corev1.Pod) {
+	r.pods.markRecorded(key, id, pod.ResourceVersion)
+	if isTerminal(pod) {
+		r.terminal.add(pod.UID, pod.ResourceVersion)
+	}
+}
+
+// recordable reports whether sampling and the phase filters let the pod's
+// records through. Completeness is checked separately, since a pod that is
+// not complete yet may still be recorded later.
+func (r *PodStartupReconciler) recordable(pod corev1.Pod, id types.UID) bool {
+	if !sampled(id, r.SampleRate) {
+		return false
+	}
+	if r.TerminalOnly && !isTerminal(pod) {
+		return false
+	}
+	return len(r.Phases) == 0 || slices.Contains(r.Phases, pod.Status.Phase)
+}
+
+// recordTermination writes a record of how long a deleted pod took to shut
+// down, from the deletion request seen on its last observed version until
+// removed, when its removal was noticed. Watch and queue delays make the
+// duration an upper bound rather than an exact measurement. The deletion
+// request is timed by the API server's clock and the removal by this
+// process's, so the duration is clamped to zero should they disagree.
+func (r *PodStartupReconciler) recordTermination(ctx context.Context, key types.NamespacedName, state *podState, removed time.Time) {
+	data := Record{
+		"pod":       key.Name,
+		"namespace": key.Namespace,
+		"node":      state.node,
+		"timestamps": map[string]string{
+			"deletionRequested": fmtTime(state.deletionRequested),
+			"removed":           fmtTime(removed),
+		},
+		"durations": map[string]string{
+			terminationDurationName: fmt.Sprintf("%v", max(removed.Sub(state.deletionRequested), 0)),
+		},
+	}
+	// The tracker knows the pod by its record identity, which is the UID
+	// unless IDFunc is set
+	if r.IDFunc != nil {
+		data["id"] = string(state.uid)
+	} else {
+		data["uid"] = string(state.uid)
+	}
+	r.stampRecord(data)
+
 	jsonData, _ := json.MarshalIndent(data, "", "  ")
-	logger.Info("Pod lifecycle event", "json", string(jsonData))
+	logf.FromContext(ctx).Info("Pod termination event", "json", string(jsonData))
+
+	// The pod is gone, so a failed write can't be retried
+	_ = r.writeSinks(ctx, data)
+	if r.Hub != nil {
+		r.Hub.Publish(data)
+	}
+}
+
+// stampRecord adds the fields every record carries regardless of the pod.
+func (r *PodStartupReconciler) stampRecord(data Record) {
+	data["schemaVersion"] = RecordSchemaVersion
+	if r.ClusterName != "" {
+		data["cluster"] = r.ClusterName
+	}
+}
+
+// writeSinks writes the record to every active sink, counting and logging
+// failures, and returns the joined errors.
+func (r *PodStartupReconciler) writeSinks(ctx context.Context, data Record) error {
+	logger := logf.FromContext(ctx)
+
+	var sinkErr error
+	var failed []string
+	for _, sink := range r.activeSinks() {
+		if err := sink.Write(ctx, data); err != nil {
+			sinkErrorsTotal.WithLabelValues(sink.Name()).Inc()
+			logger.Error(err, "Failed to write record", "sink", sink.Name())
+			sinkErr = errors.Join(sinkErr, fmt.Errorf("sink %s: %w", sink.Name(), err))
+			failed = append(failed, sink.Name())
+		}
+	}
+	r.recordWrite(sinkErr)
+	r.totals.add(r.clock(), failed)
+	return sinkErr
+}
+
+// pollResult requeues the pod after PollInterval while it is Running but
+// not yet ready. Terminal pods are never polled.
+func (r *PodStartupReconciler) pollResult(pod corev1.Pod) ctrl.Result {
+	if r.PollInterval <= 0 || pod.Status.Phase != corev1.PodRunning {
+		return ctrl.Result{}
+	}
+	if !getConditionTime(pod, corev1.PodReady).IsZero() {
+		return ctrl.Result{}
+	}
+	return ctrl.Result{RequeueAfter: r.PollInterval}
+}
+
+// clock returns the current time.
+func (r *PodStartupReconciler) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
 
-	// --- Persist locally (as JSON array) ---
-	var allData []map[string]interface{}
+// clientContext derives the context for a single API call, bounded by
+// ClientTimeout.
+func (r *PodStartupReconciler) clientContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := r.ClientTimeout
+	if timeout <= 0 {
+		timeout = DefaultClientTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
 
-	// Lock to prevent race conditions
-	r.FileLock.Lock()
-	defer r.FileLock.Unlock() // Ensures the lock is released even if a panic occurs
+// BackfillExisting records every pod that already exists through the same
+// path as Reconcile. Pods that fall short of MinCompleteness are skipped as
+// usual, and states recorded here are not recorded again by later watch
+// events.
+func (r *PodStartupReconciler) BackfillExisting(ctx context.Context) error {
+	logger := logf.FromContext(ctx)
 
-	// If the file already exists and has content, read it
-	if existing, err := os.ReadFile(PodStartupLogPath); err == nil && len(existing) > 0 {
-		if err := json.Unmarshal(existing, &allData); err != nil {
-			// If the file is corrupt, log it and reset
-			logger.Error(err, "Failed to unmarshal existing log file, resetting.")
-			allData = []map[string]interface{}{} // Reset to empty slice
+	var pods corev1.PodList
+	listCtx, cancel := r.clientContext(ctx)
+	defer cancel()
+	if err := r.List(listCtx, &pods, client.InNamespace(r.Namespace)); err != nil {
+		return fmt.Errorf("listing pods: %w", err)
+	}
+
+	var errs error
+	for i := range pods.Items {
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&pods.Items[i])}
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("pod %s: %w", req.NamespacedName, err))
 		}
 	}
+	logger.Info("Backfilled existing pods", "count", len(pods.Items))
+	return errs
+}
+
+// Events returns a channel receiving every record produced from now on, for
+// processes embedding the reconciler. It subscribes to Hub, creating one if
+// needed, so it must be called before the manager starts. The channel buffers
+// DefaultHubBufferSize records; records are dropped for a consumer that falls
+// further behind, to keep Reconcile from blocking. The channel is closed by
+// Close.
+func (r *PodStartupReconciler) Events() <-chan Record {
+	r.eventsMu.Lock()
+	defer r.eventsMu.Unlock()
+
+	if r.Hub == nil {
+		r.Hub = NewHub(DefaultHubBufferSize)
+	}
+	events, unsubscribe := r.Hub.Subscribe()
+	r.unsubscribe = append(r.unsubscribe, unsubscribe)
+	return events
+}
+
+// DefaultCloseTimeout bounds how long shutdown waits for sinks to close.
+const DefaultCloseTimeout = 15 * time.Second
+
+// Close writes any debounced records, closes the channels returned by
+// Events and then closes every sink that implements ClosingSink, returning
+// all errors. It logs how many records were written since startup, when
+// the last one was and the write errors of each sink, as an audit trail
+// across restarts.
+func (r *PodStartupReconciler) Close(ctx context.Context) error {
+	r.debounce.flush()
+
+	r.eventsMu.Lock()
+	for _, unsubscribe := range r.unsubscribe {
+		unsubscribe()
+	}
+	r.unsubscribe = nil
+	r.eventsMu.Unlock()
 
-	// Append this new pod event data
-	allData = append(allData, data)
+	var errs error
+	for _, sink := range r.activeSinks() {
+		if d, ok := sink.(dryRunSink); ok {
+			sink = d.Sink
+		}
+		closer, ok := sink.(ClosingSink)
+		if !ok {
+			continue
+		}
+		if err := closer.Close(ctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("closing sink %s: %w", sink.Name(), err))
+		}
+	}
+	r.totals.logSummary(logf.FromContext(ctx))
+	return errs
+}
+
+// ClearStore drops every record collected so far, from the query store and
+// from each sink implementing ClearingSink. Sinks are left alone in dry runs,
+// as nothing was written to them.
+func (r *PodStartupReconciler) ClearStore(ctx context.Context) error {
+	if r.Store != nil {
+		r.Store.Clear()
+	}
+
+	var errs error
+	for _, sink := range r.activeSinks() {
+		clearer, ok := sink.(ClearingSink)
+		if !ok {
+			continue
+		}
+		if err := clearer.Clear(ctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("clearing sink %s: %w", sink.Name(), err))
+		}
+	}
+	if errs == nil {
+		logf.FromContext(ctx).Info("Cleared all records")
+	}
+	return errs
+}
+
+// fileSink returns the first FileSink the records are written to, or nil
+// when there is none.
+func (r *PodStartupReconciler) fileSink() *FileSink {
+	for _, sink := range r.activeSinks() {
+		if dryRun, ok := sink.(dryRunSink); ok {
+			sink = dryRun.Sink
+		}
+		if file, ok := sink.(*FileSink); ok {
+			return file
+		}
+	}
+	return nil
+}
+
+// boltSink returns the first BoltSink the records are written to, or nil
+// when there is none.
+func (r *PodStartupReconciler) boltSink() *BoltSink {
+	for _, sink := range r.activeSinks() {
+		if dryRun, ok := sink.(dryRunSink); ok {
+			sink = dryRun.Sink
+		}
+		if bolt, ok := sink.(*BoltSink); ok {
+			return bolt
+		}
+	}
+	return nil
+}
+
+// loadStore fills the query store with the records kept by any BoltSink,
+// so the query API picks up where it left off after a restart.
+func (r *PodStartupReconciler) loadStore(ctx context.Context) error {
+	for _, sink := range r.Sinks {
+		bolt, ok := sink.(*BoltSink)
+		if !ok {
+			continue
+		}
+		records, err := bolt.Query(ctx, BoltQuery{})
+		if err != nil {
+			return fmt.Errorf("loading records from sink %s: %w", sink.Name(), err)
+		}
+		for _, rec := range records {
+			r.Store.Put(rec)
+		}
+	}
+	return nil
+}
 
-	// Re-marshal everything as a JSON array
-	jsonData, _ = json.MarshalIndent(allData, "", "  ")
+// closeOnShutdown waits for ctx to be cancelled and then closes the
+// reconciler, allowing up to DefaultCloseTimeout.
+func (r *PodStartupReconciler) closeOnShutdown(ctx context.Context) error {
+	<-ctx.Done()
 
-	// Write back to the file (overwrites but keeps all previous entries)
-	if err := os.WriteFile(PodStartupLogPath, jsonData, 0644); err != nil {
-		logger.Error(err, "Failed to write updated log file")
+	closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), DefaultCloseTimeout)
+	defer cancel()
+	if err := r.Close(closeCtx); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to close sinks on shutdown")
 	}
+	return nil
+}
 
-	return ctrl.Result{}, nil
+// activeSinks returns the sinks records are written to, falling back to the
+// default log file and wrapping them for dry runs.
+func (r *PodStartupReconciler) activeSinks() []Sink {
+	r.sinksOnce.Do(func() {
+		sinks := r.Sinks
+		if len(sinks) == 0 {
+			sinks = []Sink{&FileSink{Path: PodStartupLogPath}}
+		}
+		if r.DryRun {
+			wrapped := make([]Sink, 0, len(sinks))
+			for _, sink := range sinks {
+				wrapped = append(wrapped, dryRunSink{Sink: sink})
+			}
+			sinks = wrapped
+		}
+		r.sinks = sinks
+	})
+	return r.sinks
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *PodStartupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return r.SetupWithManagerFor(mgr, &corev1.Pod{})
+}
+
+// SetupWithManagerFor is SetupWithManager watching the type of obj instead
+// of Pods, for pods surfaced through another resource, such as a custom
+// resource kept by a virtual kubelet, or for tests and forks to swap the
+// watched type. An event for an object reconciles the Pod of the same
+// namespace and name. The type must be registered in the manager's scheme.
+func (r *PodStartupReconciler) SetupWithManagerFor(mgr ctrl.Manager, obj client.Object) error {
+	name := "podstartup"
+	if _, isPod := obj.(*corev1.Pod); !isPod {
+		gvk, err := apiutil.GVKForObject(obj, mgr.GetScheme())
+		if err != nil {
+			return err
+		}
+		// Keep the name unique should Pods be watched alongside
+		name += "-" + strings.ToLower(gvk.Kind)
+	}
+
+	if err := mgr.AddReadyzCheck("sinks", r.SinkHealthCheck); err != nil {
+		return err
+	}
+
+	serveGRPC := r.GRPCBindAddress != "" && r.GRPCBindAddress != "0"
+	serveTCP := r.QueryBindAddress != "" && r.QueryBindAddress != "0"
+	var serverTLS *tls.Config
+	if r.ServerTLS.Enabled() && (serveGRPC || serveTCP) {
+		config, watcher, err := newServerTLSConfig(r.ServerTLS)
+		if err != nil {
+			return err
+		}
+		// Reloads rotated certificates for as long as the servers run
+		if err := mgr.Add(watcher); err != nil {
+			return err
+		}
+		serverTLS = config
+	}
+
+	if serveGRPC {
+		if r.Hub == nil {
+			r.Hub = NewHub(DefaultHubBufferSize)
+		}
+		if err := mgr.Add(newGRPCServerRunnable(r.GRPCBindAddress, r.Hub, serverTLS)); err != nil {
+			return err
+		}
+	}
+
+	if (serveTCP || r.QuerySocketPath != "") && r.Store == nil {
+		r.Store = NewRecordStore()
+	}
+	if r.Store != nil {
+		metricsStore.Store(r.Store)
+		if err := r.loadStore(context.Background()); err != nil {
+			return err
+		}
+	}
+	if serveTCP {
+		server := newQueryServerRunnable(r.QueryBindAddress, r.Store, r.ResetToken, r.ClearStore)
+		server.tlsConfig = serverTLS
+		if file := r.fileSink(); file != nil {
+			server.serveDownload(file)
+		}
+		if bolt := r.boltSink(); bolt != nil {
+			server.serveBolt(r.Store, bolt)
+		}
+		if err := mgr.Add(server); err != nil {
+			return err
+		}
+	}
+	if r.QuerySocketPath != "" {
+		server := newQuerySocketRunnable(r.QuerySocketPath, r.Store)
+		if file := r.fileSink(); file != nil {
+			server.serveDownload(file)
+		}
+		if bolt := r.boltSink(); bolt != nil {
+			server.serveBolt(r.Store, bolt)
+		}
+		if err := mgr.Add(server); err != nil {
+			return err
+		}
+	}
+
+	// Sinks with background work, such as buffered producers, run alongside
+	// the manager so they are flushed on shutdown
+	for _, sink := range r.activeSinks() {
+		if runnable, ok := sink.(manager.Runnable); ok {
+			if err := mgr.Add(runnable); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Convert log files left as JSON arrays by earlier runs up front rather
+	// than on the first write to each
+	for _, sink := range r.activeSinks() {
+		if fileSink, ok := sink.(*FileSink); ok && fileSink.JSONLines {
+			if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+				if err := fileSink.Migrate(ctx); err != nil {
+					logf.FromContext(ctx).Error(err, "Failed to migrate log files to JSON Lines")
+				}
+				return nil
+			})); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Flush and close everything when the manager stops
+	if err := mgr.Add(manager.RunnableFunc(r.closeOnShutdown)); err != nil {
+		return err
+	}
+
+	if r.Backfill {
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			if err := r.BackfillExisting(ctx); err != nil {
+				logf.FromContext(ctx).Error(err, "Backfill of existing pods failed")
+			}
+			return nil
+		})); err != nil {
+			return err
+		}
+	}
+
+	if r.Rollup != nil {
+		if err := mgr.Add(r.Rollup); err != nil {
+			return err
+		}
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(r.controllerOptions()).
 		// Uncomment the following line adding a pointer to an instance of the controlled resource as an argument
-		For(&corev1.Pod{}). // watch Pods directly
-		Named("podstartup").
+		For(obj, builder.WithPredicates(r.ignoreAgedTerminal(), ignoreTimingNoise())). // watch Pods, or the injected type
+		Named(name).
 		Complete(r)
 }
 
 // --- Helper functions ---
 
+// controllerOptions returns the options of the pod controller.
+func (r *PodStartupReconciler) controllerOptions() controller.Options {
+	return controller.Options{
+		MaxConcurrentReconciles: max(r.MaxConcurrentReconciles, 1),
+		RateLimiter:             newRateLimiter(r.MaxBackoff),
+	}
+}
+
+// newRateLimiter mirrors controller-runtime's default rate limiter, but caps
+// the per-pod exponential backoff at maxBackoff.
+func newRateLimiter(maxBackoff time.Duration) workqueue.TypedRateLimiter[reconcile.Request] {
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	return workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](5*time.Millisecond, maxBackoff),
+		&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
+
+// meetsCompleteness reports whether the pod has progressed far enough through
+// its lifecycle to satisfy the given completeness level.
+func meetsCompleteness(pod corev1.Pod, level Completeness) bool {
+	// Failures are interesting no matter how far the pod got
+	if pod.Status.Phase == corev1.PodFailed {
+		return true
+	}
+
+	scheduled := pod.Spec.NodeName != "" || !getConditionTime(pod, corev1.PodScheduled).IsZero()
+	if !scheduled {
+		return false
+	}
+
+	switch level {
+	case CompletenessInitialized:
+		return !getConditionTime(pod, corev1.PodInitialized).IsZero()
+	case CompletenessReady:
+		switch pod.Status.Phase {
+		case corev1.PodSucceeded:
+			return true
+		case corev1.PodRunning:
+			return !getConditionTime(pod, corev1.PodReady).IsZero()
+		default:
+			return false
+		}
+	default:
+		return true
+	}
+}
+
+// Reasons a Pending pod is waiting, as recorded in pendingReason.
+const (
+	PendingUnschedulable = "Unschedulable"
+	PendingImagePull     = "ImagePull"
+	PendingScheduling    = "Scheduling"
+)
+
+// getPendingReason tells apart a Pending pod the scheduler rejected, one
+// whose containers are still being created or pulled, and one simply waiting
+// to be scheduled.
+func getPendingReason(pod corev1.Pod) string {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse {
+			return PendingUnschedulable
+		}
+	}
+	statuses := append(slices.Clone(pod.Status.InitContainerStatuses), pod.Status.ContainerStatuses...)
+	for _, c := range statuses {
+		if c.State.Waiting == nil {
+			continue
+		}
+		switch c.State.Waiting.Reason {
+		case "ContainerCreating", "ImagePullBackOff", "ErrImagePull":
+			return PendingImagePull
+		}
+	}
+	return PendingScheduling
+}
+
+// schedulingGated reports whether the pod is held back from scheduling by
+// its scheduling gates.
+func schedulingGated(pod corev1.Pod) bool {
+	if len(pod.Spec.SchedulingGates) > 0 {
+		return true
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse &&
+			cond.Reason == corev1.PodReasonSchedulingGated {
+			return true
+		}
+	}
+	return false
+}
+
+// totalRequests sums the CPU and memory requests of the pod's containers.
+// Containers without requests count as zero.
+func totalRequests(pod corev1.Pod) (cpu, memory resource.Quantity) {
+	for _, c := range pod.Spec.Containers {
+		if q, ok := c.Resources.Requests[corev1.ResourceCPU]; ok {
+			cpu.Add(q)
+		}
+		if q, ok := c.Resources.Requests[corev1.ResourceMemory]; ok {
+			memory.Add(q)
+		}
+	}
+	return cpu, memory
+}
+
+// hasAffinity reports whether the pod carries any node, pod or anti-affinity
+// rules.
+func hasAffinity(pod corev1.Pod) bool {
+	a := pod.Spec.Affinity
+	return a != nil && (a.NodeAffinity != nil || a.PodAffinity != nil || a.PodAntiAffinity != nil)
+}
+
+// capturedLabels returns the pod's labels with the given keys.
+func capturedLabels(pod corev1.Pod, keys []string) map[string]string {
+	labels := map[string]string{}
+	for _, key := range keys {
+		if value, ok := pod.Labels[key]; ok {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+// hasPVC reports whether the pod mounts a PersistentVolumeClaim, directly or
+// through a generic ephemeral volume.
+func hasPVC(pod corev1.Pod) bool {
+	return slices.ContainsFunc(pod.Spec.Volumes, func(v corev1.Volume) bool {
+		return v.PersistentVolumeClaim != nil || v.Ephemeral != nil
+	})
+}
+
+// volumeBindLatency approximates how long the pod waited for its volumes
+// after being scheduled. The kubelet attaches and mounts volumes before it
+// creates the sandbox, so the PodReadyToStartContainers condition bounds the
+// wait; clusters without that condition fall back to Initialized, which also
+// covers any init containers. Like scheduledToInitialized it is clamped to
+// zero.
+func volumeBindLatency(pod corev1.Pod, scheduled, initialized time.Time) (time.Duration, bool) {
+	end := getConditionTime(pod, corev1.PodReadyToStartContainers)
+	if end.IsZero() {
+		end = initialized
+	}
+	if scheduled.IsZero() || end.IsZero() {
+		return 0, false
+	}
+	return max(end.Sub(scheduled), 0), true
+}
+
+// preempted reports whether preemption was involved in placing the pod:
+// either the scheduler nominated a node for it after evicting lower-priority
+// pods, or the pod itself was marked as a preemption victim.
+func preempted(pod corev1.Pod) bool {
+	if pod.Status.NominatedNodeName != "" {
+		return true
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.DisruptionTarget && cond.Status == corev1.ConditionTrue &&
+			cond.Reason == corev1.PodReasonPreemptionByScheduler {
+			return true
+		}
+	}
+	return false
+}
+
 func getConditionTime(pod corev1.Pod, condType corev1.PodConditionType) time.Time {
 	for _, cond := range pod.Status.Conditions {
 		if cond.Type == condType && cond.Status == corev1.ConditionTrue {
@@ -171,9 +1312,83 @@ func getConditionTime(pod corev1.Pod, condType corev1.PodConditionType) time.Tim
 	return time.Time{}
 }
 
-func getPhaseTime(pod corev1.Pod, phase corev1.PodPhase) time.Time {
+// getPhaseTime returns now if the pod is in phase. The API keeps no
+// transition time for phases, so this is when the reconciler saw it.
+func getPhaseTime(pod corev1.Pod, phase corev1.PodPhase, now time.Time) time.Time {
 	if pod.Status.Phase == phase {
-		return time.Now()
+		return now
+	}
+	return time.Time{}
+}
+
+// containerStatusesLag reports whether the pod is Running but the status of
+// some of its containers hasn't been reported yet.
+func containerStatusesLag(pod corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	reported := map[string]bool{}
+	for _, c := range pod.Status.ContainerStatuses {
+		if c.State.Running != nil || c.State.Waiting != nil || c.State.Terminated != nil {
+			reported[c.Name] = true
+		}
+	}
+	return slices.ContainsFunc(pod.Spec.Containers, func(c corev1.Container) bool { return !reported[c.Name] })
+}
+
+// isTerminal reports whether the pod has Succeeded or Failed.
+func isTerminal(pod corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+// getRuntime returns how long a terminal pod's containers ran, from the
+// first of them starting to the pod's terminal time. It is zero for pods
+// that have not finished or whose containers never started.
+func getRuntime(pod corev1.Pod) time.Duration {
+	finished := getTerminalTime(pod, pod.Status.Phase)
+	if !isTerminal(pod) || finished.IsZero() {
+		return 0
+	}
+	var first time.Time
+	for _, c := range pod.Status.ContainerStatuses {
+		if c.State.Terminated == nil || c.State.Terminated.StartedAt.IsZero() {
+			continue
+		}
+		if started := c.State.Terminated.StartedAt.Time; first.IsZero() || started.Before(first) {
+			first = started
+		}
+	}
+	if first.IsZero() {
+		return 0
+	}
+	return max(finished.Sub(first), 0)
+}
+
+// getTerminalTime returns when the pod reached the given terminal phase: the
+// latest FinishedAt across its app containers, or failing that the time the
+// Ready condition turned False. It is zero if the pod is in another phase.
+func getTerminalTime(pod corev1.Pod, phase corev1.PodPhase) time.Time {
+	if pod.Status.Phase != phase {
+		return time.Time{}
+	}
+
+	var latest time.Time
+	for _, c := range pod.Status.ContainerStatuses {
+		if c.State.Terminated != nil {
+			finished := c.State.Terminated.FinishedAt.Time
+			if finished.After(latest) {
+				latest = finished
+			}
+		}
+	}
+	if !latest.IsZero() {
+		return latest
+	}
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionFalse {
+			return cond.LastTransitionTime.Time
+		}
 	}
 	return time.Time{}
 }
@@ -191,11 +1406,287 @@ func getAllContainersStartedTime(pod corev1.Pod) time.Time {
 	return latest
 }
 
-func timeZeroSafe(t time.Time) time.Time {
-	if t.IsZero() {
-		return time.Now()
+// getAllContainersRunningTime returns the latest start time of the pod's
+// containers, but only once every container in the spec is running. Unlike
+// getAllContainersStartedTime it is zero while any container is still
+// waiting.
+func getAllContainersRunningTime(pod corev1.Pod) time.Time {
+	started := map[string]time.Time{}
+	for _, c := range pod.Status.ContainerStatuses {
+		if c.State.Running != nil {
+			started[c.Name] = c.State.Running.StartedAt.Time
+		}
+	}
+
+	var latest time.Time
+	for _, c := range pod.Spec.Containers {
+		start, ok := started[c.Name]
+		if !ok {
+			return time.Time{}
+		}
+		if start.After(latest) {
+			latest = start
+		}
+	}
+	return latest
+}
+
+// getSidecarsStartedTimes returns the start time of each native sidecar, i.e.
+// init containers declared with restartPolicy: Always. Clusters without
+// sidecar support never set the policy, so the result is simply empty.
+func getSidecarsStartedTimes(pod corev1.Pod) map[string]time.Time {
+	sidecars := getSidecarNames(pod)
+	started := map[string]time.Time{}
+	for _, c := range pod.Status.InitContainerStatuses {
+		if sidecars[c.Name] && c.State.Running != nil {
+			started[c.Name] = c.State.Running.StartedAt.Time
+		}
+	}
+	return started
+}
+
+// getContainersReady returns the readiness of each app container and native
+// sidecar of the pod.
+func getContainersReady(pod corev1.Pod) map[string]bool {
+	sidecars := getSidecarNames(pod)
+	ready := map[string]bool{}
+	for _, c := range pod.Status.ContainerStatuses {
+		ready[c.Name] = c.Ready
+	}
+	for _, c := range pod.Status.InitContainerStatuses {
+		if sidecars[c.Name] {
+			ready[c.Name] = c.Ready
+		}
+	}
+	return ready
+}
+
+// getContainerTimes returns, for each app container and native sidecar of
+// the pod, its type, when it started and when it became ready. Readiness
+// times come from observed transitions, falling back to podReady, the pod's
+// Ready time, for containers that were already ready when first seen.
+func getContainerTimes(pod corev1.Pod, observedReady map[string]time.Time, podReady time.Time) map[string]map[string]string {
+	containers := map[string]map[string]string{}
+	add := func(c corev1.ContainerStatus, containerType string) {
+		entry := map[string]string{"type": containerType}
+		if c.State.Running != nil {
+			entry["startedAt"] = fmtTime(c.State.Running.StartedAt.Time)
+		}
+		if at, ok := observedReady[c.Name]; ok {
+			entry["ready"] = fmtTime(at)
+		} else if c.Ready && !podReady.IsZero() {
+			entry["ready"] = fmtTime(podReady)
+		}
+		containers[c.Name] = entry
+	}
+
+	sidecars := getSidecarNames(pod)
+	for _, c := range pod.Status.InitContainerStatuses {
+		if sidecars[c.Name] {
+			add(c, "sidecar")
+		}
+	}
+	for _, c := range pod.Status.ContainerStatuses {
+		add(c, "app")
+	}
+	return containers
+}
+
+// getInitContainerTimes returns, in spec order, when each init container that
+// has started started and finished and how long it ran, to find the one
+// holding the pod up. A running init container has no finish time or
+// duration yet. Native sidecars keep running, so they are left out.
+func getInitContainerTimes(pod corev1.Pod) []map[string]string {
+	statuses := map[string]corev1.ContainerStatus{}
+	for _, c := range pod.Status.InitContainerStatuses {
+		statuses[c.Name] = c
+	}
+	sidecars := getSidecarNames(pod)
+	var inits []map[string]string
+	for _, spec := range pod.Spec.InitContainers {
+		c, ok := statuses[spec.Name]
+		if !ok || sidecars[spec.Name] {
+			continue
+		}
+		entry := map[string]string{"name": spec.Name}
+		switch {
+		case c.State.Terminated != nil:
+			started, finished := c.State.Terminated.StartedAt.Time, c.State.Terminated.FinishedAt.Time
+			entry["startedAt"] = fmtTime(started)
+			entry["finishedAt"] = fmtTime(finished)
+			if !started.IsZero() && !finished.IsZero() {
+				entry["duration"] = fmt.Sprintf("%v", max(finished.Sub(started), 0))
+			}
+		case c.State.Running != nil:
+			entry["startedAt"] = fmtTime(c.State.Running.StartedAt.Time)
+		default:
+			continue
+		}
+		inits = append(inits, entry)
+	}
+	return inits
+}
+
+// getTerminationReasons returns why each init or app container that exited
+// with an error last terminated, such as OOMKilled, and its exit code. The
+// current state is used when the container exited with an error, otherwise
+// its last termination, marked fromLastState, which is how a crash of a
+// restarted container is seen, even one that has since exited cleanly.
+// Containers that never exited with an error are left out.
+func getTerminationReasons(pod corev1.Pod) map[string]map[string]any {
+	reasons := map[string]map[string]any{}
+	statuses := slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses)
+	for _, c := range statuses {
+		terminated, fromLastState := c.State.Terminated, false
+		if terminated == nil || terminated.ExitCode == 0 {
+			terminated, fromLastState = c.LastTerminationState.Terminated, true
+		}
+		if terminated == nil || terminated.ExitCode == 0 {
+			continue
+		}
+		entry := map[string]any{"reason": terminated.Reason, "exitCode": int64(terminated.ExitCode)}
+		if fromLastState {
+			entry["fromLastState"] = true
+		}
+		reasons[c.Name] = entry
+	}
+	return reasons
+}
+
+// getSidecarNames returns the names of the pod's native sidecars.
+func getSidecarNames(pod corev1.Pod) map[string]bool {
+	sidecars := map[string]bool{}
+	for _, c := range pod.Spec.InitContainers {
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			sidecars[c.Name] = true
+		}
+	}
+	return sidecars
+}
+
+// getEphemeralContainersStartedTimes returns the start time of each
+// ephemeral container that is or was running.
+func getEphemeralContainersStartedTimes(pod corev1.Pod) map[string]time.Time {
+	started := map[string]time.Time{}
+	for _, c := range pod.Status.EphemeralContainerStatuses {
+		switch {
+		case c.State.Running != nil:
+			started[c.Name] = c.State.Running.StartedAt.Time
+		case c.State.Terminated != nil:
+			started[c.Name] = c.State.Terminated.StartedAt.Time
+		}
+	}
+	return started
+}
+
+// getStartupProbeDurations returns, for each running container that declares
+// a startup probe, the time from the container starting to the pod becoming
+// ready, which is dominated by the application bootstrapping. It is empty
+// until the pod is ready.
+func getStartupProbeDurations(pod corev1.Pod, ready time.Time) map[string]time.Duration {
+	durations := map[string]time.Duration{}
+	if ready.IsZero() {
+		return durations
+	}
+	probed := map[string]bool{}
+	for _, c := range pod.Spec.Containers {
+		if c.StartupProbe != nil {
+			probed[c.Name] = true
+		}
+	}
+	for _, c := range pod.Status.ContainerStatuses {
+		if probed[c.Name] && c.State.Running != nil {
+			durations[c.Name] = max(ready.Sub(c.State.Running.StartedAt.Time), 0)
+		}
+	}
+	return durations
+}
+
+// getReadinessGateTimes returns when each of the pod's readiness gates
+// became True. Gates that have not passed yet are left out.
+func getReadinessGateTimes(pod corev1.Pod) map[string]time.Time {
+	passed := map[string]time.Time{}
+	for _, gate := range pod.Spec.ReadinessGates {
+		if t := getConditionTime(pod, gate.ConditionType); !t.IsZero() {
+			passed[string(gate.ConditionType)] = t
+		}
+	}
+	return passed
+}
+
+// rawConditions returns the pod's conditions as they are embedded in a
+// record under IncludeRawConditions.
+func rawConditions(pod corev1.Pod) []map[string]string {
+	conditions := make([]map[string]string, 0, len(pod.Status.Conditions))
+	for _, c := range pod.Status.Conditions {
+		conditions = append(conditions, map[string]string{
+			"type":               string(c.Type),
+			"status":             string(c.Status),
+			"lastTransitionTime": fmtTime(c.LastTransitionTime.Time),
+			"reason":             c.Reason,
+		})
+	}
+	return conditions
+}
+
+// getDeletionRequestedTime returns when deletion of the pod was requested,
+// or the zero time when it is not terminating. The deletionTimestamp is set
+// to the end of the grace period, so the grace period is taken off again.
+func getDeletionRequestedTime(pod corev1.Pod) time.Time {
+	if pod.DeletionTimestamp == nil {
+		return time.Time{}
+	}
+	requested := pod.DeletionTimestamp.Time
+	if pod.DeletionGracePeriodSeconds != nil {
+		requested = requested.Add(-time.Duration(*pod.DeletionGracePeriodSeconds) * time.Second)
+	}
+	return requested
+}
+
+// ignored reports whether the pod opted out of measurement with the
+// IgnoreAnnotation.
+func (r *PodStartupReconciler) ignored(pod corev1.Pod) bool {
+	annotation := r.IgnoreAnnotation
+	if annotation == "" {
+		annotation = DefaultIgnoreAnnotation
+	}
+	return pod.Annotations[annotation] == "true"
+}
+
+// recordID returns the identity the pod's records are keyed on, see IDFunc.
+func (r *PodStartupReconciler) recordID(pod corev1.Pod) types.UID {
+	if r.IDFunc != nil {
+		return types.UID(r.IDFunc(pod))
+	}
+	return pod.UID
+}
+
+// sampled reports whether the pod with this identity is in a sample of the given
+// fraction of pods, which is the same on every call.
+func sampled(uid types.UID, fraction float64) bool {
+	if fraction <= 0 || fraction >= 1 {
+		return true
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(uid))
+	return float64(h.Sum64())/math.MaxUint64 < fraction
+}
+
+// lifecycleLogLevel is the verbosity the record of pod is logged at: info
+// for terminal pods and pods slower to become ready than slowThreshold,
+// which defaults to DefaultExemplarThreshold like the histogram exemplars,
+// and V(1) for every other state.
+func lifecycleLogLevel(pod corev1.Pod, durations map[string]string, slowThreshold time.Duration) int {
+	if slowThreshold <= 0 {
+		slowThreshold = DefaultExemplarThreshold
+	}
+	if isTerminal(pod) {
+		return 0
+	}
+	if toReady, err := time.ParseDuration(durations["toReady"]); err == nil && toReady > slowThreshold {
+		return 0
 	}
-	return t
+	return 1
 }
 
 func fmtTime(t time.Time) string {
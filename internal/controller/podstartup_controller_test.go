@@ -19,14 +19,31 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/go-logr/logr/funcr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 )
 
 // ---------------- The actual test ----------------
@@ -104,3 +121,1537 @@ var _ = Describe("PodStartupReconciler", func() {
 		}, 10*time.Second, 500*time.Millisecond)
 	})
 })
+
+var _ = Describe("meetsCompleteness", func() {
+	scheduledPod := func(phase corev1.PodPhase, conds ...corev1.PodConditionType) corev1.Pod {
+		pod := corev1.Pod{
+			Spec:   corev1.PodSpec{NodeName: "fake-node"},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+		for _, c := range conds {
+			pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{
+				Type: c, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now(),
+			})
+		}
+		return pod
+	}
+
+	It("should skip a pod that was never scheduled", func() {
+		pod := corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending}}
+		Expect(meetsCompleteness(pod, CompletenessScheduled)).To(BeFalse())
+		Expect(meetsCompleteness(pod, CompletenessReady)).To(BeFalse())
+	})
+
+	It("should always record a Failed pod", func() {
+		pod := corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed}}
+		Expect(meetsCompleteness(pod, CompletenessReady)).To(BeTrue())
+	})
+
+	It("should accept a scheduled-only pod at the Scheduled level only", func() {
+		pod := scheduledPod(corev1.PodPending, corev1.PodScheduled)
+		Expect(meetsCompleteness(pod, "")).To(BeTrue())
+		Expect(meetsCompleteness(pod, CompletenessScheduled)).To(BeTrue())
+		Expect(meetsCompleteness(pod, CompletenessInitialized)).To(BeFalse())
+		Expect(meetsCompleteness(pod, CompletenessReady)).To(BeFalse())
+	})
+
+	It("should require readiness for Running pods at the Ready level", func() {
+		notReady := scheduledPod(corev1.PodRunning, corev1.PodScheduled, corev1.PodInitialized)
+		Expect(meetsCompleteness(notReady, CompletenessInitialized)).To(BeTrue())
+		Expect(meetsCompleteness(notReady, CompletenessReady)).To(BeFalse())
+
+		ready := scheduledPod(corev1.PodRunning, corev1.PodScheduled, corev1.PodInitialized, corev1.PodReady)
+		Expect(meetsCompleteness(ready, CompletenessReady)).To(BeTrue())
+	})
+})
+
+var _ = Describe("getSidecarsStartedTimes", func() {
+	It("should report native sidecars separately from regular init containers", func() {
+		always := corev1.ContainerRestartPolicyAlways
+		sidecarStart := metav1.NewTime(time.Now().Add(-2 * time.Second).Truncate(time.Second))
+		pod := corev1.Pod{
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					{Name: "setup", Image: "busybox"},
+					{Name: "proxy", Image: "envoy", RestartPolicy: &always},
+				},
+			},
+			Status: corev1.PodStatus{
+				InitContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name: "setup",
+						State: corev1.ContainerState{
+							Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, FinishedAt: metav1.Now()},
+						},
+					},
+					{
+						Name: "proxy",
+						State: corev1.ContainerState{
+							Running: &corev1.ContainerStateRunning{StartedAt: sidecarStart},
+						},
+					},
+				},
+			},
+		}
+
+		started := getSidecarsStartedTimes(pod)
+		Expect(started).To(HaveLen(1))
+		Expect(started).To(HaveKeyWithValue("proxy", sidecarStart.Time))
+	})
+
+	It("should return nothing for pods without sidecars", func() {
+		pod := corev1.Pod{
+			Spec: corev1.PodSpec{InitContainers: []corev1.Container{{Name: "setup"}}},
+			Status: corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "setup",
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.Now()}},
+			}}},
+		}
+		Expect(getSidecarsStartedTimes(pod)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("scheduledToInitialized", func() {
+	durationsOf := func(scheduled, initialized time.Time) map[string]string {
+		pod := newRunningPod("segmented")
+		pod.Status.Conditions = []corev1.PodCondition{
+			{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(scheduled)},
+			{Type: corev1.PodInitialized, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(initialized)},
+		}
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+		return recorder.Records()[0]["durations"].(map[string]string)
+	}
+
+	It("should measure the time between scheduling and initialization", func() {
+		scheduled := time.Now().Add(-10 * time.Second).Truncate(time.Second)
+		durations := durationsOf(scheduled, scheduled.Add(7*time.Second))
+		Expect(durations).To(HaveKeyWithValue("scheduledToInitialized", "7s"))
+	})
+
+	It("should clamp an apparent negative gap to zero", func() {
+		scheduled := time.Now().Add(-10 * time.Second).Truncate(time.Second)
+		durations := durationsOf(scheduled, scheduled.Add(-time.Second))
+		Expect(durations).To(HaveKeyWithValue("scheduledToInitialized", "0s"))
+	})
+})
+
+var _ = Describe("scheduledToContainersStarted", func() {
+	reconcileSegmented := func(name string, scheduled, started time.Time) map[string]string {
+		pod := newRunningPod(name)
+		pod.Spec.NodeName = name + "-node"
+		pod.Status.Conditions = []corev1.PodCondition{
+			{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(scheduled)},
+		}
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "c1",
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(started)}},
+		}}
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build(),
+			Scheme: scheme.Scheme,
+			Sinks:  []Sink{recorder},
+		}
+		for range 2 {
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		return recorder.Records()[0]["durations"].(map[string]string)
+	}
+
+	It("should measure the time between scheduling and the containers starting", func() {
+		scheduled := time.Now().Add(-20 * time.Second).Truncate(time.Second)
+		durations := reconcileSegmented("setup", scheduled, scheduled.Add(12*time.Second))
+		Expect(durations).To(HaveKeyWithValue("scheduledToContainersStarted", "12s"))
+
+		m := gatherMetric("pod_startup_scheduled_to_containers_started_seconds", map[string]string{"node": "setup-node"})
+		Expect(m).NotTo(BeNil())
+		Expect(m.GetHistogram().GetSampleCount()).To(BeEquivalentTo(1), "each pod is observed once")
+		Expect(m.GetHistogram().GetSampleSum()).To(BeNumerically("==", 12))
+	})
+
+	It("should clamp an apparent negative gap to zero", func() {
+		scheduled := time.Now().Add(-20 * time.Second).Truncate(time.Second)
+		durations := reconcileSegmented("skewed", scheduled, scheduled.Add(-time.Second))
+		Expect(durations).To(HaveKeyWithValue("scheduledToContainersStarted", "0s"))
+	})
+})
+
+var _ = Describe("Startup probes", func() {
+	It("should measure from container start to ready for probed containers only", func() {
+		now := time.Now().Truncate(time.Second)
+		probe := &corev1.Probe{ProbeHandler: corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: []string{"true"}}}}
+		pod := corev1.Pod{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "app", StartupProbe: probe},
+				{Name: "sidecar"},
+			}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(now.Add(-45 * time.Second))}}},
+				{Name: "sidecar", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(now.Add(-50 * time.Second))}}},
+			}},
+		}
+
+		Expect(getStartupProbeDurations(pod, now)).To(Equal(map[string]time.Duration{"app": 45 * time.Second}))
+		Expect(getStartupProbeDurations(pod, time.Time{})).To(BeEmpty(), "not ready yet")
+	})
+
+	It("should add the durations to the record", func() {
+		pod := newRunningPod("probed")
+		ready := getConditionTime(*pod, corev1.PodReady)
+		pod.Spec.Containers[0].StartupProbe = &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz"}},
+		}
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "c1",
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(ready.Add(-2 * time.Second))}},
+		}}
+
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()[0]["startupProbeDuration"]).To(Equal(map[string]string{"c1": "2s"}))
+	})
+})
+
+var _ = Describe("Ephemeral containers", func() {
+	It("should flag pods with debug containers and record when they started", func() {
+		started := metav1.NewTime(time.Now().Add(-time.Second).Truncate(time.Second))
+		pod := newRunningPod("debugged")
+		pod.Status.EphemeralContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "debugger-x7k2p",
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: started}},
+		}}
+
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, pod)
+		Expect(err).NotTo(HaveOccurred())
+		rec := recorder.Records()[0]
+		Expect(rec).To(HaveKeyWithValue("hasEphemeralContainers", true))
+		Expect(rec["ephemeralContainersStarted"]).To(Equal(map[string]string{
+			"debugger-x7k2p": started.Format(time.RFC3339),
+		}))
+	})
+
+	It("should report false for pods without them", func() {
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, newRunningPod("undebugged"))
+		Expect(err).NotTo(HaveOccurred())
+		rec := recorder.Records()[0]
+		Expect(rec).To(HaveKeyWithValue("hasEphemeralContainers", false))
+		Expect(rec).NotTo(HaveKey("ephemeralContainersStarted"))
+	})
+})
+
+var _ = Describe("Readiness gates", func() {
+	It("should record when each gate passed and how long after the containers started", func() {
+		started := time.Now().Add(-10 * time.Second).Truncate(time.Second)
+		pod := newRunningPod("gated")
+		pod.Spec.ReadinessGates = []corev1.PodReadinessGate{
+			{ConditionType: "example.com/load-balancer"},
+			{ConditionType: "example.com/pending-gate"},
+		}
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "c1",
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(started)}},
+		}}
+		pod.Status.Conditions = append(pod.Status.Conditions,
+			corev1.PodCondition{
+				Type: "example.com/load-balancer", Status: corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(started.Add(4 * time.Second)),
+			},
+			corev1.PodCondition{Type: "example.com/pending-gate", Status: corev1.ConditionFalse},
+		)
+
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+		rec := recorder.Records()[0]
+
+		Expect(rec["readinessGates"]).To(Equal(map[string]string{
+			"example.com/load-balancer": started.Add(4 * time.Second).Format(time.RFC3339),
+		}))
+		Expect(rec["readinessGateDurations"]).To(Equal(map[string]string{"example.com/load-balancer": "4s"}))
+	})
+
+	It("should leave the fields out for pods without gates", func() {
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, newRunningPod("ungated"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()[0]).NotTo(HaveKey("readinessGates"))
+	})
+})
+
+var _ = Describe("Terminal times", func() {
+	created := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	terminatedPod := func(phase corev1.PodPhase, finished ...time.Time) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "job-" + strings.ToLower(string(phase)), Namespace: "default",
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec:   corev1.PodSpec{NodeName: "fake-node"},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+		for i, t := range finished {
+			pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{
+				Name: fmt.Sprintf("c%d", i),
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					StartedAt:  metav1.NewTime(created),
+					FinishedAt: metav1.NewTime(t),
+				}},
+			})
+		}
+		return pod
+	}
+
+	recordOf := func(pod *corev1.Pod) Record {
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+		return recorder.Records()[0]
+	}
+
+	It("should use the latest container finish time for toSucceeded", func() {
+		pod := terminatedPod(corev1.PodSucceeded, created.Add(20*time.Second), created.Add(30*time.Second))
+
+		durations := recordOf(pod)["durations"].(map[string]string)
+		Expect(durations).To(HaveKeyWithValue("toSucceeded", "30s"))
+		Expect(durations).NotTo(HaveKey("toFailed"))
+	})
+
+	It("should use the container finish time for toFailed", func() {
+		pod := terminatedPod(corev1.PodFailed, created.Add(12*time.Second))
+
+		durations := recordOf(pod)["durations"].(map[string]string)
+		Expect(durations).To(HaveKeyWithValue("toFailed", "12s"))
+		Expect(durations).NotTo(HaveKey("toSucceeded"))
+	})
+
+	It("should record the total runtime of the containers", func() {
+		pod := terminatedPod(corev1.PodSucceeded, created.Add(20*time.Second), created.Add(30*time.Second))
+
+		durations := recordOf(pod)["durations"].(map[string]string)
+		Expect(durations).To(HaveKeyWithValue("runtime", "30s"))
+	})
+
+	It("should only record terminal pods under TerminalOnly", func() {
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(),
+			&PodStartupReconciler{Sinks: []Sink{recorder}, TerminalOnly: true}, newRunningPod("terminal-only-running"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(BeEmpty())
+
+		pod := terminatedPod(corev1.PodFailed, created.Add(12*time.Second))
+		_, err = reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}, TerminalOnly: true}, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+		rec := recorder.Records()[0]
+		Expect(rec["phase"]).To(Equal(string(corev1.PodFailed)))
+		Expect(rec["durations"]).To(HaveKeyWithValue("toFailed", "12s"))
+		Expect(rec["durations"]).To(HaveKeyWithValue("runtime", "12s"))
+	})
+
+	It("should only record pods in the listed Phases", func() {
+		recorder := &recordingSink{}
+		reconcile := func(pod *corev1.Pod) {
+			r := &PodStartupReconciler{Sinks: []Sink{recorder}, Phases: []corev1.PodPhase{corev1.PodRunning, corev1.PodSucceeded}}
+			_, err := reconcilePod(context.Background(), r, pod)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		pending := newRunningPod("phases-pending")
+		pending.Status.Phase = corev1.PodPending
+		pending.Status.Conditions = pending.Status.Conditions[:1]
+		reconcile(pending)
+		Expect(recorder.Records()).To(BeEmpty(), "Pending pods must be skipped")
+
+		reconcile(terminatedPod(corev1.PodFailed))
+		Expect(recorder.Records()).To(BeEmpty(), "Failed is not listed")
+
+		reconcile(newRunningPod("phases-running"))
+		reconcile(terminatedPod(corev1.PodSucceeded))
+		var phases []any
+		for _, rec := range recorder.Records() {
+			phases = append(phases, rec["phase"])
+		}
+		Expect(phases).To(Equal([]any{string(corev1.PodRunning), string(corev1.PodSucceeded)}))
+	})
+
+	It("should fall back to the Ready=False transition without container finish times", func() {
+		pod := terminatedPod(corev1.PodFailed)
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type: corev1.PodReady, Status: corev1.ConditionFalse,
+			LastTransitionTime: metav1.NewTime(created.Add(7 * time.Second)),
+		}}
+
+		Expect(getTerminalTime(*pod, corev1.PodFailed)).To(Equal(created.Add(7 * time.Second)))
+		Expect(getTerminalTime(*pod, corev1.PodSucceeded)).To(BeZero())
+	})
+
+	It("should capture termination reasons and exit codes", func() {
+		pod := terminatedPod(corev1.PodFailed, created.Add(5*time.Second), created.Add(6*time.Second))
+		pod.Status.ContainerStatuses[0].State.Terminated.Reason = "OOMKilled"
+		pod.Status.ContainerStatuses[0].State.Terminated.ExitCode = 137
+		pod.Status.ContainerStatuses[1].State.Terminated.Reason = "Completed"
+		pod.Status.InitContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "init",
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(created)}},
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Reason: "Error", ExitCode: 1,
+			}},
+		}}
+
+		Expect(recordOf(pod)).To(HaveKeyWithValue("terminationReasons", map[string]map[string]any{
+			"c0":   {"reason": "OOMKilled", "exitCode": int64(137)},
+			"init": {"reason": "Error", "exitCode": int64(1), "fromLastState": true},
+		}))
+	})
+
+	It("should report an earlier crash of a container that then exited cleanly", func() {
+		pod := terminatedPod(corev1.PodSucceeded, created.Add(5*time.Second), created.Add(6*time.Second))
+		pod.Status.ContainerStatuses[0].LastTerminationState.Terminated = &corev1.ContainerStateTerminated{
+			Reason: "OOMKilled", ExitCode: 137,
+		}
+
+		Expect(recordOf(pod)).To(HaveKeyWithValue("terminationReasons", map[string]map[string]any{
+			"c0": {"reason": "OOMKilled", "exitCode": int64(137), "fromLastState": true},
+		}))
+	})
+})
+
+var _ = Describe("Init container breakdown", func() {
+	It("should record each init container's run in spec order", func() {
+		pod := newRunningPod("with-inits")
+		start := pod.CreationTimestamp.Time
+		pod.Spec.InitContainers = []corev1.Container{{Name: "fetch"}, {Name: "migrate"}, {Name: "warm"}, {Name: "pending"}}
+		terminated := func(name string, from, to time.Duration) corev1.ContainerStatus {
+			return corev1.ContainerStatus{Name: name, State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				StartedAt: metav1.NewTime(start.Add(from)), FinishedAt: metav1.NewTime(start.Add(to)),
+			}}}
+		}
+		// Statuses out of order, to check the spec order is kept
+		pod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+			{Name: "warm", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{
+				StartedAt: metav1.NewTime(start.Add(8 * time.Second)),
+			}}},
+			terminated("migrate", 2*time.Second, 7*time.Second),
+			terminated("fetch", time.Second, 2*time.Second),
+			{Name: "pending", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}}},
+		}
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, pod)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(recorder.Records()).To(HaveLen(1))
+		Expect(recorder.Records()[0]).To(HaveKeyWithValue("initContainers", []map[string]string{
+			{
+				"name": "fetch", "duration": "1s",
+				"startedAt": fmtTime(start.Add(time.Second)), "finishedAt": fmtTime(start.Add(2 * time.Second)),
+			},
+			{
+				"name": "migrate", "duration": "5s",
+				"startedAt": fmtTime(start.Add(2 * time.Second)), "finishedAt": fmtTime(start.Add(7 * time.Second)),
+			},
+			{"name": "warm", "startedAt": fmtTime(start.Add(8 * time.Second))},
+		}))
+	})
+
+	It("should leave the breakdown out of pods without init containers", func() {
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, newRunningPod("no-inits"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()[0]).NotTo(HaveKey("initContainers"))
+	})
+})
+
+var _ = Describe("Scheduling constraints", func() {
+	recordOf := func(pod *corev1.Pod) Record {
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{Sinks: []Sink{recorder}}
+		_, err := reconcilePod(context.Background(), r, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+		return recorder.Records()[0]
+	}
+
+	It("should flag node affinity", func() {
+		pod := newRunningPod("affinity-pod")
+		pod.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"},
+					}},
+				}},
+			},
+		}}
+
+		rec := recordOf(pod)
+		Expect(rec).To(HaveKeyWithValue("hasAffinity", true))
+		Expect(rec).To(HaveKeyWithValue("hasNodeSelector", false))
+		Expect(rec).To(HaveKeyWithValue("hasTolerations", false))
+	})
+
+	It("should flag node selectors and tolerations", func() {
+		pod := newRunningPod("selector-pod")
+		pod.Spec.NodeSelector = map[string]string{"disk": "ssd"}
+		pod.Spec.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}
+
+		rec := recordOf(pod)
+		Expect(rec).To(HaveKeyWithValue("hasAffinity", false))
+		Expect(rec).To(HaveKeyWithValue("hasNodeSelector", true))
+		Expect(rec).To(HaveKeyWithValue("hasTolerations", true))
+	})
+
+	It("should measure how long scheduling gates held a pod", func() {
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{Sinks: []Sink{recorder}}
+		scheduledPod := newRunningPod("gated")
+		gatedPod := scheduledPod.DeepCopy()
+		gatedPod.Spec.NodeName = ""
+		gatedPod.Spec.SchedulingGates = []corev1.PodSchedulingGate{{Name: "example.com/quota"}}
+		gatedPod.Status = corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonSchedulingGated,
+			}},
+		}
+
+		for _, pod := range []*corev1.Pod{gatedPod, scheduledPod} {
+			r.Client = nil
+			_, err := reconcilePod(context.Background(), r, pod)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		recs := recorder.Records()
+		Expect(recs).NotTo(BeEmpty())
+		rec := recs[len(recs)-1]
+		scheduled := getConditionTime(*scheduledPod, corev1.PodScheduled)
+		Expect(rec["timestamps"]).To(HaveKeyWithValue("schedulingGatesClearedAt", fmtTime(scheduled)))
+		Expect(rec["durations"]).To(HaveKeyWithValue("gatedDuration", "1s"))
+
+		ungated := recordOf(newRunningPod("ungated"))
+		Expect(ungated["timestamps"]).NotTo(HaveKey("schedulingGatesClearedAt"))
+		Expect(ungated["durations"]).NotTo(HaveKey("gatedDuration"))
+	})
+
+	It("should copy only the configured labels", func() {
+		pod := newRunningPod("labelled")
+		pod.Labels = map[string]string{"app": "web", "team": "payments", "version": "v2"}
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{Sinks: []Sink{recorder}, CaptureLabels: []string{"app", "team", "tier"}}
+		_, err := reconcilePod(context.Background(), r, pod)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(recorder.Records()[0]).To(HaveKeyWithValue("labels", map[string]string{"app": "web", "team": "payments"}))
+		Expect(recordOf(pod)).NotTo(HaveKey("labels"), "nothing is copied unless configured")
+	})
+
+	It("should flag pods mounting a PVC with their volume bind latency", func() {
+		pod := newRunningPod("pvc-pod")
+		pod.Spec.Volumes = []corev1.Volume{{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-pvc"},
+			},
+		}}
+		scheduled := pod.Status.Conditions[0].LastTransitionTime.Time
+		pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{
+			Type: corev1.PodReadyToStartContainers, Status: corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(scheduled.Add(time.Second)),
+		})
+
+		rec := recordOf(pod)
+		Expect(rec).To(HaveKeyWithValue("hasPVC", true))
+		Expect(rec["durations"]).To(HaveKeyWithValue("volumeBindLatency", "1s"))
+	})
+
+	It("should leave out the volume bind latency without a PVC or the conditions", func() {
+		rec := recordOf(newRunningPod("no-pvc"))
+		Expect(rec).To(HaveKeyWithValue("hasPVC", false))
+		Expect(rec["durations"]).NotTo(HaveKey("volumeBindLatency"))
+
+		pod := newRunningPod("pvc-no-conditions")
+		pod.Spec.Volumes = []corev1.Volume{{
+			Name:         "scratch",
+			VolumeSource: corev1.VolumeSource{Ephemeral: &corev1.EphemeralVolumeSource{}},
+		}}
+		rec = recordOf(pod)
+		Expect(rec).To(HaveKeyWithValue("hasPVC", true))
+		Expect(rec["durations"]).NotTo(HaveKey("volumeBindLatency"))
+	})
+
+	It("should record the priority class and preemption", func() {
+		pod := newRunningPod("priority-pod")
+		priority := int32(1000)
+		pod.Spec.PriorityClassName = "batch-low"
+		pod.Spec.Priority = &priority
+		pod.Status.NominatedNodeName = "node-a"
+
+		rec := recordOf(pod)
+		Expect(rec).To(HaveKeyWithValue("priorityClassName", "batch-low"))
+		Expect(rec).To(HaveKeyWithValue("priority", int64(1000)))
+		Expect(rec).To(HaveKeyWithValue("preempted", true))
+	})
+
+	It("should flag preemption victims", func() {
+		pod := newRunningPod("victim-pod")
+		pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{
+			Type:   corev1.DisruptionTarget,
+			Status: corev1.ConditionTrue,
+			Reason: corev1.PodReasonPreemptionByScheduler,
+		})
+
+		Expect(recordOf(pod)).To(HaveKeyWithValue("preempted", true))
+	})
+
+	It("should leave the priority empty for pods without a class", func() {
+		rec := recordOf(newRunningPod("plain-pod"))
+		Expect(rec).To(HaveKeyWithValue("priorityClassName", ""))
+		Expect(rec).NotTo(HaveKey("priority"))
+		Expect(rec).To(HaveKeyWithValue("preempted", false))
+	})
+})
+
+var _ = Describe("PollInterval", func() {
+	reconcileWith := func(interval time.Duration, pod *corev1.Pod) ctrl.Result {
+		r := &PodStartupReconciler{Sinks: []Sink{&recordingSink{}}, PollInterval: interval}
+		result, err := reconcilePod(context.Background(), r, pod)
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	notReady := func(name string) *corev1.Pod {
+		pod := newRunningPod(name)
+		pod.Status.Conditions = pod.Status.Conditions[:1]
+		return pod
+	}
+
+	It("should requeue Running pods that are not ready yet", func() {
+		Expect(reconcileWith(5*time.Second, notReady("poll-running")).RequeueAfter).To(Equal(5 * time.Second))
+	})
+
+	It("should not requeue when disabled", func() {
+		Expect(reconcileWith(0, notReady("poll-disabled")).RequeueAfter).To(BeZero())
+	})
+
+	It("should not requeue ready or terminal pods", func() {
+		Expect(reconcileWith(5*time.Second, newRunningPod("poll-ready")).RequeueAfter).To(BeZero())
+
+		pod := notReady("poll-failed")
+		pod.Status.Phase = corev1.PodFailed
+		Expect(reconcileWith(5*time.Second, pod).RequeueAfter).To(BeZero())
+	})
+})
+
+var _ = Describe("Container start times", func() {
+	first := metav1.NewTime(time.Now().Add(-5 * time.Second).Truncate(time.Second))
+	second := metav1.NewTime(time.Now().Add(-2 * time.Second).Truncate(time.Second))
+
+	withStatuses := func(statuses ...corev1.ContainerStatus) corev1.Pod {
+		return corev1.Pod{
+			Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}, {Name: "worker"}}},
+			Status: corev1.PodStatus{ContainerStatuses: statuses},
+		}
+	}
+	running := func(name string, at metav1.Time) corev1.ContainerStatus {
+		return corev1.ContainerStatus{Name: name, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: at}}}
+	}
+	waiting := func(name string) corev1.ContainerStatus {
+		return corev1.ContainerStatus{Name: name, State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}}
+	}
+
+	It("should only report all containers started once every container runs", func() {
+		partial := withStatuses(running("app", first), waiting("worker"))
+		Expect(getAllContainersStartedTime(partial)).To(Equal(first.Time))
+		Expect(getAllContainersRunningTime(partial)).To(BeZero())
+
+		missing := withStatuses(running("app", first))
+		Expect(getAllContainersRunningTime(missing)).To(BeZero())
+
+		full := withStatuses(running("app", first), running("worker", second))
+		Expect(getAllContainersStartedTime(full)).To(Equal(second.Time))
+		Expect(getAllContainersRunningTime(full)).To(Equal(second.Time))
+	})
+
+	It("should record both as distinct fields", func() {
+		pod := newRunningPod("partial-containers")
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: "c2", Image: "busybox"})
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{running("c1", first), waiting("c2")}
+
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, pod)
+		Expect(err).NotTo(HaveOccurred())
+
+		timestamps := recorder.Records()[0]["timestamps"].(map[string]string)
+		Expect(timestamps).To(HaveKeyWithValue("anyContainerStarted", first.Format(time.RFC3339)))
+		Expect(timestamps).To(HaveKeyWithValue("allContainersStarted", ""))
+		Expect(recorder.Records()[0]["durations"]).NotTo(HaveKey("toAllContainersStarted"))
+	})
+})
+
+var _ = Describe("getPendingReason", func() {
+	pending := func() corev1.Pod {
+		return corev1.Pod{
+			Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "c1"}}},
+			Status: corev1.PodStatus{Phase: corev1.PodPending},
+		}
+	}
+	waiting := func(reason string) corev1.ContainerStatus {
+		return corev1.ContainerStatus{Name: "c1", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}}
+	}
+
+	It("should report pods the scheduler rejected as Unschedulable", func() {
+		pod := pending()
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable,
+		}}
+		Expect(getPendingReason(pod)).To(Equal(PendingUnschedulable))
+	})
+
+	DescribeTable("should report containers being created or pulled as ImagePull",
+		func(reason string, init bool) {
+			pod := pending()
+			if init {
+				pod.Status.InitContainerStatuses = []corev1.ContainerStatus{waiting(reason)}
+			} else {
+				pod.Status.ContainerStatuses = []corev1.ContainerStatus{waiting(reason)}
+			}
+			Expect(getPendingReason(pod)).To(Equal(PendingImagePull))
+		},
+		Entry("ContainerCreating", "ContainerCreating", false),
+		Entry("ImagePullBackOff", "ImagePullBackOff", false),
+		Entry("init container pulling", "ImagePullBackOff", true),
+	)
+
+	It("should otherwise report Scheduling", func() {
+		Expect(getPendingReason(pending())).To(Equal(PendingScheduling))
+
+		pod := pending()
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{waiting("CrashLoopBackOff")}
+		Expect(getPendingReason(pod)).To(Equal(PendingScheduling))
+	})
+
+	It("should only be recorded for Pending pods", func() {
+		pod := newRunningPod("pending-reason")
+		pod.Status.Phase = corev1.PodPending
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{waiting("ContainerCreating")}
+
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()[0]).To(HaveKeyWithValue("pendingReason", PendingImagePull))
+
+		recorder = &recordingSink{}
+		_, err = reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, newRunningPod("running-no-reason"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()[0]).NotTo(HaveKey("pendingReason"))
+	})
+})
+
+var _ = Describe("QoS and resource requests", func() {
+	recordOf := func(pod *corev1.Pod) Record {
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, pod)
+		Expect(err).NotTo(HaveOccurred())
+		return recorder.Records()[0]
+	}
+
+	It("should sum the requests of a Guaranteed pod", func() {
+		pod := newRunningPod("guaranteed")
+		resources := corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("250m"),
+				corev1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+		}
+		resources.Limits = resources.Requests
+		pod.Spec.Containers = []corev1.Container{
+			{Name: "app", Image: "busybox", Resources: resources},
+			{Name: "proxy", Image: "envoy", Resources: *resources.DeepCopy()},
+		}
+		pod.Spec.Containers[1].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("1")
+		pod.Spec.Containers[1].Resources.Limits[corev1.ResourceCPU] = resource.MustParse("1")
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+			{Name: "app", State: pod.Status.ContainerStatuses[0].State},
+			{Name: "proxy", State: pod.Status.ContainerStatuses[0].State},
+		}
+		pod.Status.QOSClass = corev1.PodQOSGuaranteed
+
+		rec := recordOf(pod)
+		Expect(rec).To(HaveKeyWithValue("qosClass", "Guaranteed"))
+		Expect(rec).To(HaveKeyWithValue("cpuRequestMillicores", int64(1250)))
+		Expect(rec).To(HaveKeyWithValue("memoryRequestBytes", int64(128*1024*1024)))
+	})
+
+	It("should record zero requests for a BestEffort pod", func() {
+		pod := newRunningPod("best-effort")
+		pod.Status.QOSClass = corev1.PodQOSBestEffort
+
+		rec := recordOf(pod)
+		Expect(rec).To(HaveKeyWithValue("qosClass", "BestEffort"))
+		Expect(rec).To(HaveKeyWithValue("cpuRequestMillicores", int64(0)))
+		Expect(rec).To(HaveKeyWithValue("memoryRequestBytes", int64(0)))
+	})
+})
+
+var _ = Describe("Termination", func() {
+	It("should measure from the deletion request to removal", func() {
+		ctx := context.Background()
+		grace := int64(30)
+		pod := newRunningPod("terminating")
+		pod.Finalizers = []string{"example.com/hold"}
+		pod.DeletionGracePeriodSeconds = &grace
+		deletionTimestamp := metav1.NewTime(time.Now().Add(-10 * time.Second).Truncate(time.Second))
+		pod.DeletionTimestamp = &deletionTimestamp
+
+		recorder := &recordingSink{}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build()
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, Sinks: []Sink{recorder}}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+
+		// Dropping the last finalizer removes the terminating pod
+		var existing corev1.Pod
+		Expect(c.Get(ctx, req.NamespacedName, &existing)).To(Succeed())
+		existing.Finalizers = nil
+		Expect(c.Update(ctx, &existing)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(recorder.Records()).To(HaveLen(2))
+		rec := recorder.Records()[1]
+		Expect(rec).To(HaveKeyWithValue("pod", "terminating"))
+		Expect(rec).To(HaveKeyWithValue("uid", string(pod.UID)))
+		Expect(rec).To(HaveKeyWithValue("schemaVersion", RecordSchemaVersion))
+		requested := deletionTimestamp.Add(-30 * time.Second)
+		Expect(rec["timestamps"]).To(HaveKeyWithValue("deletionRequested", requested.Format(time.RFC3339)))
+		terminationDuration, ok := recordDuration(rec, "terminationDuration")
+		Expect(ok).To(BeTrue())
+		Expect(terminationDuration).To(BeNumerically("~", 40*time.Second, 5*time.Second))
+	})
+
+	It("should not record pods that were removed without being seen terminating", func() {
+		ctx := context.Background()
+		pod := newRunningPod("removed-directly")
+		recorder := &recordingSink{}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build()
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, Sinks: []Sink{recorder}}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.Delete(ctx, pod)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+	})
+	DescribeTable("should not record the termination of filtered pods",
+		func(configure func(r *PodStartupReconciler, pod *corev1.Pod)) {
+			ctx := context.Background()
+			pod := newRunningPod("filtered-terminating")
+			pod.Finalizers = []string{"example.com/hold"}
+			deletionTimestamp := metav1.NewTime(time.Now().Add(-10 * time.Second).Truncate(time.Second))
+			pod.DeletionTimestamp = &deletionTimestamp
+
+			recorder := &recordingSink{}
+			r := &PodStartupReconciler{Scheme: scheme.Scheme, Sinks: []Sink{recorder}}
+			configure(r, pod)
+			r.Client = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build()
+			req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			var existing corev1.Pod
+			Expect(r.Get(ctx, req.NamespacedName, &existing)).To(Succeed())
+			existing.Finalizers = nil
+			Expect(r.Update(ctx, &existing)).To(Succeed())
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Records()).To(BeEmpty())
+		},
+		Entry("outside the sample", func(r *PodStartupReconciler, pod *corev1.Pod) {
+			r.SampleRate = 0.5
+			for i := 0; sampled(pod.UID, r.SampleRate); i++ {
+				pod.UID = types.UID(fmt.Sprintf("uid-unsampled-%d", i))
+			}
+		}),
+		Entry("not terminal with TerminalOnly", func(r *PodStartupReconciler, _ *corev1.Pod) {
+			r.TerminalOnly = true
+		}),
+		Entry("in a phase that is not recorded", func(r *PodStartupReconciler, _ *corev1.Pod) {
+			r.Phases = []corev1.PodPhase{corev1.PodSucceeded, corev1.PodFailed}
+		}),
+	)
+})
+
+var _ = Describe("Schema version", func() {
+	It("should stamp every record with RecordSchemaVersion", func() {
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, newRunningPod("versioned"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+		Expect(recorder.Records()[0]).To(HaveKeyWithValue("schemaVersion", RecordSchemaVersion))
+
+		// The field must survive the round trip through the log file
+		encoded, err := json.Marshal(recorder.Records()[0])
+		Expect(err).NotTo(HaveOccurred())
+		var decoded Record
+		Expect(json.Unmarshal(encoded, &decoded)).To(Succeed())
+		Expect(decoded).To(HaveKeyWithValue("schemaVersion", RecordSchemaVersion))
+	})
+})
+
+var _ = Describe("Raw conditions", func() {
+	recordOf := func(r *PodStartupReconciler, pod *corev1.Pod) Record {
+		recorder := &recordingSink{}
+		r.Sinks = []Sink{recorder}
+		_, err := reconcilePod(context.Background(), r, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+		return recorder.Records()[0]
+	}
+
+	It("should embed the pod's conditions when enabled", func() {
+		pod := newRunningPod("raw-conditions")
+		pod.Status.Conditions[0].Reason = "Example"
+
+		rec := recordOf(&PodStartupReconciler{IncludeRawConditions: true}, pod)
+		Expect(rec["conditions"]).To(HaveLen(len(pod.Status.Conditions)))
+		first := pod.Status.Conditions[0]
+		Expect(rec["conditions"]).To(ContainElement(map[string]string{
+			"type":               string(first.Type),
+			"status":             string(first.Status),
+			"lastTransitionTime": first.LastTransitionTime.Format(time.RFC3339),
+			"reason":             "Example",
+		}))
+	})
+
+	It("should leave them out by default", func() {
+		rec := recordOf(&PodStartupReconciler{}, newRunningPod("no-raw-conditions"))
+		Expect(rec).NotTo(HaveKey("conditions"))
+	})
+})
+
+var _ = Describe("Cluster name", func() {
+	recordWith := func(clusterName string) Record {
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(),
+			&PodStartupReconciler{Sinks: []Sink{recorder}, ClusterName: clusterName}, newRunningPod("clustered"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+		return recorder.Records()[0]
+	}
+
+	It("should tag records with the configured cluster", func() {
+		Expect(recordWith("prod-eu-1")).To(HaveKeyWithValue("cluster", "prod-eu-1"))
+	})
+
+	It("should leave the field out when unset", func() {
+		Expect(recordWith("")).NotTo(HaveKey("cluster"))
+	})
+})
+
+var _ = Describe("Missing creation timestamp", func() {
+	It("should flag the record instead of measuring from a made-up baseline", func() {
+		pod := newRunningPod("no-creation-time")
+		pod.CreationTimestamp = metav1.Time{}
+
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+		rec := recorder.Records()[0]
+
+		Expect(rec).To(HaveKeyWithValue("baselineMissing", true))
+		durations := rec["durations"].(map[string]string)
+		for name := range durations {
+			Expect(name).NotTo(HavePrefix("to"), "duration %s needs a baseline", name)
+		}
+		Expect(rec["timestamps"]).To(HaveKeyWithValue("pending", ""))
+	})
+
+	It("should not flag pods with a creation time", func() {
+		recorder := &recordingSink{}
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, newRunningPod("with-creation-time"))
+		Expect(err).NotTo(HaveOccurred())
+		rec := recorder.Records()[0]
+		Expect(rec).NotTo(HaveKey("baselineMissing"))
+		Expect(rec["durations"]).To(HaveKey("toReady"))
+	})
+})
+
+var _ = Describe("Per-container readiness", func() {
+	It("should record when each container was seen becoming ready", func() {
+		ctx := context.Background()
+		started := time.Now().Add(-time.Minute).Truncate(time.Second)
+		always := corev1.ContainerRestartPolicyAlways
+		pod := newRunningPod("laggard")
+		pod.Spec.InitContainers = []corev1.Container{{Name: "proxy", Image: "envoy", RestartPolicy: &always}}
+		pod.Spec.Containers = []corev1.Container{{Name: "app", Image: "busybox"}, {Name: "worker", Image: "busybox"}}
+		status := func(name string, ready bool) corev1.ContainerStatus {
+			return corev1.ContainerStatus{
+				Name:  name,
+				Ready: ready,
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(started)}},
+			}
+		}
+		pod.Status.Conditions = pod.Status.Conditions[:1] // not ready yet
+		pod.Status.InitContainerStatuses = []corev1.ContainerStatus{status("proxy", true)}
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{status("app", false), status("worker", false)}
+
+		clock := started.Add(10 * time.Second)
+		recorder := &recordingSink{}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build()
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, Sinks: []Sink{recorder},
+			now: func() time.Time { return clock }}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+		setReady := func(ready map[string]bool, podReady bool) {
+			var existing corev1.Pod
+			Expect(c.Get(ctx, req.NamespacedName, &existing)).To(Succeed())
+			for i := range existing.Status.ContainerStatuses {
+				existing.Status.ContainerStatuses[i].Ready = ready[existing.Status.ContainerStatuses[i].Name]
+			}
+			if podReady {
+				existing.Status.Conditions = append(existing.Status.Conditions, corev1.PodCondition{
+					Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(clock),
+				})
+			}
+			Expect(c.Status().Update(ctx, &existing)).To(Succeed())
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		clock = started.Add(20 * time.Second)
+		setReady(map[string]bool{"app": true}, false)
+		clock = started.Add(45 * time.Second)
+		setReady(map[string]bool{"app": true, "worker": true}, true)
+
+		records := recorder.Records()
+		containers := records[len(records)-1]["containers"].(map[string]map[string]string)
+		Expect(containers).To(HaveLen(3))
+		Expect(containers["app"]).To(Equal(map[string]string{
+			"type":      "app",
+			"startedAt": started.Format(time.RFC3339),
+			"ready":     started.Add(20 * time.Second).Format(time.RFC3339),
+		}))
+		Expect(containers["worker"]).To(HaveKeyWithValue("ready", started.Add(45*time.Second).Format(time.RFC3339)))
+		// The sidecar was ready before it was first seen, so it falls back
+		// to the pod's Ready time
+		Expect(containers["proxy"]).To(HaveKeyWithValue("type", "sidecar"))
+		Expect(containers["proxy"]).To(HaveKeyWithValue("ready", started.Add(45*time.Second).Format(time.RFC3339)))
+	})
+})
+
+var _ = Describe("First observed", func() {
+	It("should keep the time the pod was first reconciled", func() {
+		ctx := context.Background()
+		pod := newRunningPod("preexisting")
+		ready := pod.Status.Conditions
+		pod.Status.Conditions = pod.Status.Conditions[:1] // not ready yet
+
+		first := time.Now().Truncate(time.Second)
+		clock := first
+		recorder := &recordingSink{}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build()
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, Sinks: []Sink{recorder},
+			now: func() time.Time { return clock }}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		var existing corev1.Pod
+		Expect(c.Get(ctx, req.NamespacedName, &existing)).To(Succeed())
+		existing.Status.Conditions = ready
+		Expect(c.Status().Update(ctx, &existing)).To(Succeed())
+		clock = first.Add(30 * time.Second)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		records := recorder.Records()
+		Expect(records).To(HaveLen(2))
+		for _, rec := range records {
+			timestamps := rec["timestamps"].(map[string]string)
+			Expect(timestamps).To(HaveKeyWithValue("firstObserved", first.Format(time.RFC3339)))
+		}
+	})
+})
+
+var _ = Describe("Custom durations", func() {
+	It("should add each declared duration", func() {
+		pod := newRunningPod("custom")
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{
+			Sinks: []Sink{recorder},
+			CustomDurations: []DurationSpec{
+				{Name: "scheduledToReady", From: "scheduled", To: "ready"},
+				{Name: "readyToSucceeded", From: "ready", To: "succeeded"},
+			},
+		}
+		_, err := reconcilePod(context.Background(), r, pod)
+		Expect(err).NotTo(HaveOccurred())
+
+		rec := recorder.Records()[0]
+		d, ok := recordDuration(rec, "scheduledToReady")
+		Expect(ok).To(BeTrue())
+		Expect(d).To(Equal(2 * time.Second))
+		// The pod hasn't succeeded, so there is nothing to measure yet
+		_, ok = recordDuration(rec, "readyToSucceeded")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should list every built-in timestamp and duration a record carries", func() {
+		recorder := &recordingSink{}
+		pod := newRunningPod("builtin-names")
+		_, err := reconcilePod(context.Background(), &PodStartupReconciler{Sinks: []Sink{recorder}}, pod)
+		Expect(err).NotTo(HaveOccurred())
+
+		rec := recorder.Records()[0]
+		for name := range rec["timestamps"].(map[string]string) {
+			Expect(TimestampNames).To(ContainElement(name))
+		}
+		for name := range recordDurations(rec) {
+			Expect(BuiltinDurationNames).To(ContainElement(name))
+		}
+	})
+})
+
+var _ = Describe("Clock steps", func() {
+	// expectNonNegative fails on any negative duration of rec, including
+	// the nested readiness gate durations.
+	expectNonNegative := func(rec Record) {
+		durations := recordDurations(rec)
+		Expect(durations).NotTo(BeEmpty())
+		for name, d := range durations {
+			Expect(d).To(BeNumerically(">=", 0), name)
+		}
+		for gate, value := range rec["readinessGateDurations"].(map[string]string) {
+			d, err := time.ParseDuration(value)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d).To(BeNumerically(">=", 0), gate)
+		}
+	}
+
+	It("should not record negative durations after a backward clock step", func() {
+		ctx := context.Background()
+		pod := newRunningPod("clock-step")
+		created := pod.CreationTimestamp.Time
+		// The node's clock stepped back after the pod was scheduled, so it
+		// reported starting and becoming ready before the pod was created
+		started := created.Add(-8 * time.Second)
+		pod.Spec.ReadinessGates = []corev1.PodReadinessGate{{ConditionType: "example.com/gate"}}
+		pod.Status.Conditions = []corev1.PodCondition{
+			{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(created.Add(time.Second))},
+			{Type: corev1.PodInitialized, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(created.Add(-9 * time.Second))},
+			{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(created.Add(-5 * time.Second))},
+			{Type: "example.com/gate", Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(started.Add(-2 * time.Second))},
+		}
+		pod.Status.ContainerStatuses[0].State.Running.StartedAt = metav1.NewTime(started)
+
+		clock := time.Now()
+		recorder := &recordingSink{}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build()
+		r := &PodStartupReconciler{
+			Client: c, Scheme: scheme.Scheme, Sinks: []Sink{recorder},
+			CustomDurations: []DurationSpec{{Name: "observedToReady", From: "firstObserved", To: "ready"}},
+			now:             func() time.Time { return clock },
+		}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Reconciling again after the controller's clock stepped back")
+		clock = clock.Add(-time.Hour)
+		var existing corev1.Pod
+		Expect(c.Get(ctx, req.NamespacedName, &existing)).To(Succeed())
+		existing.Labels = map[string]string{"changed": "true"}
+		Expect(c.Update(ctx, &existing)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(recorder.Records()).To(HaveLen(2))
+		for _, rec := range recorder.Records() {
+			expectNonNegative(rec)
+			Expect(rec["durations"]).To(HaveKeyWithValue("toReady", "0s"))
+			Expect(rec["durations"]).To(HaveKeyWithValue("observedToReady", "0s"))
+		}
+	})
+
+	It("should not record a negative termination duration", func() {
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{Sinks: []Sink{recorder}}
+		requested := time.Now()
+		r.recordTermination(context.Background(), types.NamespacedName{Namespace: "default", Name: "clock-step-removed"},
+			&podState{uid: "uid-clock-step-removed", deletionRequested: requested}, requested.Add(-time.Minute))
+
+		Expect(recorder.Records()).To(HaveLen(1))
+		Expect(recorder.Records()[0]["durations"]).To(HaveKeyWithValue("terminationDuration", "0s"))
+	})
+})
+
+var _ = Describe("Lifecycle event logging", func() {
+	levelOf := func(pod *corev1.Pod) string {
+		var lines []string
+		logger := funcr.New(func(_, args string) {
+			if strings.Contains(args, `"msg"="Pod lifecycle event"`) {
+				lines = append(lines, args)
+			}
+		}, funcr.Options{Verbosity: 1})
+		_, err := reconcilePod(logf.IntoContext(context.Background(), logger), &PodStartupReconciler{Sinks: []Sink{&recordingSink{}}}, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lines).To(HaveLen(1))
+		level, _, _ := strings.Cut(lines[0], " ")
+		return level
+	}
+
+	It("should log routine states at V(1)", func() {
+		Expect(levelOf(newRunningPod("routine"))).To(Equal(`"level"=1`))
+	})
+
+	It("should log terminal pods at info level", func() {
+		pod := newRunningPod("finished")
+		pod.Status.Phase = corev1.PodSucceeded
+		Expect(levelOf(pod)).To(Equal(`"level"=0`))
+	})
+
+	It("should log pods slower than the threshold at info level", func() {
+		pod := newRunningPod("slow")
+		pod.CreationTimestamp = metav1.NewTime(pod.CreationTimestamp.Add(-time.Hour))
+		Expect(levelOf(pod)).To(Equal(`"level"=0`))
+	})
+
+	It("should not log routine states by default", func() {
+		var logged bool
+		logger := funcr.New(func(_, args string) {
+			logged = logged || strings.Contains(args, "Pod lifecycle event")
+		}, funcr.Options{})
+		_, err := reconcilePod(logf.IntoContext(context.Background(), logger), &PodStartupReconciler{Sinks: []Sink{&recordingSink{}}}, newRunningPod("quiet"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(logged).To(BeFalse())
+	})
+})
+
+var _ = Describe("Sampling", func() {
+	It("should decide the same way for a UID every time", func() {
+		for i := range 100 {
+			uid := types.UID(fmt.Sprintf("uid-%d", i))
+			first := sampled(uid, 0.3)
+			for range 5 {
+				Expect(sampled(uid, 0.3)).To(Equal(first))
+			}
+			Expect(sampled(uid, 1)).To(BeTrue())
+			Expect(sampled(uid, 0)).To(BeTrue(), "zero is treated as unset")
+		}
+	})
+
+	It("should include about the given fraction of pods", func() {
+		const pods = 20000
+		included := 0
+		for i := range pods {
+			if sampled(types.UID(fmt.Sprintf("%08x-sample-%d", i*7919, i)), 0.1) {
+				included++
+			}
+		}
+		Expect(float64(included) / pods).To(BeNumerically("~", 0.1, 0.01))
+	})
+
+	It("should only record sampled pods", func() {
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{Sinks: []Sink{recorder}, SampleRate: 0.5}
+		recorded := 0
+		for i := range 40 {
+			pod := newRunningPod(fmt.Sprintf("sampled-%d", i))
+			r.Client = nil
+			_, err := reconcilePod(context.Background(), r, pod)
+			Expect(err).NotTo(HaveOccurred())
+			if sampled(pod.UID, r.SampleRate) {
+				recorded++
+			}
+		}
+		Expect(recorded).To(And(BeNumerically(">", 0), BeNumerically("<", 40)))
+		Expect(recorder.Records()).To(HaveLen(recorded))
+	})
+})
+
+var _ = Describe("Record identity", func() {
+	// byRevision identifies pods by name and revision label, so a pod
+	// recreated at the same revision is the same pod.
+	byRevision := func(pod corev1.Pod) string {
+		return pod.Namespace + "/" + pod.Name + "/" + pod.Labels["revision"]
+	}
+
+	// reconcileRecreated reconciles a pod, then a recreation of it under a
+	// new UID, and returns what was recorded.
+	reconcileRecreated := func(r *PodStartupReconciler) []Record {
+		recorder := &recordingSink{}
+		r.Sinks = []Sink{recorder}
+		for _, uid := range []types.UID{"uid-first", "uid-second"} {
+			pod := newRunningPod("recreated")
+			pod.UID = uid
+			pod.Labels = map[string]string{"revision": "7"}
+			r.Client = nil
+			_, err := reconcilePod(context.Background(), r, pod)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		return recorder.Records()
+	}
+
+	It("should key records on the pod UID by default", func() {
+		recs := reconcileRecreated(&PodStartupReconciler{})
+		Expect(recs).To(HaveLen(2))
+		Expect(recs[0]).NotTo(HaveKey("id"))
+	})
+
+	It("should deduplicate records on a custom identity", func() {
+		r := &PodStartupReconciler{IDFunc: byRevision}
+		recs := reconcileRecreated(r)
+		Expect(recs).To(HaveLen(1))
+		Expect(recs[0]).To(HaveKeyWithValue("id", "default/recreated/7"))
+		Expect(recs[0]).To(HaveKeyWithValue("uid", "uid-first"))
+		Expect(r.pods.recorded(types.NamespacedName{Namespace: "default", Name: "recreated"}, "default/recreated/7")).To(BeTrue())
+
+		store := NewRecordStore()
+		store.Put(recs[0])
+		store.Put(Record{"id": "default/recreated/7", "uid": "uid-second"})
+		Expect(store.Len()).To(Equal(1))
+	})
+})
+
+var _ = Describe("Lagging container statuses", func() {
+	It("should requeue a Running pod until its containers are reported", func() {
+		ctx := context.Background()
+		pod := newRunningPod("lagging")
+		statuses := pod.Status.ContainerStatuses
+		pod.Status.ContainerStatuses = nil
+		recorder := &recordingSink{}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build()
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, Sinks: []Sink{recorder}}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(containerStatusLagDelay))
+		Expect(recorder.Records()).To(BeEmpty())
+
+		var existing corev1.Pod
+		Expect(c.Get(ctx, req.NamespacedName, &existing)).To(Succeed())
+		existing.Status.ContainerStatuses = statuses
+		Expect(c.Status().Update(ctx, &existing)).To(Succeed())
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+
+		Expect(recorder.Records()).To(HaveLen(1))
+		rec := recorder.Records()[0]
+		Expect(rec["timestamps"]).To(HaveKeyWithValue("containersStarted", statuses[0].State.Running.StartedAt.Format(time.RFC3339)))
+		Expect(rec["durations"]).To(HaveKeyWithValue("toContainersStarted", "2s"))
+	})
+
+	It("should record without them once the retries run out", func() {
+		pod := newRunningPod("never-reported")
+		pod.Status.ContainerStatuses = nil
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build(),
+			Scheme: scheme.Scheme,
+			Sinks:  []Sink{recorder},
+		}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+		for range containerStatusLagRetries {
+			result, err := r.Reconcile(context.Background(), req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(containerStatusLagDelay))
+		}
+		_, err := r.Reconcile(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("Recording only when complete", func() {
+	var (
+		ctx      context.Context
+		pod      *corev1.Pod
+		ready    []corev1.PodCondition
+		first    time.Time
+		clock    time.Time
+		c        client.Client
+		recorder *recordingSink
+		r        *PodStartupReconciler
+		req      ctrl.Request
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		pod = newRunningPod("slow-to-ready")
+		ready = pod.Status.Conditions
+		pod.Status.Conditions = pod.Status.Conditions[:1] // not ready yet
+		first = time.Now().Truncate(time.Second)
+		clock = first
+		c = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build()
+		recorder = &recordingSink{}
+		r = &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, Sinks: []Sink{recorder},
+			RecordOnlyWhenComplete: true, MaxWait: time.Minute,
+			now: func() time.Time { return clock }}
+		req = ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+	})
+
+	It("should record a pod that becomes ready within the wait", func() {
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(completeWaitBaseDelay))
+		clock = first.Add(time.Second)
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(2*completeWaitBaseDelay), "the delay grows")
+		Expect(recorder.Records()).To(BeEmpty())
+
+		var existing corev1.Pod
+		Expect(c.Get(ctx, req.NamespacedName, &existing)).To(Succeed())
+		existing.Status.Conditions = ready
+		Expect(c.Status().Update(ctx, &existing)).To(Succeed())
+		clock = first.Add(3 * time.Second)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(recorder.Records()).To(HaveLen(1))
+		rec := recorder.Records()[0]
+		Expect(rec).NotTo(HaveKey("incomplete"))
+		Expect(rec["durations"]).To(HaveKey("toReady"))
+	})
+
+	It("should record a pod still incomplete after the wait, marked incomplete", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		clock = first.Add(59500 * time.Millisecond)
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(500*time.Millisecond), "the delay is capped at the remaining wait")
+		Expect(recorder.Records()).To(BeEmpty())
+
+		clock = first.Add(time.Minute)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+		Expect(recorder.Records()[0]).To(HaveKeyWithValue("incomplete", true))
+	})
+})
+
+var _ = Describe("Concurrent reconciles", func() {
+	It("should default to a single worker", func() {
+		Expect((&PodStartupReconciler{}).controllerOptions().MaxConcurrentReconciles).To(Equal(1))
+		Expect((&PodStartupReconciler{MaxConcurrentReconciles: 8}).controllerOptions().MaxConcurrentReconciles).To(Equal(8))
+	})
+
+	It("should record every pod when reconciled by several workers", func() {
+		const workers, pods = 8, 64
+		dir := GinkgoT().TempDir()
+		single := &FileSink{Path: filepath.Join(dir, "records.json")}
+		partitioned := &FileSink{Dir: filepath.Join(dir, "by-namespace")}
+		Expect(os.Mkdir(partitioned.Dir, 0755)).To(Succeed())
+
+		builder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+		var reqs []ctrl.Request
+		for i := range pods {
+			pod := newRunningPod(fmt.Sprintf("concurrent-%d", i))
+			pod.Namespace = fmt.Sprintf("team-%d", i%4)
+			builder = builder.WithObjects(pod)
+			reqs = append(reqs, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)})
+		}
+		r := &PodStartupReconciler{
+			Client: builder.Build(), Scheme: scheme.Scheme,
+			Sinks:                   []Sink{single, partitioned},
+			MaxConcurrentReconciles: workers,
+		}
+
+		// Like the controller's workers, each pod is only handled by one
+		// worker at a time but different pods run in parallel
+		queue := make(chan ctrl.Request, len(reqs))
+		for _, req := range reqs {
+			queue <- req
+		}
+		close(queue)
+		var wg sync.WaitGroup
+		for range r.controllerOptions().MaxConcurrentReconciles {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for req := range queue {
+					_, err := r.Reconcile(context.Background(), req)
+					Expect(err).NotTo(HaveOccurred())
+				}
+			}()
+		}
+		wg.Wait()
+
+		Expect(ReadRecords(single.Path)).To(HaveLen(pods))
+		files, err := partitioned.files()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(4))
+		total := 0
+		for _, path := range files {
+			records, err := ReadRecords(path)
+			Expect(err).NotTo(HaveOccurred())
+			total += len(records)
+		}
+		Expect(total).To(Equal(pods))
+	})
+})
+
+// podLike stands in for a resource exposing pods through another type.
+type podLike struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+func (p *podLike) DeepCopyObject() runtime.Object {
+	out := *p
+	p.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return &out
+}
+
+var _ = Describe("Watched type", func() {
+	newManager := func(s *runtime.Scheme) ctrl.Manager {
+		// Nothing is started, so the API server is never contacted
+		mgr, err := ctrl.NewManager(&rest.Config{Host: "http://127.0.0.1:1"}, ctrl.Options{
+			Scheme:                 s,
+			Metrics:                metricsserver.Options{BindAddress: "0"},
+			HealthProbeBindAddress: "0",
+			Controller:             config.Controller{SkipNameValidation: ptr.To(true)},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return mgr
+	}
+
+	It("should set up a controller for an injected type", func() {
+		s := runtime.NewScheme()
+		Expect(corev1.AddToScheme(s)).To(Succeed())
+		s.AddKnownTypes(schema.GroupVersion{Group: "example.com", Version: "v1"}, &podLike{})
+		mgr := newManager(s)
+
+		r := &PodStartupReconciler{Client: mgr.GetClient(), Scheme: s, Sinks: []Sink{&recordingSink{}}}
+		Expect(r.SetupWithManagerFor(mgr, &podLike{})).To(Succeed())
+	})
+
+	It("should reject a type missing from the scheme", func() {
+		s := runtime.NewScheme()
+		Expect(corev1.AddToScheme(s)).To(Succeed())
+		mgr := newManager(s)
+
+		r := &PodStartupReconciler{Client: mgr.GetClient(), Scheme: s, Sinks: []Sink{&recordingSink{}}}
+		Expect(r.SetupWithManagerFor(mgr, &podLike{})).To(MatchError(ContainSubstring("no kind is registered")))
+	})
+})
@@ -0,0 +1,157 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// ignoreAgedTerminal filters out events for terminal pods that finished more
+// than TerminalIgnoreAge ago and have already been recorded, such as the pods
+// of long completed Jobs. Deletes always pass so their state is forgotten.
+func (r *PodStartupReconciler) ignoreAgedTerminal() predicate.Predicate {
+	keep := func(obj client.Object) bool {
+		pod, ok := obj.(*corev1.Pod)
+		return !ok || !r.agedOutTerminal(*pod)
+	}
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return keep(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return keep(e.ObjectNew) },
+		DeleteFunc:  func(event.DeleteEvent) bool { return true },
+		GenericFunc: func(e event.GenericEvent) bool { return keep(e.Object) },
+	}
+}
+
+// agedOutTerminal reports whether the pod reached a terminal phase more than
+// TerminalIgnoreAge ago and its state has been recorded at least once. Pods
+// without a terminal time are aged from their creation.
+func (r *PodStartupReconciler) agedOutTerminal(pod corev1.Pod) bool {
+	if r.TerminalIgnoreAge <= 0 {
+		return false
+	}
+	if !isTerminal(pod) {
+		return false
+	}
+	finished := getTerminalTime(pod, pod.Status.Phase)
+	if finished.IsZero() {
+		finished = pod.CreationTimestamp.Time
+	}
+	if time.Since(finished) <= r.TerminalIgnoreAge {
+		return false
+	}
+	return r.pods.recorded(client.ObjectKeyFromObject(&pod), r.recordID(pod))
+}
+
+// ignoreTimingNoise filters out pod updates that change nothing the recorded
+// timings depend on, such as a new pod IP or a refreshed probe time, which
+// would otherwise each cost a reconcile. Changes outside the status always
+// pass, as do updates of objects other than pods.
+func ignoreTimingNoise() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			newPod, newOK := e.ObjectNew.(*corev1.Pod)
+			if !ok || !newOK {
+				return true
+			}
+			return timingChanged(oldPod, newPod)
+		},
+	}
+}
+
+// timingChanged reports whether the pod changed in a way that can affect its
+// record: anything in its metadata or spec, or the status fields timings and
+// reasons are derived from.
+func timingChanged(old, updated *corev1.Pod) bool {
+	oldMeta, newMeta := old.ObjectMeta.DeepCopy(), updated.ObjectMeta.DeepCopy()
+	oldMeta.ResourceVersion, newMeta.ResourceVersion = "", ""
+	oldMeta.ManagedFields, newMeta.ManagedFields = nil, nil
+	if !equality.Semantic.DeepEqual(oldMeta, newMeta) || !equality.Semantic.DeepEqual(old.Spec, updated.Spec) {
+		return true
+	}
+	return !equality.Semantic.DeepEqual(timingStatus(old.Status), timingStatus(updated.Status))
+}
+
+// podTimingStatus is the part of a pod's status that records depend on.
+type podTimingStatus struct {
+	Phase             corev1.PodPhase
+	Reason            string
+	NominatedNodeName string
+	StartTime         *metav1.Time
+	QOSClass          corev1.PodQOSClass
+	Conditions        []conditionTiming
+	Containers        []containerTiming
+}
+
+// conditionTiming is a pod condition without its message and probe time.
+type conditionTiming struct {
+	Type               corev1.PodConditionType
+	Status             corev1.ConditionStatus
+	Reason             string
+	LastTransitionTime metav1.Time
+}
+
+// containerTiming is the part of a container status records depend on.
+type containerTiming struct {
+	Name                 string
+	Ready                bool
+	Started              *bool
+	RestartCount         int32
+	State                corev1.ContainerState
+	LastTerminationState corev1.ContainerState
+}
+
+// timingStatus picks the podTimingStatus out of a pod's status.
+func timingStatus(status corev1.PodStatus) podTimingStatus {
+	t := podTimingStatus{
+		Phase:             status.Phase,
+		Reason:            status.Reason,
+		NominatedNodeName: status.NominatedNodeName,
+		StartTime:         status.StartTime,
+		QOSClass:          status.QOSClass,
+	}
+	for _, c := range status.Conditions {
+		t.Conditions = append(t.Conditions, conditionTiming{
+			Type:               c.Type,
+			Status:             c.Status,
+			Reason:             c.Reason,
+			LastTransitionTime: c.LastTransitionTime,
+		})
+	}
+	for _, statuses := range [][]corev1.ContainerStatus{
+		status.InitContainerStatuses, status.ContainerStatuses, status.EphemeralContainerStatuses,
+	} {
+		for _, c := range statuses {
+			t.Containers = append(t.Containers, containerTiming{
+				Name:                 c.Name,
+				Ready:                c.Ready,
+				Started:              c.Started,
+				RestartCount:         c.RestartCount,
+				State:                c.State,
+				LastTerminationState: c.LastTerminationState,
+			})
+		}
+	}
+	return t
+}
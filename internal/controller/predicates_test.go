@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("TerminalIgnoreAge", func() {
+	succeededPod := func(name string, finished time.Time) *corev1.Pod {
+		pod := newRunningPod(name)
+		pod.CreationTimestamp = metav1.NewTime(finished.Add(-time.Minute))
+		pod.Status.Phase = corev1.PodSucceeded
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name: "c1",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				StartedAt:  metav1.NewTime(finished.Add(-30 * time.Second)),
+				FinishedAt: metav1.NewTime(finished),
+			}},
+		}}
+		return pod
+	}
+
+	It("should record an old terminal pod once and then skip it", func() {
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{Sinks: []Sink{recorder}, TerminalIgnoreAge: time.Hour}
+		filter := r.ignoreAgedTerminal()
+		old := succeededPod("old-job", time.Now().Add(-2*time.Hour))
+
+		Expect(filter.Create(event.CreateEvent{Object: old})).To(BeTrue(), "not recorded yet")
+		_, err := reconcilePod(context.Background(), r, old)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+
+		Expect(filter.Create(event.CreateEvent{Object: old})).To(BeFalse())
+		Expect(filter.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: old})).To(BeFalse())
+		Expect(filter.Delete(event.DeleteEvent{Object: old})).To(BeTrue())
+
+		recreated := old.DeepCopy()
+		recreated.UID = types.UID("uid-old-job-2")
+		Expect(filter.Create(event.CreateEvent{Object: recreated})).To(BeTrue(), "a new pod under the same name")
+	})
+
+	It("should keep processing recently finished pods", func() {
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{Sinks: []Sink{recorder}, TerminalIgnoreAge: time.Hour}
+		fresh := succeededPod("fresh-job", time.Now().Add(-time.Minute))
+
+		_, err := reconcilePod(context.Background(), r, fresh)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+		Expect(r.ignoreAgedTerminal().Update(event.UpdateEvent{ObjectOld: fresh, ObjectNew: fresh})).To(BeTrue())
+	})
+
+	It("should never filter when unset", func() {
+		r := &PodStartupReconciler{}
+		old := succeededPod("unset-job", time.Now().Add(-48*time.Hour))
+		r.pods.markRecorded(types.NamespacedName{Namespace: old.Namespace, Name: old.Name}, old.UID, "1")
+		Expect(r.agedOutTerminal(*old)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Timing noise filter", func() {
+	filter := ignoreTimingNoise()
+
+	passes := func(change func(*corev1.Pod)) bool {
+		old := newRunningPod("noisy")
+		old.ResourceVersion = "1"
+		updated := old.DeepCopy()
+		updated.ResourceVersion = "2"
+		change(updated)
+		return filter.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated})
+	}
+
+	It("should filter status updates that leave the timings alone", func() {
+		Expect(passes(func(*corev1.Pod) {})).To(BeFalse())
+		Expect(passes(func(pod *corev1.Pod) {
+			pod.Status.PodIP = "10.0.0.7"
+			pod.Status.Message = "still fine"
+			for i := range pod.Status.Conditions {
+				pod.Status.Conditions[i].LastProbeTime = metav1.Now()
+				pod.Status.Conditions[i].Message = "probed"
+			}
+			pod.Status.ContainerStatuses[0].ImageID = "sha256:abc"
+		})).To(BeFalse())
+	})
+
+	It("should pass changes the timings depend on", func() {
+		Expect(passes(func(pod *corev1.Pod) { pod.Status.Phase = corev1.PodSucceeded })).To(BeTrue())
+		Expect(passes(func(pod *corev1.Pod) {
+			pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{
+				Type: corev1.PodInitialized, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now(),
+			})
+		})).To(BeTrue())
+		Expect(passes(func(pod *corev1.Pod) {
+			pod.Status.ContainerStatuses[0].State = corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				ExitCode: 1, FinishedAt: metav1.Now(),
+			}}
+		})).To(BeTrue())
+		Expect(passes(func(pod *corev1.Pod) { pod.Status.ContainerStatuses[0].Ready = false })).To(BeTrue())
+	})
+
+	It("should pass changes outside the status and other objects", func() {
+		Expect(passes(func(pod *corev1.Pod) {
+			now := metav1.Now()
+			pod.DeletionTimestamp = &now
+		})).To(BeTrue())
+		Expect(passes(func(pod *corev1.Pod) { pod.Labels = map[string]string{"team": "a"} })).To(BeTrue())
+
+		other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm"}}
+		Expect(filter.Update(event.UpdateEvent{ObjectOld: other, ObjectNew: other})).To(BeTrue())
+		Expect(filter.Create(event.CreateEvent{Object: newRunningPod("created")})).To(BeTrue())
+	})
+})
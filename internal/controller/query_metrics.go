@@ -0,0 +1,77 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// newAggregatesMetricsHandler serves GET /aggregates/metrics: the pod count
+// and time to ready of the stored records per namespace and per node, in the
+// Prometheus text format. The gauges are built from the store on every
+// request and never registered with the manager's registry, as a label per
+// node or namespace can be too many series for it.
+func newAggregatesMetricsHandler(store *RecordStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		registry := prometheus.NewRegistry()
+		records := store.List()
+		for _, label := range []string{"namespace", "node"} {
+			registerAggregates(registry, label, records)
+		}
+		promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, req)
+	})
+}
+
+// registerAggregates registers gauges of the records grouped by the value of
+// the named record field, which is also the label name.
+func registerAggregates(registry *prometheus.Registry, label string, records []Record) {
+	pods := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pod_startup_" + label + "_pods",
+		Help: "Number of pods recorded, by " + label + ".",
+	}, []string{label})
+	mean := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pod_startup_" + label + "_to_ready_mean_seconds",
+		Help: "Mean time from pod creation to the Ready condition, by " + label + ".",
+	}, []string{label})
+	median := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pod_startup_" + label + "_to_ready_median_seconds",
+		Help: "Median time from pod creation to the Ready condition, by " + label + ".",
+	}, []string{label})
+	registry.MustRegister(pods, mean, median)
+
+	count := map[string]int{}
+	toReady := map[string][]time.Duration{}
+	for _, rec := range records {
+		value := recordString(rec, label)
+		count[value]++
+		if d, ok := recordDuration(rec, "toReady"); ok {
+			toReady[value] = append(toReady[value], d)
+		}
+	}
+	for value, n := range count {
+		pods.WithLabelValues(value).Set(float64(n))
+	}
+	for value, ds := range toReady {
+		stats := durationStats(ds)
+		mean.WithLabelValues(value).Set(stats.MeanSeconds)
+		median.WithLabelValues(value).Set(stats.MedianSeconds)
+	}
+}
@@ -0,0 +1,436 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// QuerySocketMode is the permission of the query socket, which only the
+// controller's own user, such as a sidecar running as it, may connect to.
+const QuerySocketMode fs.FileMode = 0600
+
+// Summary is the body of GET /summary.
+type Summary struct {
+	// TotalPods is the number of pods recorded.
+	TotalPods int `json:"totalPods"`
+	// Phases counts the recorded pods by their latest phase.
+	Phases map[string]int `json:"phases"`
+	// ToReady aggregates the time to ready of the pods that became ready.
+	ToReady DurationStats `json:"toReady"`
+}
+
+// DurationStats aggregates a set of durations.
+type DurationStats struct {
+	Count         int     `json:"count"`
+	MeanSeconds   float64 `json:"meanSeconds"`
+	MedianSeconds float64 `json:"medianSeconds"`
+}
+
+// Page sizes of GET /pods.
+const (
+	DefaultPageLimit = 100
+	MaxPageLimit     = 1000
+)
+
+// PodPage is the body of GET /pods.
+type PodPage struct {
+	// Items is the page of records, ordered by namespace, pod name and UID.
+	Items []Record `json:"items"`
+	// Total is the number of records across all pages.
+	Total int `json:"total"`
+	// Next is the offset of the following page, absent on the last page.
+	Next *int `json:"next,omitempty"`
+}
+
+// newQueryHandler serves read-only views of the store.
+func newQueryHandler(store *RecordStore) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /summary", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, summarize(store.List()))
+	})
+	mux.Handle("GET /pods", newPodsHandler(store, nil))
+	mux.Handle("GET /aggregates/metrics", newAggregatesMetricsHandler(store))
+	return mux
+}
+
+// newPodsHandler serves GET /pods. The namespace, createdFrom and createdTo
+// parameters, the latter two RFC3339 times bounding the creation time as in
+// BoltQuery, are answered from bolt when it is set and by filtering the
+// store otherwise.
+func newPodsHandler(store *RecordStore, bolt *BoltSink) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		limit, err := queryInt(req, "limit", DefaultPageLimit)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		offset, err := queryInt(req, "offset", 0)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q := BoltQuery{Namespace: req.URL.Query().Get("namespace")}
+		if q.CreatedFrom, err = queryTime(req, "createdFrom"); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if q.CreatedTo, err = queryTime(req, "createdTo"); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var records []Record
+		if bolt != nil && (q.Namespace != "" || q.ranged()) {
+			if records, err = bolt.Query(req.Context(), q); err != nil {
+				logf.FromContext(req.Context()).Error(err, "Failed to query records")
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		} else {
+			records = slices.DeleteFunc(store.List(), func(rec Record) bool { return !q.matches(rec) })
+		}
+		writeJSON(w, paginate(records, offset, min(max(limit, 1), MaxPageLimit)))
+	}
+}
+
+// ResetConfirmParam is the query parameter POST /reset must carry, set to
+// "true", so the data isn't wiped by accident.
+const ResetConfirmParam = "confirm"
+
+// newResetHandler serves POST /reset, which calls reset for requests bearing
+// token as their bearer token.
+func newResetHandler(token string, reset func(context.Context) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		bearer, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if req.URL.Query().Get(ResetConfirmParam) != "true" {
+			http.Error(w, "resetting wipes every record, set "+ResetConfirmParam+"=true to confirm", http.StatusBadRequest)
+			return
+		}
+		if err := reset(req.Context()); err != nil {
+			logf.FromContext(req.Context()).Error(err, "Failed to reset records")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// queryInt parses the named non-negative integer query parameter, returning
+// def when it is absent.
+func queryInt(req *http.Request, name string, def int) (int, error) {
+	value := req.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", name, value)
+	}
+	return n, nil
+}
+
+// queryTime parses the named RFC3339 query parameter, returning the zero
+// time when it is absent.
+func queryTime(req *http.Request, name string) (time.Time, error) {
+	value := req.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC3339 time, got %q", name, value)
+	}
+	return t, nil
+}
+
+// paginate sorts records into a stable order and returns the page of limit
+// records starting at offset. An offset past the end yields an empty page.
+func paginate(records []Record, offset, limit int) PodPage {
+	slices.SortFunc(records, func(a, b Record) int {
+		return cmp.Or(
+			cmp.Compare(recordString(a, "namespace"), recordString(b, "namespace")),
+			cmp.Compare(recordString(a, "pod"), recordString(b, "pod")),
+			cmp.Compare(recordString(a, "uid"), recordString(b, "uid")),
+		)
+	})
+
+	page := PodPage{Items: []Record{}, Total: len(records)}
+	if offset >= len(records) {
+		return page
+	}
+	end := min(offset+limit, len(records))
+	page.Items = records[offset:end]
+	if end < len(records) {
+		page.Next = &end
+	}
+	return page
+}
+
+// summarize computes the Summary of the given records.
+func summarize(records []Record) Summary {
+	summary := Summary{TotalPods: len(records), Phases: map[string]int{}}
+
+	var toReady []time.Duration
+	for _, rec := range records {
+		summary.Phases[recordString(rec, "phase")]++
+		if d, ok := recordDuration(rec, "toReady"); ok {
+			toReady = append(toReady, d)
+		}
+	}
+	summary.ToReady = durationStats(toReady)
+	return summary
+}
+
+// durationStats computes the mean in a single pass and the median by
+// selection, so neither needs the durations sorted. ds is reordered.
+func durationStats(ds []time.Duration) DurationStats {
+	stats := DurationStats{Count: len(ds)}
+	if len(ds) == 0 {
+		return stats
+	}
+
+	var sum time.Duration
+	for _, d := range ds {
+		sum += d
+	}
+	stats.MeanSeconds = sum.Seconds() / float64(len(ds))
+
+	mid := len(ds) / 2
+	median := selectNth(ds, mid)
+	if len(ds)%2 == 0 {
+		// The lower middle is the largest of what selection left below mid
+		lower := ds[0]
+		for _, d := range ds[:mid] {
+			lower = max(lower, d)
+		}
+		median = (lower + median) / 2
+	}
+	stats.MedianSeconds = median.Seconds()
+	return stats
+}
+
+// selectNth partially reorders ds so that ds[n] is the value it would hold if
+// sorted, with smaller values before it, and returns it.
+func selectNth(ds []time.Duration, n int) time.Duration {
+	lo, hi := 0, len(ds)-1
+	for lo < hi {
+		pivot := ds[(lo+hi)/2]
+		i, j := lo, hi
+		for i <= j {
+			for ds[i] < pivot {
+				i++
+			}
+			for ds[j] > pivot {
+				j--
+			}
+			if i <= j {
+				ds[i], ds[j] = ds[j], ds[i]
+				i++
+				j--
+			}
+		}
+		switch {
+		case n <= j:
+			hi = j
+		case n >= i:
+			lo = i
+		default:
+			return ds[n]
+		}
+	}
+	return ds[n]
+}
+
+// writeJSON writes v as the JSON response body.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// queryServerRunnable serves the query API for the lifetime of the manager.
+type queryServerRunnable struct {
+	network string
+	addr    string
+	server  *http.Server
+	mux     *http.ServeMux
+
+	// tlsConfig, when set, serves over TLS.
+	tlsConfig *tls.Config
+}
+
+// newQueryServerRunnable serves the query API from store. POST /reset is
+// only served when resetToken is set.
+func newQueryServerRunnable(addr string, store *RecordStore, resetToken string, reset func(context.Context) error) *queryServerRunnable {
+	mux := http.NewServeMux()
+	mux.Handle("/", newQueryHandler(store))
+	if resetToken != "" {
+		mux.Handle("POST /reset", newResetHandler(resetToken, reset))
+	}
+	return &queryServerRunnable{
+		network: "tcp",
+		addr:    addr,
+		server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		mux: mux,
+	}
+}
+
+// newQuerySocketRunnable serves the read-only query API from store on a
+// Unix socket at path. POST /reset is never served on it.
+func newQuerySocketRunnable(path string, store *RecordStore) *queryServerRunnable {
+	mux := http.NewServeMux()
+	mux.Handle("/", newQueryHandler(store))
+	return &queryServerRunnable{
+		network: "unix",
+		addr:    path,
+		server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		mux: mux,
+	}
+}
+
+// serveDownload adds GET /download of the file sink's records.
+func (q *queryServerRunnable) serveDownload(sink *FileSink) {
+	q.mux.Handle("GET /download", newDownloadHandler(sink))
+}
+
+// serveBolt answers the filters of GET /pods from the bolt sink's indexes
+// rather than by scanning the store.
+func (q *queryServerRunnable) serveBolt(store *RecordStore, sink *BoltSink) {
+	q.mux.Handle("GET /pods", newPodsHandler(store, sink))
+}
+
+// Start implements manager.Runnable.
+func (q *queryServerRunnable) Start(ctx context.Context) error {
+	logger := logf.FromContext(ctx)
+
+	if q.network == "unix" {
+		// A socket left behind by a previous run would stop the listen
+		if err := removeSocket(q.addr); err != nil {
+			return err
+		}
+	}
+	var lis net.Listener
+	var err error
+	if q.network == "unix" {
+		lis, err = listenSocket(q.addr)
+		if err != nil {
+			return err
+		}
+		defer removeSocket(q.addr) //nolint:errcheck
+	} else if lis, err = net.Listen(q.network, q.addr); err != nil {
+		return fmt.Errorf("listening on %s: %w", q.addr, err)
+	}
+	if q.tlsConfig != nil {
+		lis = tls.NewListener(lis, q.tlsConfig)
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = q.server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("Starting query server", "address", lis.Addr().String())
+	if err := q.server.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// listenSocket listens on a Unix socket at path that no other user can
+// connect to, even briefly. The socket is bound inside a new directory next
+// to path that only the controller's user may enter, set to QuerySocketMode
+// as a backstop, and only then renamed into place. The directory's name
+// counts towards the length limit of socket paths.
+func listenSocket(path string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".query-socket-")
+	if err != nil {
+		return nil, fmt.Errorf("creating directory for %s: %w", path, err)
+	}
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	bound := filepath.Join(dir, "sock")
+	lis, err := net.Listen("unix", bound)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", path, err)
+	}
+	// The listener would otherwise try to remove the path it was bound to
+	lis.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(bound, QuerySocketMode); err != nil {
+		_ = lis.Close()
+		return nil, fmt.Errorf("setting mode of %s: %w", path, err)
+	}
+	if err := os.Rename(bound, path); err != nil {
+		_ = lis.Close()
+		return nil, fmt.Errorf("moving socket to %s: %w", path, err)
+	}
+	return lis, nil
+}
+
+// removeSocket removes the socket at path, if there is one. Anything else at
+// path is left alone and reported, so a misconfigured path can't delete a
+// regular file.
+func removeSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	return os.Remove(path)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Like the
+// gRPC server, it serves on every replica.
+func (q *queryServerRunnable) NeedLeaderElection() bool {
+	return false
+}
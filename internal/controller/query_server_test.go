@@ -0,0 +1,358 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/expfmt"
+)
+
+// storedPod returns a record as Reconcile would build it.
+func storedPod(name, phase, toReady string) Record {
+	durations := map[string]string{}
+	if toReady != "" {
+		durations["toReady"] = toReady
+	}
+	return Record{"pod": name, "namespace": "default", "uid": "uid-" + name, "phase": phase, "durations": durations}
+}
+
+// getJSON issues a GET against the handler and decodes the response into v.
+func getJSON(handler http.Handler, target string, v any) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code == http.StatusOK {
+		Expect(json.Unmarshal(rec.Body.Bytes(), v)).To(Succeed())
+	}
+	return rec
+}
+
+var _ = Describe("Query server", func() {
+	Describe("GET /summary", func() {
+		It("should summarize the stored records", func() {
+			store := NewRecordStore()
+			store.Put(storedPod("a", "Running", "1s"))
+			store.Put(storedPod("b", "Running", "3s"))
+			store.Put(storedPod("c", "Succeeded", "2s"))
+			store.Put(storedPod("d", "Running", "10s"))
+			store.Put(storedPod("e", "Pending", ""))
+			store.Put(storedPod("a", "Succeeded", "1s")) // replaces the earlier record of a
+
+			var summary Summary
+			resp := getJSON(newQueryHandler(store), "/summary", &summary)
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(resp.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			Expect(summary.TotalPods).To(Equal(5))
+			Expect(summary.Phases).To(Equal(map[string]int{"Running": 2, "Succeeded": 2, "Pending": 1}))
+			Expect(summary.ToReady.Count).To(Equal(4))
+			Expect(summary.ToReady.MeanSeconds).To(BeNumerically("~", 4))
+			Expect(summary.ToReady.MedianSeconds).To(BeNumerically("~", 2.5))
+		})
+
+		It("should return zeroes for an empty store", func() {
+			var summary Summary
+			Expect(getJSON(newQueryHandler(NewRecordStore()), "/summary", &summary).Code).To(Equal(http.StatusOK))
+			Expect(summary.TotalPods).To(BeZero())
+			Expect(summary.ToReady).To(Equal(DurationStats{}))
+		})
+
+		It("should include pods recorded by the reconciler", func() {
+			store := NewRecordStore()
+			r := &PodStartupReconciler{Sinks: []Sink{&recordingSink{}}, Store: store}
+			_, err := reconcilePod(context.Background(), r, newRunningPod("summarized"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var summary Summary
+			getJSON(newQueryHandler(store), "/summary", &summary)
+			Expect(summary.TotalPods).To(Equal(1))
+			Expect(summary.ToReady.MedianSeconds).To(BeNumerically("~", 3))
+		})
+	})
+
+	Describe("GET /aggregates/metrics", func() {
+		It("should render the aggregates per namespace and node", func() {
+			store := NewRecordStore()
+			for _, p := range []struct{ name, namespace, node, toReady string }{
+				{"a", "web", "node-1", "1s"},
+				{"b", "web", "node-2", "3s"},
+				{"c", "batch", "node-1", "8s"},
+				{"d", "batch", "node-1", ""},
+			} {
+				rec := storedPod(p.name, "Running", p.toReady)
+				rec["namespace"], rec["node"] = p.namespace, p.node
+				store.Put(rec)
+			}
+			server := httptest.NewServer(newQueryHandler(store))
+			defer server.Close()
+
+			resp, err := http.Get(server.URL + "/aggregates/metrics")
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close() //nolint:errcheck
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/plain"))
+			families, err := (&expfmt.TextParser{}).TextToMetricFamilies(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			gauge := func(name, label, value string) float64 {
+				Expect(families).To(HaveKey(name))
+				for _, m := range families[name].GetMetric() {
+					if m.GetLabel()[0].GetName() == label && m.GetLabel()[0].GetValue() == value {
+						return m.GetGauge().GetValue()
+					}
+				}
+				Fail(fmt.Sprintf("no %s{%s=%q}", name, label, value))
+				return 0
+			}
+			Expect(gauge("pod_startup_namespace_pods", "namespace", "web")).To(Equal(2.0))
+			Expect(gauge("pod_startup_namespace_pods", "namespace", "batch")).To(Equal(2.0))
+			Expect(gauge("pod_startup_namespace_to_ready_mean_seconds", "namespace", "web")).To(Equal(2.0))
+			Expect(gauge("pod_startup_namespace_to_ready_median_seconds", "namespace", "batch")).To(Equal(8.0))
+			Expect(gauge("pod_startup_node_pods", "node", "node-1")).To(Equal(3.0))
+			Expect(gauge("pod_startup_node_to_ready_mean_seconds", "node", "node-1")).To(Equal(4.5))
+			Expect(gauge("pod_startup_node_to_ready_median_seconds", "node", "node-2")).To(Equal(3.0))
+		})
+	})
+
+	Describe("GET /pods", func() {
+		var handler http.Handler
+
+		BeforeEach(func() {
+			store := NewRecordStore()
+			// Put in reverse so the order must come from sorting
+			for i := 249; i >= 0; i-- {
+				store.Put(storedPod(fmt.Sprintf("pod-%03d", i), "Running", "1s"))
+			}
+			handler = newQueryHandler(store)
+		})
+
+		podNames := func(page PodPage) []string {
+			var names []string
+			for _, rec := range page.Items {
+				names = append(names, rec["pod"].(string))
+			}
+			return names
+		}
+
+		It("should return the first page with the default limit", func() {
+			var page PodPage
+			Expect(getJSON(handler, "/pods", &page).Code).To(Equal(http.StatusOK))
+			Expect(page.Total).To(Equal(250))
+			Expect(page.Items).To(HaveLen(DefaultPageLimit))
+			Expect(podNames(page)[0]).To(Equal("pod-000"))
+			Expect(page.Next).To(HaveValue(Equal(DefaultPageLimit)))
+		})
+
+		It("should return a middle page and point at the next one", func() {
+			var page PodPage
+			Expect(getJSON(handler, "/pods?limit=10&offset=120", &page).Code).To(Equal(http.StatusOK))
+			Expect(podNames(page)).To(HaveLen(10))
+			Expect(podNames(page)[0]).To(Equal("pod-120"))
+			Expect(podNames(page)[9]).To(Equal("pod-129"))
+			Expect(page.Next).To(HaveValue(Equal(130)))
+		})
+
+		It("should end without a next cursor", func() {
+			var page PodPage
+			Expect(getJSON(handler, "/pods?limit=100&offset=200", &page).Code).To(Equal(http.StatusOK))
+			Expect(page.Items).To(HaveLen(50))
+			Expect(page.Next).To(BeNil())
+		})
+
+		It("should return an empty page past the end", func() {
+			var page PodPage
+			Expect(getJSON(handler, "/pods?offset=1000", &page).Code).To(Equal(http.StatusOK))
+			Expect(page.Items).To(BeEmpty())
+			Expect(page.Total).To(Equal(250))
+			Expect(page.Next).To(BeNil())
+		})
+
+		It("should clamp the limit and reject invalid parameters", func() {
+			var page PodPage
+			getJSON(handler, "/pods?limit=0", &page)
+			Expect(page.Items).To(HaveLen(1))
+			getJSON(handler, "/pods?limit=100000", &page)
+			Expect(page.Items).To(HaveLen(250))
+
+			Expect(getJSON(handler, "/pods?limit=ten", &page).Code).To(Equal(http.StatusBadRequest))
+			Expect(getJSON(handler, "/pods?offset=-1", &page).Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should filter by namespace and creation time", func() {
+			created := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+			store := NewRecordStore()
+			for i, name := range []string{"early", "inside", "late"} {
+				rec := storedPod(name, "Running", "1s")
+				rec["timestamps"] = map[string]string{"created": fmtTime(created.Add(time.Duration(i) * time.Hour))}
+				store.Put(rec)
+			}
+			other := storedPod("other-namespace", "Running", "1s")
+			other["namespace"] = "kube-system"
+			other["timestamps"] = map[string]string{"created": fmtTime(created.Add(time.Hour))}
+			store.Put(other)
+			handler := newQueryHandler(store)
+
+			var page PodPage
+			target := fmt.Sprintf("/pods?namespace=default&createdFrom=%s&createdTo=%s",
+				fmtTime(created.Add(time.Hour)), fmtTime(created.Add(2*time.Hour)))
+			Expect(getJSON(handler, target, &page).Code).To(Equal(http.StatusOK))
+			Expect(podNames(page)).To(Equal([]string{"inside"}))
+
+			Expect(getJSON(handler, "/pods?createdFrom=yesterday", &page).Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("POST /reset", func() {
+		var (
+			path    string
+			store   *RecordStore
+			handler http.Handler
+		)
+
+		BeforeEach(func() {
+			path = filepath.Join(GinkgoT().TempDir(), "records.json")
+			sink := &FileSink{Path: path}
+			store = NewRecordStore()
+			for _, name := range []string{"a", "b"} {
+				Expect(sink.Write(context.Background(), storedPod(name, "Running", "1s"))).To(Succeed())
+				store.Put(storedPod(name, "Running", "1s"))
+			}
+			r := &PodStartupReconciler{Sinks: []Sink{sink}, Store: store}
+			handler = newQueryServerRunnable("", store, "s3cret", r.ClearStore).server.Handler
+		})
+
+		reset := func(target, token string) int {
+			req := httptest.NewRequest(http.MethodPost, target, nil)
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		It("should clear the store and the file", func() {
+			Expect(reset("/reset?confirm=true", "s3cret")).To(Equal(http.StatusNoContent))
+
+			Expect(store.Len()).To(BeZero())
+			data, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal("[]"))
+			Expect(ReadRecords(path)).To(BeEmpty())
+		})
+
+		It("should require the token and a confirmation", func() {
+			Expect(reset("/reset?confirm=true", "")).To(Equal(http.StatusUnauthorized))
+			Expect(reset("/reset?confirm=true", "wrong")).To(Equal(http.StatusUnauthorized))
+			Expect(reset("/reset", "s3cret")).To(Equal(http.StatusBadRequest))
+
+			Expect(store.Len()).To(Equal(2))
+			Expect(ReadRecords(path)).To(HaveLen(2))
+		})
+
+		It("should not be served without a token", func() {
+			handler = newQueryServerRunnable("", store, "", nil).server.Handler
+			Expect(reset("/reset?confirm=true", "")).To(Equal(http.StatusNotFound))
+			Expect(store.Len()).To(Equal(2))
+		})
+	})
+
+	It("should serve the read-only API on a Unix socket", func() {
+		// Socket paths are limited to about 100 bytes, which GinkgoT's temp
+		// dirs can exceed
+		dir, err := os.MkdirTemp("", "query")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+		path := filepath.Join(dir, "query.sock")
+		store := NewRecordStore()
+		store.Put(storedPod("a", "Running", "1s"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- newQuerySocketRunnable(path, store).Start(ctx) }()
+		Eventually(func() error { _, err := os.Stat(path); return err }).Should(Succeed())
+		if runtime.GOOS != "windows" {
+			info, err := os.Stat(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(QuerySocketMode))
+		}
+		entries, err := os.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1), "the directory the socket was bound in is gone")
+
+		client := &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		}}
+		resp, err := client.Get("http://query/pods")
+		Expect(err).NotTo(HaveOccurred())
+		var page PodPage
+		Expect(json.NewDecoder(resp.Body).Decode(&page)).To(Succeed())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(page.Items).To(HaveLen(1))
+		Expect(page.Items[0]).To(HaveKeyWithValue("pod", "a"))
+
+		resp, err = client.Post("http://query/reset?confirm=true", "", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(resp.StatusCode).NotTo(Equal(http.StatusNoContent))
+		Expect(store.Len()).To(Equal(1))
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		_, err = os.Stat(path)
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+
+	It("should not remove anything but a socket", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "records.json")
+		Expect(os.WriteFile(path, []byte("[]"), 0644)).To(Succeed())
+		err := newQuerySocketRunnable(path, NewRecordStore()).Start(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("is not a socket")))
+		Expect(path).To(BeAnExistingFile())
+	})
+
+	It("should select the same median as sorting", func() {
+		for n := 1; n < 50; n++ {
+			ds := make([]time.Duration, n)
+			for i := range ds {
+				ds[i] = time.Duration(rand.IntN(20)) * time.Second
+			}
+			sorted := slices.Clone(ds)
+			slices.Sort(sorted)
+			want := sorted[n/2]
+			if n%2 == 0 {
+				want = (sorted[n/2-1] + sorted[n/2]) / 2
+			}
+			Expect(durationStats(ds).MedianSeconds).To(Equal(want.Seconds()), "n=%d", n)
+		}
+	})
+})
@@ -0,0 +1,101 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import "time"
+
+// recordString returns a string field of the record, or "" when missing.
+func recordString(rec Record, key string) string {
+	s, _ := rec[key].(string)
+	return s
+}
+
+// recordInt64 returns an integer field of the record, or 0 when missing.
+// Decoded records hold numbers as float64.
+func recordInt64(rec Record, key string) int64 {
+	switch v := rec[key].(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// recordTimestamp returns the named timestamp of the record, or the zero time
+// when it is missing or unparsable. It accepts records built by Reconcile as
+// well as ones decoded from JSON.
+func recordTimestamp(rec Record, name string) time.Time {
+	var value string
+	switch timestamps := rec["timestamps"].(type) {
+	case map[string]string:
+		value = timestamps[name]
+	case map[string]interface{}:
+		value, _ = timestamps[name].(string)
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// recordDuration returns the named duration of the record, and false when it
+// is missing or unparsable. Like recordTimestamp it accepts both built and
+// decoded records.
+func recordDuration(rec Record, name string) (time.Duration, bool) {
+	var value string
+	switch durations := rec["durations"].(type) {
+	case map[string]string:
+		value = durations[name]
+	case map[string]interface{}:
+		value, _ = durations[name].(string)
+	}
+	if value == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+// recordDurations returns every parsable duration of the record by name.
+func recordDurations(rec Record) map[string]time.Duration {
+	var names []string
+	switch durations := rec["durations"].(type) {
+	case map[string]string:
+		for name := range durations {
+			names = append(names, name)
+		}
+	case map[string]interface{}:
+		for name := range durations {
+			names = append(names, name)
+		}
+	}
+
+	ds := map[string]time.Duration{}
+	for _, name := range names {
+		if d, ok := recordDuration(rec, name); ok {
+			ds[name] = d
+		}
+	}
+	return ds
+}
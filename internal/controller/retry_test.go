@@ -0,0 +1,161 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Transient Get failures", func() {
+	pods := schema.GroupResource{Resource: "pods"}
+
+	It("should classify API errors", func() {
+		Expect(isTransient(apierrors.NewServiceUnavailable("down"))).To(BeTrue())
+		Expect(isTransient(apierrors.NewTooManyRequests("slow down", 2))).To(BeTrue())
+		Expect(isTransient(apierrors.NewTimeoutError("timeout", 1))).To(BeTrue())
+		Expect(isTransient(apierrors.NewInternalError(errors.New("boom")))).To(BeTrue())
+
+		Expect(isTransient(nil)).To(BeFalse())
+		Expect(isTransient(apierrors.NewNotFound(pods, "gone"))).To(BeFalse())
+		Expect(isTransient(apierrors.NewForbidden(pods, "denied", errors.New("rbac")))).To(BeFalse())
+	})
+
+	It("should requeue with growing backoff and record once the Get succeeds", func() {
+		pod := newRunningPod("flaky-get")
+		var failuresLeft atomic.Int32
+		failuresLeft.Store(2)
+		c := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(pod).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if _, ok := obj.(*corev1.Pod); ok && failuresLeft.Add(-1) >= 0 {
+						return apierrors.NewServiceUnavailable("apiserver restarting")
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}).
+			Build()
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, Sinks: []Sink{recorder}}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+
+		result, err := r.Reconcile(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(transientRetryBaseDelay))
+
+		result, err = r.Reconcile(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(2 * transientRetryBaseDelay))
+		Expect(recorder.Records()).To(BeEmpty())
+
+		result, err = r.Reconcile(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		Expect(recorder.Records()).To(HaveLen(1))
+	})
+
+	It("should return permanent errors", func() {
+		c := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(context.Context, client.WithWatch, client.ObjectKey, client.Object, ...client.GetOption) error {
+					return apierrors.NewForbidden(pods, "denied", errors.New("rbac"))
+				},
+			}).
+			Build()
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme}
+
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "x"}})
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+	})
+
+	It("should cap the backoff and honour server suggested delays", func() {
+		var b retryBackoff
+		key := client.ObjectKey{Name: "capped"}
+		var last time.Duration
+		for range 10 {
+			last = b.next(key, apierrors.NewServiceUnavailable("down"), 3*time.Second)
+		}
+		Expect(last).To(Equal(3 * time.Second))
+
+		b.reset(key)
+		Expect(b.next(key, apierrors.NewTooManyRequests("slow down", 2), time.Minute)).To(Equal(2 * time.Second))
+	})
+})
+
+var _ = Describe("ClientTimeout", func() {
+	// blockUntilCancelled stands in for a hung API server.
+	blockUntilCancelled := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	It("should abandon a hung Get and requeue the pod", func() {
+		c := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, _ client.WithWatch, _ client.ObjectKey, _ client.Object, _ ...client.GetOption) error {
+					return blockUntilCancelled(ctx)
+				},
+			}).
+			Build()
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, ClientTimeout: 50 * time.Millisecond}
+
+		start := time.Now()
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "hung"}})
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		// Returning the error is what makes the workqueue requeue the pod
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+
+	It("should return a deadline error from a hung write", func() {
+		pod := newRunningPod("hung-patch")
+		c := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(pod).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Patch: func(ctx context.Context, _ client.WithWatch, _ client.Object, _ client.Patch, _ ...client.PatchOption) error {
+					return blockUntilCancelled(ctx)
+				},
+			}).
+			Build()
+		r := &PodStartupReconciler{
+			Client: c, Scheme: scheme.Scheme,
+			Sinks:         []Sink{&recordingSink{}},
+			AnnotatePods:  true,
+			ClientTimeout: 50 * time.Millisecond,
+		}
+
+		_, err := reconcilePod(context.Background(), r, pod)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+})
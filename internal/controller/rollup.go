@@ -0,0 +1,259 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultRollupInterval is the period each rollup entry covers.
+	DefaultRollupInterval = 24 * time.Hour
+
+	// RollupFileName is where rollups are kept, next to the records.
+	RollupFileName = "rollups.json"
+)
+
+// RollupEntry summarizes the pods created within one period.
+type RollupEntry struct {
+	Start      time.Time              `json:"start"`
+	End        time.Time              `json:"end"`
+	Namespaces map[string]RollupStats `json:"namespaces"`
+}
+
+// RollupStats summarizes the pods of one namespace within a period.
+type RollupStats struct {
+	// Count is the number of pods recorded.
+	Count int `json:"count"`
+	// ToReadyCount is how many of them became ready.
+	ToReadyCount int `json:"toReadyCount"`
+	// P50Seconds and P95Seconds are nearest-rank percentiles of the time to
+	// ready, zero when no pod became ready.
+	P50Seconds float64 `json:"p50Seconds"`
+	P95Seconds float64 `json:"p95Seconds"`
+}
+
+// Rollup periodically condenses the records of a FileSink into one compact
+// entry per period, appended to a JSON array at Path. Periods are aligned to
+// Interval in UTC, so the default interval rolls up each calendar day once it
+// has ended. Records are grouped by the creation time of their pod; records
+// arriving for a period after it was rolled up are not counted.
+type Rollup struct {
+	// Sink is the file sink whose records are rolled up.
+	Sink *FileSink
+
+	// Path is the rollup file. Defaults to RollupFileName in the sink's
+	// directory.
+	Path string
+
+	// Interval is the period each entry covers. Defaults to
+	// DefaultRollupInterval.
+	Interval time.Duration
+
+	// Prune removes the raw records of every period once it is rolled up.
+	Prune bool
+
+	now func() time.Time
+}
+
+// Start implements manager.Runnable. It catches up on any ended periods
+// straight away and then once at the end of each period.
+func (r *Rollup) Start(ctx context.Context) error {
+	logger := logf.FromContext(ctx).WithName("rollup")
+	for {
+		if err := r.run(); err != nil {
+			logger.Error(err, "Failed to roll up records")
+		}
+
+		// Periods follow the wall clock, so after a clock step the next
+		// boundary is recomputed from the new time
+		now := r.clock()
+		timer := time.NewTimer(now.Truncate(r.interval()).Add(r.interval()).Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// run rolls up every ended period that has records and no entry yet.
+func (r *Rollup) run() error {
+	files, err := r.Sink.files()
+	if err != nil {
+		return fmt.Errorf("listing record files: %w", err)
+	}
+	var records []Record
+	for _, path := range files {
+		recs, err := r.Sink.read(path)
+		if err != nil {
+			return err
+		}
+		records = append(records, recs...)
+	}
+
+	existing, err := r.read()
+	if err != nil {
+		return err
+	}
+	done := map[time.Time]bool{}
+	for _, entry := range existing {
+		done[entry.Start.UTC()] = true
+	}
+
+	current := r.clock().Truncate(r.interval())
+	byPeriod := map[time.Time][]Record{}
+	for _, rec := range records {
+		created := recordTimestamp(rec, "created")
+		if created.IsZero() {
+			continue
+		}
+		start := created.UTC().Truncate(r.interval())
+		if start.Before(current) && !done[start] {
+			byPeriod[start] = append(byPeriod[start], rec)
+		}
+	}
+	if len(byPeriod) == 0 {
+		return nil
+	}
+
+	starts := make([]time.Time, 0, len(byPeriod))
+	for start := range byPeriod {
+		starts = append(starts, start)
+	}
+	slices.SortFunc(starts, time.Time.Compare)
+	for _, start := range starts {
+		existing = append(existing, rollupPeriod(start, start.Add(r.interval()), byPeriod[start]))
+		done[start] = true
+	}
+	if err := r.write(existing); err != nil {
+		return err
+	}
+
+	if !r.Prune {
+		return nil
+	}
+	for _, path := range files {
+		err := r.Sink.rewrite(path, func(records []Record) []Record {
+			return slices.DeleteFunc(records, func(rec Record) bool {
+				created := recordTimestamp(rec, "created")
+				return !created.IsZero() && done[created.UTC().Truncate(r.interval())]
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("pruning %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// rollupPeriod summarizes the latest record of each pod in records.
+func rollupPeriod(start, end time.Time, records []Record) RollupEntry {
+	latest := map[string]Record{}
+	for _, rec := range records {
+		latest[storeKey(rec)] = rec
+	}
+
+	pods := map[string]int{}
+	toReady := map[string][]time.Duration{}
+	for _, rec := range latest {
+		namespace := recordString(rec, "namespace")
+		pods[namespace]++
+		if d, ok := recordDuration(rec, "toReady"); ok {
+			toReady[namespace] = append(toReady[namespace], d)
+		}
+	}
+
+	entry := RollupEntry{Start: start, End: end, Namespaces: map[string]RollupStats{}}
+	for namespace, count := range pods {
+		ds := toReady[namespace]
+		stats := RollupStats{Count: count, ToReadyCount: len(ds)}
+		if len(ds) > 0 {
+			stats.P50Seconds = nearestRank(ds, 0.50).Seconds()
+			stats.P95Seconds = nearestRank(ds, 0.95).Seconds()
+		}
+		entry.Namespaces[namespace] = stats
+	}
+	return entry
+}
+
+// nearestRank returns the p-th percentile of ds by the nearest-rank method.
+// ds must not be empty and is reordered.
+func nearestRank(ds []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(ds)))) - 1
+	return selectNth(ds, max(rank, 0))
+}
+
+// read returns the rollup entries written so far.
+func (r *Rollup) read() ([]RollupEntry, error) {
+	data, err := os.ReadFile(r.path())
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(data) == 0) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading rollups: %w", err)
+	}
+	var entries []RollupEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", r.path(), err)
+	}
+	return entries, nil
+}
+
+func (r *Rollup) write(entries []RollupEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling rollups: %w", err)
+	}
+	return writeFileAtomic(r.path(), data, false, r.Sink.fileMode())
+}
+
+func (r *Rollup) path() string {
+	if r.Path != "" {
+		return r.Path
+	}
+	dir := r.Sink.Dir
+	if dir == "" {
+		dir = filepath.Dir(r.Sink.Path)
+	}
+	return filepath.Join(dir, RollupFileName)
+}
+
+func (r *Rollup) interval() time.Duration {
+	if r.Interval <= 0 {
+		return DefaultRollupInterval
+	}
+	return r.Interval
+}
+
+func (r *Rollup) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rollup", func() {
+	day1 := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	var sink *FileSink
+	var now time.Time
+	var rollup *Rollup
+
+	// seed writes one record of a pod created at created that became ready
+	// after toReady, or never when toReady is zero.
+	seed := func(namespace, pod string, created time.Time, toReady time.Duration) {
+		durations := map[string]string{}
+		if toReady > 0 {
+			durations["toReady"] = toReady.String()
+		}
+		Expect(sink.Write(context.Background(), Record{
+			"pod": pod, "namespace": namespace, "uid": namespace + "-" + pod,
+			"timestamps": map[string]string{"created": created.Format(time.RFC3339)},
+			"durations":  durations,
+		})).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		sink = &FileSink{Path: filepath.Join(dir, "pod_startup_times.json")}
+		rollup = &Rollup{Sink: sink, now: func() time.Time { return now }}
+
+		for i := 1; i <= 20; i++ {
+			seed("web", fmt.Sprintf("web-%d", i), day1.Add(time.Duration(i)*time.Hour), time.Duration(i)*time.Second)
+		}
+		seed("batch", "job-1", day1.Add(23*time.Hour+59*time.Minute), 0)
+		seed("web", "web-late", day2.Add(time.Minute), 4*time.Second)
+	})
+
+	It("should summarize each ended day once", func() {
+		now = day2.Add(10 * time.Hour)
+		Expect(rollup.run()).To(Succeed())
+
+		entries, err := rollup.read()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1), "the current day is not rolled up yet")
+		Expect(entries[0].Start).To(BeTemporally("==", day1))
+		Expect(entries[0].End).To(BeTemporally("==", day2))
+		Expect(entries[0].Namespaces).To(Equal(map[string]RollupStats{
+			"web":   {Count: 20, ToReadyCount: 20, P50Seconds: 10, P95Seconds: 19},
+			"batch": {Count: 1},
+		}))
+		Expect(filepath.Base(rollup.path())).To(Equal(RollupFileName))
+
+		Expect(rollup.run()).To(Succeed())
+		entries, err = rollup.read()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1), "a day is only rolled up once")
+
+		now = day2.Add(30 * time.Hour)
+		Expect(rollup.run()).To(Succeed())
+		entries, err = rollup.read()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[1].Start).To(BeTemporally("==", day2))
+		Expect(entries[1].Namespaces).To(Equal(map[string]RollupStats{
+			"web": {Count: 1, ToReadyCount: 1, P50Seconds: 4, P95Seconds: 4},
+		}))
+
+		records, err := ReadRecords(sink.Path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(22), "raw records are kept without Prune")
+	})
+
+	It("should count the latest record of each pod", func() {
+		seed("web", "web-1", day1.Add(time.Hour), time.Minute)
+		now = day2
+		Expect(rollup.run()).To(Succeed())
+
+		entries, err := rollup.read()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries[0].Namespaces["web"].Count).To(Equal(20))
+		// web-1 moved from 1s to 60s, shifting the median up by one pod
+		Expect(entries[0].Namespaces["web"].P50Seconds).To(BeNumerically("==", 11))
+	})
+
+	It("should prune the raw records it rolled up", func() {
+		rollup.Prune = true
+		now = day2.Add(10 * time.Hour)
+		Expect(rollup.run()).To(Succeed())
+
+		records, err := ReadRecords(sink.Path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(1))
+		Expect(records[0]["pod"]).To(Equal("web-late"))
+	})
+})
@@ -0,0 +1,187 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	// DefaultS3FlushInterval is how often buffered records are uploaded.
+	DefaultS3FlushInterval = time.Minute
+
+	// DefaultS3MaxBatch is how many buffered records trigger an upload
+	// before the interval passes.
+	DefaultS3MaxBatch = 500
+)
+
+// S3Uploader is the part of *s3.Client the S3Sink uses, so tests can
+// substitute a fake.
+type S3Uploader interface {
+	PutObject(ctx context.Context, in *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// S3Sink batches records and uploads each batch as a JSON Lines object to
+// S3-compatible object storage. Objects are keyed by upload date and time,
+// <prefix>/<yyyy-mm-dd>/<hhmmss>-<instance>-<seq>.jsonl, so a day's records can be
+// listed together. A batch is uploaded every FlushInterval, once it holds
+// MaxBatch records, and on Close.
+type S3Sink struct {
+	// Client uploads the objects.
+	Client S3Uploader
+
+	// Bucket and Prefix select where objects are written.
+	Bucket string
+	Prefix string
+
+	// Instance distinguishes replicas writing under the same prefix.
+	// NewS3Sink sets it to the hostname, which is the pod name.
+	Instance string
+
+	// FlushInterval defaults to DefaultS3FlushInterval.
+	FlushInterval time.Duration
+
+	// MaxBatch defaults to DefaultS3MaxBatch.
+	MaxBatch int
+
+	// MaxBuffered caps the records held while writes fail, dropping the
+	// oldest beyond it. It defaults to batchBufferFactor times MaxBatch.
+	MaxBuffered int
+
+	batch batcher[[]byte]
+	mu    sync.Mutex
+	seq   int
+	now   func() time.Time
+}
+
+// NewS3Sink returns a sink uploading to bucket, with credentials and region
+// taken from the standard AWS chain. A non-empty endpoint overrides the S3
+// endpoint and switches to path-style addressing, as MinIO and most other
+// S3-compatible stores expect.
+func NewS3Sink(ctx context.Context, bucket, prefix, region, endpoint string) (*S3Sink, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if region != "" {
+		opts = append(opts, awsconfig.WithRegion(region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS config: %w", err)
+	}
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+	instance, _ := os.Hostname()
+	return &S3Sink{Client: client, Bucket: bucket, Prefix: prefix, Instance: instance}, nil
+}
+
+// Name implements Sink.
+func (s *S3Sink) Name() string { return "s3" }
+
+// Write implements Sink. It only buffers the record; upload failures are
+// counted on the sink error metric and retried with the next batch.
+func (s *S3Sink) Write(_ context.Context, rec Record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	s.batch.add(line, s.limits())
+	return nil
+}
+
+// Start implements manager.Runnable, uploading batches until ctx is done.
+// The last batch is uploaded by Close.
+func (s *S3Sink) Start(ctx context.Context) error {
+	interval := s.FlushInterval
+	if interval <= 0 {
+		interval = DefaultS3FlushInterval
+	}
+	return s.batch.run(ctx, interval, s.flush, "Failed to upload records", "bucket", s.Bucket)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every
+// replica buffers the records it writes, so every replica must upload them.
+func (s *S3Sink) NeedLeaderElection() bool {
+	return false
+}
+
+// Close implements ClosingSink, uploading whatever is still buffered.
+func (s *S3Sink) Close(ctx context.Context) error {
+	return s.flush(ctx)
+}
+
+// flush uploads the buffered records as one object. On failure the records
+// are put back to be retried with the next batch.
+func (s *S3Sink) flush(ctx context.Context) error {
+	return s.batch.flush(ctx, s.limits(), func(ctx context.Context, lines [][]byte) error {
+		key := s.nextKey()
+		_, err := s.Client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:      aws.String(s.Bucket),
+			Key:         aws.String(key),
+			Body:        bytes.NewReader(append(bytes.Join(lines, []byte("\n")), '\n')),
+			ContentType: aws.String("application/x-ndjson"),
+		})
+		if err != nil {
+			return fmt.Errorf("uploading %s: %w", key, err)
+		}
+		return nil
+	})
+}
+
+// nextKey names the next object. Instance and the sequence number keep keys
+// unique across replicas and within the same second.
+func (s *S3Sink) nextKey() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	t := now().UTC()
+	s.seq++
+	name := fmt.Sprintf("%s-%06d.jsonl", t.Format("150405"), s.seq)
+	if s.Instance != "" {
+		name = fmt.Sprintf("%s-%s-%06d.jsonl", t.Format("150405"), s.Instance, s.seq)
+	}
+	return path.Join(s.Prefix, t.Format(time.DateOnly), name)
+}
+
+// limits returns the batcher configuration of the sink.
+func (s *S3Sink) limits() batchLimits {
+	limits := batchLimits{sink: s.Name(), maxBatch: s.MaxBatch, maxBuffered: s.MaxBuffered}
+	if limits.maxBatch <= 0 {
+		limits.maxBatch = DefaultS3MaxBatch
+	}
+	if limits.maxBuffered <= 0 {
+		limits.maxBuffered = batchBufferFactor * limits.maxBatch
+	}
+	return limits
+}
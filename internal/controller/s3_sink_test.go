@@ -0,0 +1,137 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeS3 keeps every uploaded object in memory, failing while err is set.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	err     error
+}
+
+func (f *fakeS3) PutObject(_ context.Context, in *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	body, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.objects == nil {
+		f.objects = map[string]string{}
+	}
+	f.objects[aws.ToString(in.Bucket)+"/"+aws.ToString(in.Key)] = string(body)
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) Objects() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	objects := map[string]string{}
+	for k, v := range f.objects {
+		objects[k] = v
+	}
+	return objects
+}
+
+// podsIn returns the pod names of a JSON Lines payload, in order.
+func podsIn(payload string) []string {
+	var pods []string
+	for _, line := range strings.Split(strings.TrimSpace(payload), "\n") {
+		var rec Record
+		Expect(json.Unmarshal([]byte(line), &rec)).To(Succeed())
+		pods = append(pods, rec["pod"].(string))
+	}
+	return pods
+}
+
+var _ = Describe("S3Sink", func() {
+	uploadedAt := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+
+	It("should upload a batch as one date-keyed JSON Lines object", func() {
+		client := &fakeS3{}
+		sink := &S3Sink{Client: client, Bucket: "records", Prefix: "cluster-a", Instance: "ctrl-0",
+			now: func() time.Time { return uploadedAt }}
+
+		Expect(sink.Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+		Expect(sink.Write(context.Background(), Record{"pod": "b"})).To(Succeed())
+		Expect(client.Objects()).To(BeEmpty(), "writes are only buffered")
+
+		Expect(sink.Close(context.Background())).To(Succeed())
+		objects := client.Objects()
+		Expect(objects).To(HaveLen(1))
+		Expect(objects).To(HaveKey("records/cluster-a/2025-03-14/092653-ctrl-0-000001.jsonl"))
+		Expect(podsIn(objects["records/cluster-a/2025-03-14/092653-ctrl-0-000001.jsonl"])).To(Equal([]string{"a", "b"}))
+
+		Expect(sink.Close(context.Background())).To(Succeed())
+		Expect(client.Objects()).To(HaveLen(1), "nothing left to upload")
+	})
+
+	It("should upload early once a batch is full", func() {
+		client := &fakeS3{}
+		sink := &S3Sink{Client: client, Bucket: "records", FlushInterval: time.Hour, MaxBatch: 2}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() { _ = sink.Start(ctx) }()
+
+		Expect(sink.Write(ctx, Record{"pod": "a"})).To(Succeed())
+		Consistently(client.Objects, 100*time.Millisecond).Should(BeEmpty())
+		Expect(sink.Write(ctx, Record{"pod": "b"})).To(Succeed())
+		Eventually(client.Objects).Should(HaveLen(1))
+	})
+
+	It("should keep records whose upload failed for the next batch", func() {
+		client := &fakeS3{err: errors.New("slow down")}
+		sink := &S3Sink{Client: client, Bucket: "records", now: func() time.Time { return uploadedAt }}
+		before := testutil.ToFloat64(sinkErrorsTotal.WithLabelValues("s3"))
+
+		Expect(sink.Write(context.Background(), Record{"pod": "a"})).To(Succeed())
+		Expect(sink.Close(context.Background())).To(MatchError(ContainSubstring("slow down")))
+		Expect(testutil.ToFloat64(sinkErrorsTotal.WithLabelValues("s3")) - before).To(BeNumerically("==", 1))
+
+		client.mu.Lock()
+		client.err = nil
+		client.mu.Unlock()
+		Expect(sink.Write(context.Background(), Record{"pod": "b"})).To(Succeed())
+		Expect(sink.Close(context.Background())).To(Succeed())
+
+		objects := client.Objects()
+		Expect(objects).To(HaveLen(1))
+		for _, payload := range objects {
+			Expect(podsIn(payload)).To(Equal([]string{"a", "b"}))
+		}
+	})
+})
@@ -0,0 +1,140 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+)
+
+// ServerTLS configures TLS for the query and lifecycle gRPC servers. The
+// zero value leaves them serving plain text.
+type ServerTLS struct {
+	// CertFile and KeyFile hold the server's PEM certificate and key. Both
+	// or neither must be set. Rotated files are picked up without a
+	// restart.
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+
+	// ClientCAFile, when set, holds the PEM CA bundle client certificates
+	// must chain to, so only clients presenting one are served (mTLS). The
+	// bundle is reloaded when the file changes.
+	ClientCAFile string `json:"clientCAFile,omitempty"`
+}
+
+// Enabled reports whether TLS is configured.
+func (t ServerTLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// Validate reports incomplete settings.
+func (t ServerTLS) Validate() error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return errors.New("certFile and keyFile must be set together")
+	}
+	if t.ClientCAFile != "" && t.CertFile == "" {
+		return errors.New("clientCAFile requires certFile and keyFile")
+	}
+	return nil
+}
+
+// newServerTLSConfig loads the certificate and client CA bundle and returns
+// the TLS config serving them, along with the watcher reloading the
+// certificate, which must be started for rotation to take effect.
+func newServerTLSConfig(t ServerTLS) (*tls.Config, *certwatcher.CertWatcher, error) {
+	if err := t.Validate(); err != nil {
+		return nil, nil, err
+	}
+	watcher, err := certwatcher.New(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading server certificate: %w", err)
+	}
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: watcher.GetCertificate,
+	}
+	if t.ClientCAFile == "" {
+		return config, watcher, nil
+	}
+
+	cas := &caBundle{path: t.ClientCAFile}
+	if _, err := cas.pool(); err != nil {
+		return nil, nil, err
+	}
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		pool, err := cas.pool()
+		if err != nil {
+			return nil, err
+		}
+		perClient := config.Clone()
+		perClient.GetConfigForClient = nil
+		perClient.ClientCAs = pool
+		return perClient, nil
+	}
+	return config, watcher, nil
+}
+
+// caBundle caches the CA pool parsed from a PEM file, parsing it again
+// whenever the file's modification time changes.
+type caBundle struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	cached  *x509.CertPool
+}
+
+// pool returns the current CA pool. Should a changed file fail to parse,
+// the previous pool keeps being used.
+func (b *caBundle) pool() (*x509.CertPool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	info, err := os.Stat(b.path)
+	if err != nil {
+		return b.fallback(fmt.Errorf("reading client CA bundle: %w", err))
+	}
+	if b.cached != nil && info.ModTime().Equal(b.modTime) {
+		return b.cached, nil
+	}
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		return b.fallback(fmt.Errorf("reading client CA bundle: %w", err))
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return b.fallback(fmt.Errorf("no certificates in client CA bundle %s", b.path))
+	}
+	b.cached, b.modTime = pool, info.ModTime()
+	return pool, nil
+}
+
+// fallback returns the cached pool, or err when nothing was loaded yet.
+func (b *caBundle) fallback(err error) (*x509.CertPool, error) {
+	if b.cached == nil {
+		return nil, err
+	}
+	return b.cached, nil
+}
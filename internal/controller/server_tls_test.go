@@ -0,0 +1,250 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+
+	lifecyclev1 "github.com/karthikbhat19/pod-time-measure-controller/api/lifecycle/v1"
+)
+
+// testCA issues certificates for the TLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key signed by the CA, valid for
+// 127.0.0.1 and usable for the given purpose.
+func (ca *testCA) issue(usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "pod-startup-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// clientConfig returns a TLS client config trusting the CA, presenting a
+// client certificate issued by signer unless it is nil.
+func (ca *testCA) clientConfig(signer *testCA) *tls.Config {
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.pem)
+	config := &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	if signer != nil {
+		certPEM, keyPEM := signer.issue(x509.ExtKeyUsageClientAuth)
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		Expect(err).NotTo(HaveOccurred())
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config
+}
+
+var _ = Describe("Server TLS", func() {
+	var (
+		dir string
+		ca  *testCA
+		cfg ServerTLS
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		ca = newTestCA("server-ca")
+		certPEM, keyPEM := ca.issue(x509.ExtKeyUsageServerAuth)
+		cfg = ServerTLS{
+			CertFile: filepath.Join(dir, "tls.crt"),
+			KeyFile:  filepath.Join(dir, "tls.key"),
+		}
+		Expect(os.WriteFile(cfg.CertFile, certPEM, 0o600)).To(Succeed())
+		Expect(os.WriteFile(cfg.KeyFile, keyPEM, 0o600)).To(Succeed())
+	})
+
+	// serve starts an HTTPS server answering 200 behind the config built from
+	// cfg and returns its URL.
+	serve := func() string {
+		config, _, err := newServerTLSConfig(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		server := &http.Server{
+			Handler:           http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}),
+			ReadHeaderTimeout: time.Second,
+		}
+		go func() {
+			_ = server.Serve(tls.NewListener(lis, config))
+		}()
+		DeferCleanup(server.Close)
+		return "https://" + lis.Addr().String()
+	}
+
+	get := func(url string, config *tls.Config) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}, Timeout: 5 * time.Second}
+		resp, err := client.Get(url)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close() //nolint:errcheck
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		return nil
+	}
+
+	It("should serve clients trusting the server certificate", func() {
+		url := serve()
+		Expect(get(url, ca.clientConfig(nil))).To(Succeed())
+
+		_, err := http.Get(url)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should require a client certificate signed by the client CA in mTLS mode", func() {
+		clientCA := newTestCA("client-ca")
+		cfg.ClientCAFile = filepath.Join(dir, "ca.crt")
+		Expect(os.WriteFile(cfg.ClientCAFile, clientCA.pem, 0o600)).To(Succeed())
+		url := serve()
+
+		Expect(get(url, ca.clientConfig(nil))).NotTo(Succeed())
+		Expect(get(url, ca.clientConfig(newTestCA("other-ca")))).NotTo(Succeed())
+		Expect(get(url, ca.clientConfig(clientCA))).To(Succeed())
+	})
+
+	It("should pick up a replaced client CA bundle", func() {
+		oldCA, newCA := newTestCA("old-ca"), newTestCA("new-ca")
+		cfg.ClientCAFile = filepath.Join(dir, "ca.crt")
+		Expect(os.WriteFile(cfg.ClientCAFile, oldCA.pem, 0o600)).To(Succeed())
+		url := serve()
+		Expect(get(url, ca.clientConfig(oldCA))).To(Succeed())
+
+		Expect(os.WriteFile(cfg.ClientCAFile, newCA.pem, 0o600)).To(Succeed())
+		later := time.Now().Add(time.Minute)
+		Expect(os.Chtimes(cfg.ClientCAFile, later, later)).To(Succeed())
+
+		Expect(get(url, ca.clientConfig(newCA))).To(Succeed())
+		Expect(get(url, ca.clientConfig(oldCA))).NotTo(Succeed())
+	})
+
+	It("should keep the previous client CA bundle when the new one is unreadable", func() {
+		cfg.ClientCAFile = filepath.Join(dir, "ca.crt")
+		clientCA := newTestCA("client-ca")
+		Expect(os.WriteFile(cfg.ClientCAFile, clientCA.pem, 0o600)).To(Succeed())
+		url := serve()
+
+		Expect(os.WriteFile(cfg.ClientCAFile, []byte("garbage"), 0o600)).To(Succeed())
+		later := time.Now().Add(time.Minute)
+		Expect(os.Chtimes(cfg.ClientCAFile, later, later)).To(Succeed())
+
+		Expect(get(url, ca.clientConfig(clientCA))).To(Succeed())
+	})
+
+	It("should serve the gRPC API over mTLS", func() {
+		clientCA := newTestCA("client-ca")
+		cfg.ClientCAFile = filepath.Join(dir, "ca.crt")
+		Expect(os.WriteFile(cfg.ClientCAFile, clientCA.pem, 0o600)).To(Succeed())
+		config, _, err := newServerTLSConfig(cfg)
+		Expect(err).NotTo(HaveOccurred())
+
+		runnable := newGRPCServerRunnable("127.0.0.1:0", NewHub(DefaultHubBufferSize), config)
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		go func() {
+			_ = runnable.server.Serve(lis)
+		}()
+		DeferCleanup(runnable.server.Stop)
+
+		dial := func(clientConfig *tls.Config) *grpc.ClientConn {
+			conn, err := grpc.NewClient(lis.Addr().String(),
+				grpc.WithTransportCredentials(credentials.NewTLS(clientConfig)))
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(conn.Close)
+			return conn
+		}
+
+		// The connection only becomes ready once the server accepted the
+		// handshake and sent its preface.
+		trusted := dial(ca.clientConfig(clientCA))
+		trusted.Connect()
+		Eventually(trusted.GetState, 5*time.Second).Should(Equal(connectivity.Ready))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		_, err = lifecyclev1.NewLifecycleServiceClient(dial(ca.clientConfig(nil))).Watch(ctx, &lifecyclev1.WatchRequest{})
+		Expect(err).To(MatchError(ContainSubstring("certificate required")))
+	})
+
+	It("should reject incomplete settings", func() {
+		Expect(ServerTLS{}.Validate()).To(Succeed())
+		Expect(ServerTLS{}.Enabled()).To(BeFalse())
+		Expect(cfg.Validate()).To(Succeed())
+		Expect(cfg.Enabled()).To(BeTrue())
+
+		Expect(ServerTLS{CertFile: "tls.crt"}.Validate()).To(MatchError(ContainSubstring("set together")))
+		Expect(ServerTLS{ClientCAFile: "ca.crt"}.Validate()).To(MatchError(ContainSubstring("requires certFile")))
+
+		cfg.ClientCAFile = filepath.Join(dir, "missing.crt")
+		_, _, err := newServerTLSConfig(cfg)
+		Expect(err).To(MatchError(ContainSubstring("client CA bundle")))
+	})
+})
@@ -0,0 +1,62 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Sink persists the records produced by the reconciler.
+type Sink interface {
+	// Name identifies the sink in logs and metrics.
+	Name() string
+	// Write persists a single record.
+	Write(ctx context.Context, rec Record) error
+}
+
+// ClosingSink is implemented by sinks that buffer records or hold
+// connections, which must be flushed and released on shutdown.
+type ClosingSink interface {
+	Sink
+	// Close flushes anything buffered and releases the sink's resources,
+	// giving up when ctx is done.
+	Close(ctx context.Context) error
+}
+
+// ClearingSink is implemented by sinks that keep the records they were
+// written, so the collected data can be wiped without a restart.
+type ClearingSink interface {
+	Sink
+	// Clear drops every record written so far.
+	Clear(ctx context.Context) error
+}
+
+// dryRunSink stands in for a sink when running with DryRun, logging what
+// would have been written instead of writing it.
+type dryRunSink struct {
+	Sink
+}
+
+// Write implements Sink.
+func (d dryRunSink) Write(ctx context.Context, rec Record) error {
+	jsonData, _ := json.Marshal(rec)
+	logf.FromContext(ctx).Info("Dry run, not writing record", "sink", d.Name(), "json", string(jsonData))
+	return nil
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// recordingSink keeps every record written to it in memory.
+type recordingSink struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Write(_ context.Context, rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, rec)
+	return nil
+}
+
+func (s *recordingSink) Records() []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Record(nil), s.records...)
+}
+
+// newRunningPod returns a scheduled, ready pod created a few seconds ago.
+func newRunningPod(name string) *corev1.Pod {
+	now := time.Now().Truncate(time.Second)
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "default", UID: types.UID("uid-" + name),
+			CreationTimestamp: metav1.NewTime(now.Add(-3 * time.Second)),
+		},
+		Spec: corev1.PodSpec{
+			NodeName:   "fake-node",
+			Containers: []corev1.Container{{Name: "c1", Image: "busybox"}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(now.Add(-2 * time.Second))},
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(now)},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "c1",
+				Ready: true,
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(now.Add(-time.Second))}},
+			}},
+		},
+	}
+}
+
+// reconcilePod runs a single reconcile of the pod against a fake client.
+func reconcilePod(ctx context.Context, r *PodStartupReconciler, pod *corev1.Pod) (ctrl.Result, error) {
+	if r.Client == nil {
+		r.Client = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build()
+		r.Scheme = scheme.Scheme
+	}
+	return r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)})
+}
+
+var _ = Describe("Dry run", func() {
+	It("should log records without writing them", func() {
+		var logs strings.Builder
+		ctx := logf.IntoContext(context.Background(), funcr.New(func(prefix, args string) {
+			logs.WriteString(args + "\n")
+		}, funcr.Options{}))
+
+		path := filepath.Join(GinkgoT().TempDir(), "out.json")
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{
+			Sinks:  []Sink{&FileSink{Path: path}, recorder},
+			DryRun: true,
+		}
+
+		_, err := reconcilePod(ctx, r, newRunningPod("dry-run-pod"))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = os.Stat(path)
+		Expect(os.IsNotExist(err)).To(BeTrue(), "dry run must not create the file")
+		Expect(recorder.Records()).To(BeEmpty())
+		Expect(logs.String()).To(ContainSubstring("Dry run, not writing record"))
+		Expect(logs.String()).To(ContainSubstring(`\"pod\":\"dry-run-pod\"`))
+	})
+})
+
+// failingSink rejects every write.
+type failingSink struct{}
+
+func (failingSink) Name() string { return "failing" }
+
+func (failingSink) Write(context.Context, Record) error { return errors.New("disk full") }
+
+var _ = Describe("Sink failures", func() {
+	It("should count failures without requeueing by default", func() {
+		before := testutil.ToFloat64(sinkErrorsTotal.WithLabelValues("failing"))
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{Sinks: []Sink{failingSink{}, recorder}}
+
+		_, err := reconcilePod(context.Background(), r, newRunningPod("soft-fail"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(testutil.ToFloat64(sinkErrorsTotal.WithLabelValues("failing"))).To(Equal(before + 1))
+		Expect(recorder.Records()).To(HaveLen(1), "other sinks still receive the record")
+	})
+
+	It("should return the error under FailHard", func() {
+		before := testutil.ToFloat64(sinkErrorsTotal.WithLabelValues("failing"))
+		r := &PodStartupReconciler{Sinks: []Sink{failingSink{}}, FailHard: true}
+
+		_, err := reconcilePod(context.Background(), r, newRunningPod("hard-fail"))
+		Expect(err).To(MatchError(ContainSubstring("disk full")))
+		Expect(testutil.ToFloat64(sinkErrorsTotal.WithLabelValues("failing"))).To(Equal(before + 1))
+	})
+
+	It("should cap the retry backoff", func() {
+		limiter := newRateLimiter(time.Second)
+		req := ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "retried"}}
+
+		var last time.Duration
+		for range 20 {
+			last = limiter.When(req)
+		}
+		Expect(last).To(Equal(time.Second))
+	})
+})
+
+// closingSink records whether it was closed.
+type closingSink struct {
+	recordingSink
+	closed chan struct{}
+	err    error
+}
+
+func newClosingSink() *closingSink { return &closingSink{closed: make(chan struct{})} }
+
+func (s *closingSink) Close(context.Context) error {
+	close(s.closed)
+	return s.err
+}
+
+var _ = Describe("Shutdown", func() {
+	It("should close sinks when the manager context is cancelled", func() {
+		closer := newClosingSink()
+		r := &PodStartupReconciler{Sinks: []Sink{&recordingSink{}, closer}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- r.closeOnShutdown(ctx) }()
+		Consistently(closer.closed, 100*time.Millisecond).ShouldNot(BeClosed())
+
+		cancel()
+		Eventually(closer.closed).Should(BeClosed())
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("should flush debounced writes before closing", func() {
+		closer := newClosingSink()
+		r := &PodStartupReconciler{Sinks: []Sink{closer}, DebounceWindow: time.Hour}
+		_, err := reconcilePod(context.Background(), r, newRunningPod("closing"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(closer.Records()).To(BeEmpty())
+
+		Expect(r.Close(context.Background())).To(Succeed())
+		Expect(closer.Records()).To(HaveLen(1))
+		Expect(closer.closed).To(BeClosed())
+	})
+
+	It("should close dry-run sinks and report close errors", func() {
+		closer := newClosingSink()
+		closer.err = errors.New("connection reset")
+		r := &PodStartupReconciler{Sinks: []Sink{closer}, DryRun: true}
+
+		Expect(r.Close(context.Background())).To(MatchError(ContainSubstring("connection reset")))
+		Expect(closer.closed).To(BeClosed())
+	})
+})
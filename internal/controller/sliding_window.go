@@ -0,0 +1,78 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultToReadyWindow is how far back the windowed time to ready p99 looks.
+const DefaultToReadyWindow = 15 * time.Minute
+
+// windowSample is one observation of a slidingQuantile.
+type windowSample struct {
+	// at is from the reconciler's clock. With time.Now its monotonic
+	// reading keeps samples in order and expiring on time across clock
+	// steps.
+	at    time.Time
+	value time.Duration
+}
+
+// slidingQuantile estimates quantiles per key over the samples observed in
+// the last window, so unlike a cumulative histogram it follows recent
+// changes. Each key's samples are kept oldest first and pruned as new ones
+// arrive. The zero value is ready to use.
+type slidingQuantile struct {
+	mu      sync.Mutex
+	samples map[string][]windowSample
+}
+
+// observe adds value for key at now, drops the samples of every key that are
+// older than window and returns the q quantile of key's remaining samples,
+// along with the keys left without any.
+func (s *slidingQuantile) observe(key string, value time.Duration, now time.Time, window time.Duration, q float64) (time.Duration, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.samples == nil {
+		s.samples = map[string][]windowSample{}
+	}
+	s.samples[key] = append(s.samples[key], windowSample{at: now, value: value})
+
+	cutoff := now.Add(-window)
+	var expired []string
+	for k, samples := range s.samples {
+		i := 0
+		for i < len(samples) && !samples[i].at.After(cutoff) {
+			i++
+		}
+		if i == len(samples) {
+			delete(s.samples, k)
+			expired = append(expired, k)
+			continue
+		}
+		// Appending reallocates once the dropped front is all that is
+		// left of the capacity, so the memory stays bounded
+		s.samples[k] = samples[i:]
+	}
+	values := make([]time.Duration, len(s.samples[key]))
+	for i, sample := range s.samples[key] {
+		values[i] = sample.value
+	}
+	return nearestRank(values, q), expired
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Sliding time to ready p99", func() {
+	t0 := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	It("should estimate the p99 of the samples in the window", func() {
+		var s slidingQuantile
+		var p99 time.Duration
+		for i := 1; i <= 100; i++ {
+			p99, _ = s.observe("default", time.Duration(i)*time.Second, t0.Add(time.Duration(i)*time.Second), time.Hour, 0.99)
+		}
+		Expect(p99).To(Equal(99 * time.Second))
+	})
+
+	It("should drop samples that fell out of the window", func() {
+		var s slidingQuantile
+		for i := range 10 {
+			s.observe("default", time.Minute, t0.Add(time.Duration(i)*time.Second), 15*time.Minute, 0.99)
+		}
+
+		p99, _ := s.observe("default", 2*time.Second, t0.Add(14*time.Minute), 15*time.Minute, 0.99)
+		Expect(p99).To(Equal(time.Minute), "the slow samples are still in the window")
+
+		p99, _ = s.observe("default", 3*time.Second, t0.Add(16*time.Minute), 15*time.Minute, 0.99)
+		Expect(p99).To(Equal(3*time.Second), "only the recent samples are left")
+	})
+
+	It("should report namespaces left without samples", func() {
+		var s slidingQuantile
+		s.observe("quiet", time.Second, t0, time.Minute, 0.99)
+		_, expired := s.observe("busy", time.Second, t0.Add(30*time.Second), time.Minute, 0.99)
+		Expect(expired).To(BeEmpty())
+
+		_, expired = s.observe("busy", time.Second, t0.Add(2*time.Minute), time.Minute, 0.99)
+		Expect(expired).To(ConsistOf("quiet"))
+	})
+
+	It("should expose the estimate per namespace", func() {
+		clock := t0
+		r := &PodStartupReconciler{ToReadyWindow: 15 * time.Minute, now: func() time.Time { return clock }}
+
+		r.observeToReadyWindow("window-a", 40*time.Second)
+		r.observeToReadyWindow("window-b", 5*time.Second)
+		Expect(testutil.ToFloat64(toReadyWindowP99.WithLabelValues("window-a"))).To(Equal(40.0))
+
+		clock = t0.Add(20 * time.Minute)
+		r.observeToReadyWindow("window-a", 4*time.Second)
+		Expect(testutil.ToFloat64(toReadyWindowP99.WithLabelValues("window-a"))).To(Equal(4.0))
+		Expect(toReadyWindowP99.DeleteLabelValues("window-b")).To(BeFalse(), "window-b had no pods ready in the window")
+	})
+})
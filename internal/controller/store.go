@@ -0,0 +1,78 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import "sync"
+
+// RecordStore keeps the latest record of every pod in memory so it can be
+// queried without reading the sinks back.
+type RecordStore struct {
+	mu      sync.RWMutex
+	records map[string]Record
+}
+
+// NewRecordStore returns an empty RecordStore.
+func NewRecordStore() *RecordStore {
+	return &RecordStore{records: map[string]Record{}}
+}
+
+// Put stores rec as the latest record of its pod, replacing any earlier one.
+// Records must not be modified once stored.
+func (s *RecordStore) Put(rec Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[storeKey(rec)] = rec
+}
+
+// List returns the latest record of every pod, in no particular order.
+func (s *RecordStore) List() []Record {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]Record, 0, len(s.records))
+	for _, rec := range s.records {
+		records = append(records, rec)
+	}
+	return records
+}
+
+// Clear drops every stored record.
+func (s *RecordStore) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.records)
+}
+
+// Len returns the number of pods in the store.
+func (s *RecordStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.records)
+}
+
+// storeKey identifies the pod a record belongs to. A pod recreated under the
+// same name is a different pod, so the id set by IDFunc or else the UID is
+// preferred.
+func storeKey(rec Record) string {
+	if id := recordString(rec, "id"); id != "" {
+		return id
+	}
+	if uid := recordString(rec, "uid"); uid != "" {
+		return uid
+	}
+	return recordString(rec, "namespace") + "/" + recordString(rec, "pod")
+}
@@ -34,6 +34,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	monitoringv1alpha1 "github.com/karthikbhat19/pod-time-measure-controller/api/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -61,6 +63,9 @@ var _ = BeforeSuite(func() {
 
 	PodStartupLogPath = "./test_pod_startup_times.json"
 	var err error
+	err = monitoringv1alpha1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
 	// +kubebuilder:scaffold:scheme
 
 	By("bootstrapping test environment")
@@ -97,6 +102,8 @@ var _ = BeforeSuite(func() {
 	reconciler := &PodStartupReconciler{
 		Client: k8sManager.GetClient(),
 		Scheme: k8sManager.GetScheme(),
+
+		RecordMeasurements: true,
 	}
 
 	Expect(reconciler.SetupWithManager(k8sManager)).To(Succeed())
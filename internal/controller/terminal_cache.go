@@ -0,0 +1,83 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"container/list"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// terminalCacheSize bounds how many finished pods terminalCache remembers.
+// Resyncs of pods evicted from it are simply processed again.
+const terminalCacheSize = 4096
+
+// terminalCache remembers the resourceVersion last processed for terminal
+// pods, which can't change state any more, so resyncs of an unchanged pod
+// can be skipped before any lookups. It keeps the most recently used
+// entries. The zero value is ready to use.
+type terminalCache struct {
+	mu      sync.Mutex
+	order   list.List
+	entries map[types.UID]*list.Element
+}
+
+// terminalEntry is an element of terminalCache.order.
+type terminalEntry struct {
+	uid     types.UID
+	version string
+}
+
+// seen reports whether version of the pod is the one last processed.
+func (c *terminalCache) seen(uid types.UID, version string) bool {
+	if version == "" {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[uid]
+	if !ok || elem.Value.(*terminalEntry).version != version {
+		return false
+	}
+	c.order.MoveToFront(elem)
+	return true
+}
+
+// add remembers version as the last processed one of the pod, evicting the
+// least recently used pod when the cache is full.
+func (c *terminalCache) add(uid types.UID, version string) {
+	if version == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[uid]; ok {
+		elem.Value.(*terminalEntry).version = version
+		c.order.MoveToFront(elem)
+		return
+	}
+	if c.entries == nil {
+		c.entries = map[types.UID]*list.Element{}
+	}
+	c.entries[uid] = c.order.PushFront(&terminalEntry{uid: uid, version: version})
+	if c.order.Len() > terminalCacheSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*terminalEntry).uid)
+	}
+}
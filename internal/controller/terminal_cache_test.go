@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Terminal pod cache", func() {
+	It("should skip resyncs of an unchanged terminal pod", func() {
+		ctx := context.Background()
+		pod := newRunningPod("terminal-cached")
+		pod.Status.Phase = corev1.PodSucceeded
+		// The missing Job costs a lookup on every pass that isn't skipped
+		pod.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "batch/v1", Kind: "Job", Name: "gone", UID: "uid-gone", Controller: ptr.To(true),
+		}}
+
+		var gets atomic.Int32
+		c := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(pod).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					gets.Add(1)
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}).
+			Build()
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{Client: c, Scheme: scheme.Scheme, Sinks: []Sink{recorder}, EnrichJobInfo: true}
+
+		_, err := reconcilePod(ctx, r, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1))
+		Expect(gets.Load()).To(BeNumerically(">", 1))
+
+		By("Reconciling the same version again")
+		gets.Store(0)
+		_, err = reconcilePod(ctx, r, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(1), "an unchanged terminal pod must not be written again")
+		Expect(gets.Load()).To(BeEquivalentTo(1), "only the pod itself should be fetched")
+	})
+
+	It("should process a terminal pod again once it changes", func() {
+		ctx := context.Background()
+		pod := newRunningPod("terminal-changed")
+		pod.Status.Phase = corev1.PodFailed
+		recorder := &recordingSink{}
+		r := &PodStartupReconciler{Sinks: []Sink{recorder}}
+
+		_, err := reconcilePod(ctx, r, pod)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(pod), pod)).To(Succeed())
+		pod.Labels = map[string]string{"changed": "true"}
+		Expect(r.Update(ctx, pod)).To(Succeed())
+		Expect(r.terminal.seen(pod.UID, pod.ResourceVersion)).To(BeFalse())
+
+		_, err = reconcilePod(ctx, r, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Records()).To(HaveLen(2))
+		Expect(r.terminal.seen(pod.UID, pod.ResourceVersion)).To(BeTrue())
+	})
+
+	It("should evict the least recently used pods when full", func() {
+		var c terminalCache
+		c.add("first", "1")
+		for i := range terminalCacheSize - 1 {
+			c.add(types.UID(fmt.Sprintf("pod-%d", i)), "1")
+		}
+		// Using the first pod keeps it over the next oldest
+		Expect(c.seen("first", "1")).To(BeTrue())
+		c.add("last", "1")
+
+		Expect(c.seen("first", "1")).To(BeTrue())
+		Expect(c.seen("pod-0", "1")).To(BeFalse())
+		Expect(c.seen("last", "1")).To(BeTrue())
+		Expect(c.order.Len()).To(Equal(terminalCacheSize))
+	})
+})
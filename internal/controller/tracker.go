@@ -0,0 +1,310 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// podState is what the reconciler remembers about a pod between reconciles.
+type podState struct {
+	// uid identifies the pod, see PodStartupReconciler.IDFunc.
+	uid types.UID
+
+	// firstObserved is when the reconciler first saw this pod.
+	firstObserved time.Time
+
+	// readyObserved is set once the pod's time to ready has been fed into
+	// the metrics, so each pod contributes a single observation.
+	readyObserved bool
+
+	// seenReady is set once the pod has been observed with a ready time,
+	// and outcomeCounted once it has finished or gone one way or another:
+	// only pods that end without seenReady count in podsNeverReadyTotal.
+	seenReady      bool
+	outcomeCounted bool
+
+	// statusLagRequeues counts the requeues waiting for the container
+	// statuses of a Running pod to be reported.
+	statusLagRequeues int
+
+	// gated is set once the pod has been seen held by scheduling gates.
+	gated bool
+
+	// completeWaits counts the requeues waiting for the pod to be complete
+	// enough to record, see RecordOnlyWhenComplete.
+	completeWaits int
+
+	// containersStartedObserved is readyObserved for the scheduled to
+	// containers started histogram.
+	containersStartedObserved bool
+
+	// recordedVersion is the resourceVersion of the last state that was
+	// fully written, so the same state is not recorded twice.
+	recordedVersion string
+
+	// pendingReason is the reason the pod was counted in podsPendingTotal,
+	// or empty when it is not counted.
+	pendingReason string
+
+	// deletionRequested is when deletion of the pod was requested, taken
+	// from the last observed version, or zero while it is not terminating.
+	deletionRequested time.Time
+
+	// node is the node of the last observed version.
+	node string
+
+	// containersNotReady holds the containers seen not ready, so that
+	// becoming ready later is seen as a transition.
+	containersNotReady map[string]bool
+
+	// containersReady is when each container was first seen ready after
+	// being seen not ready.
+	containersReady map[string]time.Time
+}
+
+// finish counts the pod in podsNeverReadyTotal for reason if it ends without
+// having been seen ready. Each pod is counted at most once.
+func (s *podState) finish(namespace, reason string) {
+	if s.outcomeCounted {
+		return
+	}
+	s.outcomeCounted = true
+	if !s.seenReady {
+		podsNeverReadyTotal.WithLabelValues(namespace, reason).Inc()
+	}
+}
+
+// setPending moves the pod's contribution to podsPendingTotal to reason,
+// removing it when reason is empty.
+func (s *podState) setPending(namespace, reason string) {
+	if s.pendingReason == reason {
+		return
+	}
+	if s.pendingReason != "" {
+		podsPendingTotal.WithLabelValues(namespace, s.pendingReason).Dec()
+	}
+	if reason != "" {
+		podsPendingTotal.WithLabelValues(namespace, reason).Inc()
+	}
+	s.pendingReason = reason
+}
+
+// podTracker holds per-pod state keyed by namespaced name. A pod recreated
+// under the same name gets fresh state because its UID differs. The zero
+// value is ready to use.
+type podTracker struct {
+	mu   sync.Mutex
+	pods map[types.NamespacedName]*podState
+}
+
+// update runs fn with the state of the given pod while holding the lock.
+func (t *podTracker) update(key types.NamespacedName, uid types.UID, fn func(*podState)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.pods == nil {
+		t.pods = map[types.NamespacedName]*podState{}
+	}
+	state, ok := t.pods[key]
+	if !ok || state.uid != uid {
+		if ok {
+			// The previous pod of this name is gone
+			state.setPending(key.Namespace, "")
+			state.finish(key.Namespace, neverReadyDeleted)
+		}
+		state = &podState{uid: uid}
+		t.pods[key] = state
+	}
+	fn(state)
+}
+
+// firstReady reports whether this is the first time the pod has been seen
+// ready, marking it as seen.
+func (t *podTracker) firstReady(key types.NamespacedName, uid types.UID) bool {
+	first := false
+	t.update(key, uid, func(s *podState) {
+		first = !s.readyObserved
+		s.readyObserved = true
+	})
+	return first
+}
+
+// firstContainersStarted reports whether this is the first time the pod's
+// containers have been seen started, marking them as seen.
+func (t *podTracker) firstContainersStarted(key types.NamespacedName, uid types.UID) bool {
+	first := false
+	t.update(key, uid, func(s *podState) {
+		first = !s.containersStartedObserved
+		s.containersStartedObserved = true
+	})
+	return first
+}
+
+// waitForContainerStatuses reports whether the pod should be requeued to
+// wait for its container statuses, counting the requeue, until it has been
+// requeued limit times.
+func (t *podTracker) waitForContainerStatuses(key types.NamespacedName, uid types.UID, limit int) bool {
+	wait := false
+	t.update(key, uid, func(s *podState) {
+		wait = s.statusLagRequeues < limit
+		if wait {
+			s.statusLagRequeues++
+		}
+	})
+	return wait
+}
+
+// completeWait counts another requeue of the pod waiting to be complete and
+// returns how long to wait before it, doubling base for each earlier one.
+func (t *podTracker) completeWait(key types.NamespacedName, uid types.UID, base time.Duration) time.Duration {
+	delay := base
+	t.update(key, uid, func(s *podState) {
+		delay <<= min(s.completeWaits, 16)
+		s.completeWaits++
+	})
+	return delay
+}
+
+// alreadyRecorded reports whether the given resourceVersion of the pod has
+// already been recorded. An empty version never counts as recorded.
+func (t *podTracker) alreadyRecorded(key types.NamespacedName, uid types.UID, version string) bool {
+	recorded := false
+	t.update(key, uid, func(s *podState) {
+		recorded = version != "" && s.recordedVersion == version
+	})
+	return recorded
+}
+
+// recorded reports whether any state of the pod has been written.
+func (t *podTracker) recorded(key types.NamespacedName, uid types.UID) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.pods[key]
+	return ok && state.uid == uid && state.recordedVersion != ""
+}
+
+// markRecorded remembers that the given resourceVersion of the pod has been
+// written.
+func (t *podTracker) markRecorded(key types.NamespacedName, uid types.UID, version string) {
+	t.update(key, uid, func(s *podState) {
+		s.recordedVersion = version
+	})
+}
+
+// trackPending records whether the pod is Pending and why, keeping
+// podsPendingTotal in step. An empty reason means the pod is not Pending.
+func (t *podTracker) trackPending(key types.NamespacedName, uid types.UID, reason string) {
+	t.update(key, uid, func(s *podState) {
+		s.setPending(key.Namespace, reason)
+	})
+}
+
+// trackDeletion remembers when deletion of the pod was requested and the
+// node it ran on, so its shutdown can be measured once it is gone. A zero
+// time means the pod is not terminating.
+func (t *podTracker) trackDeletion(key types.NamespacedName, uid types.UID, requested time.Time, node string) {
+	t.update(key, uid, func(s *podState) {
+		s.deletionRequested = requested
+		s.node = node
+	})
+}
+
+// observe returns when the pod was first observed, taking now as that time
+// when it is the first observation.
+func (t *podTracker) observe(key types.NamespacedName, uid types.UID, now time.Time) time.Time {
+	var first time.Time
+	t.update(key, uid, func(s *podState) {
+		if s.firstObserved.IsZero() {
+			s.firstObserved = now
+		}
+		first = s.firstObserved
+	})
+	return first
+}
+
+// trackContainersReady records the readiness of the pod's containers as of
+// now and returns when each container was seen becoming ready. Containers
+// that were already ready when first seen, or lost readiness since, are left
+// out, as the transition was not observed.
+func (t *podTracker) trackContainersReady(key types.NamespacedName, uid types.UID, ready map[string]bool, now time.Time) map[string]time.Time {
+	observed := map[string]time.Time{}
+	t.update(key, uid, func(s *podState) {
+		if s.containersNotReady == nil {
+			s.containersNotReady = map[string]bool{}
+			s.containersReady = map[string]time.Time{}
+		}
+		for name, isReady := range ready {
+			switch {
+			case !isReady:
+				s.containersNotReady[name] = true
+				delete(s.containersReady, name)
+			case s.containersNotReady[name] && s.containersReady[name].IsZero():
+				s.containersReady[name] = now
+			}
+		}
+		for name, at := range s.containersReady {
+			observed[name] = at
+		}
+	})
+	return observed
+}
+
+// forget drops the state of a pod that no longer exists, returning it, or
+// nil when the pod was not tracked.
+func (t *podTracker) forget(key types.NamespacedName) *podState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.pods[key]
+	if !ok {
+		return nil
+	}
+	state.setPending(key.Namespace, "")
+	state.finish(key.Namespace, neverReadyDeleted)
+	delete(t.pods, key)
+	return state
+}
+
+// trackGating notes whether the pod is held by scheduling gates and reports
+// whether it has ever been seen so.
+func (t *podTracker) trackGating(key types.NamespacedName, uid types.UID, gated bool) bool {
+	var seen bool
+	t.update(key, uid, func(s *podState) {
+		s.gated = s.gated || gated
+		seen = s.gated
+	})
+	return seen
+}
+
+// trackReadiness notes whether the pod has been ready and whether it ended,
+// counting a Failed pod that was never seen ready. Succeeded pods finished
+// their work, so they are not counted even if they were never ready.
+func (t *podTracker) trackReadiness(key types.NamespacedName, uid types.UID, ready bool, phase corev1.PodPhase) {
+	t.update(key, uid, func(s *podState) {
+		s.seenReady = s.seenReady || ready
+		switch phase {
+		case corev1.PodFailed:
+			s.finish(key.Namespace, neverReadyFailed)
+		case corev1.PodSucceeded:
+			s.outcomeCounted = true
+		}
+	})
+}
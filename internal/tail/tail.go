@@ -0,0 +1,397 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tail prints records to the terminal as the controller produces
+// them, from its gRPC stream or by following a JSON Lines file.
+package tail
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	lifecyclev1 "github.com/karthikbhat19/pod-time-measure-controller/api/lifecycle/v1"
+	"github.com/karthikbhat19/pod-time-measure-controller/pkg/reader"
+)
+
+// pollInterval is how often a followed file is checked for new records.
+const pollInterval = 500 * time.Millisecond
+
+// Durations up to slowThreshold are printed green, up to verySlowThreshold
+// yellow and longer ones red.
+const (
+	slowThreshold     = 5 * time.Second
+	verySlowThreshold = 30 * time.Second
+)
+
+// ANSI escape sequences of the duration colors.
+const (
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorRed    = "\x1b[31m"
+	colorReset  = "\x1b[0m"
+)
+
+// durationOrder lists the durations printed first, in lifecycle order.
+// Others follow by name.
+var durationOrder = []string{
+	"toScheduled", "toInitialized", "toContainersStarted", "toAllContainersStarted",
+	"toReady", "toSucceeded", "toFailed", "runtime",
+}
+
+// Options selects where records are read from and which are printed.
+type Options struct {
+	// GRPCAddress is the controller's gRPC server to watch.
+	GRPCAddress string
+	// CAFile, when set, connects to GRPCAddress over TLS, trusting the CAs
+	// in this bundle.
+	CAFile string
+	// CertFile and KeyFile, when set, present this client certificate to
+	// servers that require mutual TLS. They connect over TLS even without
+	// CAFile, trusting the system's CAs.
+	CertFile string
+	KeyFile  string
+	// File is a JSON Lines record file to follow instead.
+	File string
+	// Namespace, when set, prints only the records of pods in it.
+	Namespace string
+	// Color highlights durations by how slow they are.
+	Color bool
+}
+
+// Main runs the tail subcommand with the given arguments, printing records
+// to stdout until interrupted, and returns the exit code.
+func Main(args []string, stdout, stderr io.Writer) int {
+	var opts Options
+	noColor := false
+	fs := flag.NewFlagSet("tail", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.GRPCAddress, "grpc-address", "", "The controller's gRPC server to stream records from.")
+	fs.StringVar(&opts.CAFile, "ca-file", "", "CA bundle to verify the gRPC server with. Connects in plain text when empty.")
+	fs.StringVar(&opts.CertFile, "cert-file", "", "Client certificate to present to a gRPC server requiring mutual TLS.")
+	fs.StringVar(&opts.KeyFile, "key-file", "", "PEM key of --cert-file.")
+	fs.StringVar(&opts.File, "file", "", "JSON Lines record file to follow instead of the gRPC server.")
+	fs.StringVar(&opts.Namespace, "namespace", "", "Only print records of pods in this namespace.")
+	fs.BoolVar(&noColor, "no-color", false, "Print durations without colors.")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: tail (--grpc-address <host:port> | --file <path>) [flags]") //nolint:errcheck
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 || (opts.GRPCAddress == "") == (opts.File == "") || (opts.CertFile == "") != (opts.KeyFile == "") {
+		fs.Usage()
+		return 2
+	}
+	opts.Color = !noColor && isTerminal(stdout)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := Run(ctx, opts, stdout); err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintf(stderr, "tail: %v\n", err) //nolint:errcheck
+		return 1
+	}
+	return 0
+}
+
+// Run prints every record produced from now on until ctx is done.
+func Run(ctx context.Context, opts Options, w io.Writer) error {
+	printRecord := func(rec reader.Record) error {
+		if opts.Namespace != "" && rec.Namespace != opts.Namespace {
+			return nil
+		}
+		_, err := fmt.Fprintln(w, FormatRecord(rec, opts.Color))
+		return err
+	}
+	if opts.File != "" {
+		return Follow(ctx, opts.File, printRecord)
+	}
+	return watch(ctx, opts, printRecord)
+}
+
+// watch streams records from the gRPC server. The server filters them by
+// namespace too.
+func watch(ctx context.Context, opts Options, fn func(reader.Record) error) error {
+	creds, err := transportCredentials(opts)
+	if err != nil {
+		return err
+	}
+	conn, err := grpc.NewClient(opts.GRPCAddress, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return err
+	}
+	defer conn.Close() //nolint:errcheck
+
+	stream, err := lifecyclev1.NewLifecycleServiceClient(conn).Watch(ctx, &lifecyclev1.WatchRequest{Namespace: opts.Namespace})
+	if err != nil {
+		return err
+	}
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		var rec reader.Record
+		if err := json.Unmarshal([]byte(event.GetRecord()), &rec); err != nil {
+			return fmt.Errorf("decoding record: %w", err)
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// transportCredentials returns the credentials watch connects with: plain
+// text unless a CA bundle or client certificate is configured.
+func transportCredentials(opts Options) (credentials.TransportCredentials, error) {
+	if opts.CAFile == "" && opts.CertFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", opts.CAFile)
+		}
+	}
+	if opts.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// Follow calls fn with every record appended to the JSON Lines file at path
+// from now on, until ctx is done. The path is checked on every poll: once it
+// names another file, as after Clear or a rewrite renames a new file over
+// it, the rest of the old file is read and the new one from its start. A
+// file truncated in place is read again from its start too.
+func Follow(ctx context.Context, path string, fn func(reader.Record) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	var partial []byte
+	for {
+		replaced, err := replacedAt(file, path)
+		if err != nil {
+			return err
+		}
+		if replaced {
+			next, err := os.Open(path)
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				// Removed again since the check, so wait for the next poll
+			case err != nil:
+				return err
+			default:
+				if _, err := readLines(file, path, &partial, fn); err != nil {
+					_ = next.Close()
+					return err
+				}
+				_ = file.Close()
+				file, offset, partial = next, 0, nil
+			}
+		}
+
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() < offset {
+			if offset, err = file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			partial = nil
+		}
+		n, err := readLines(file, path, &partial, fn)
+		if err != nil {
+			return err
+		}
+		offset += n
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// replacedAt reports whether path names a file other than the open one. A
+// missing path is not a replacement yet, as the new file may still be
+// renamed into place.
+func replacedAt(file *os.File, path string) (bool, error) {
+	current, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	opened, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+	return !os.SameFile(opened, current), nil
+}
+
+// readLines reads file to its end and calls fn with every whole line's
+// record. The trailing partial line is kept in partial for the next read.
+// It returns the number of bytes read.
+func readLines(file *os.File, path string, partial *[]byte, fn func(reader.Record) error) (int64, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return 0, err
+	}
+	*partial = append(*partial, data...)
+	for {
+		line, rest, found := strings.Cut(string(*partial), "\n")
+		if !found {
+			break
+		}
+		*partial = []byte(rest)
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var rec reader.Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return int64(len(data)), fmt.Errorf("decoding record in %s: %w", path, err)
+		}
+		if err := fn(rec); err != nil {
+			return int64(len(data)), err
+		}
+	}
+	return int64(len(data)), nil
+}
+
+// FormatRecord renders rec as one line: the time of its latest timestamp,
+// the pod, its node and phase, then its durations, colored by how slow they
+// are when color is set.
+func FormatRecord(rec reader.Record, color bool) string {
+	var b strings.Builder
+	if at := latestTimestamp(rec); !at.IsZero() {
+		b.WriteString(at.Format(time.RFC3339) + " ")
+	}
+	fmt.Fprintf(&b, "%s/%s", rec.Namespace, rec.Pod)
+	if rec.Node != "" {
+		b.WriteString(" node=" + rec.Node)
+	}
+	if rec.Phase != "" {
+		b.WriteString(" " + rec.Phase)
+	}
+	if rec.Incomplete {
+		b.WriteString(" (incomplete)")
+	}
+	for _, name := range durationNames(rec) {
+		d, ok := rec.Duration(name)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, " %s=%s", name, colorize(d, color))
+	}
+	return b.String()
+}
+
+// latestTimestamp returns the most recent timestamp of rec, or the zero time
+// when it has none.
+func latestTimestamp(rec reader.Record) time.Time {
+	var latest time.Time
+	for name := range rec.Timestamps {
+		if t, ok := rec.Timestamp(name); ok && t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// durationNames returns the names of rec's durations, those of
+// durationOrder first.
+func durationNames(rec reader.Record) []string {
+	var names, others []string
+	for _, name := range durationOrder {
+		if _, ok := rec.Durations[name]; ok {
+			names = append(names, name)
+		}
+	}
+	for name := range rec.Durations {
+		if !slices.Contains(durationOrder, name) {
+			others = append(others, name)
+		}
+	}
+	slices.Sort(others)
+	return append(names, others...)
+}
+
+// colorize prints d, wrapped in the color of its slowness when color is
+// set.
+func colorize(d time.Duration, color bool) string {
+	if !color {
+		return d.String()
+	}
+	code := colorGreen
+	switch {
+	case d > verySlowThreshold:
+		code = colorRed
+	case d > slowThreshold:
+		code = colorYellow
+	}
+	return code + d.String() + colorReset
+}
+
+// isTerminal reports whether w is a terminal, where colors are readable.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/karthikbhat19/pod-time-measure-controller/pkg/reader"
+)
+
+var _ = Describe("FormatRecord", func() {
+	rec := reader.Record{
+		Pod:       "web-1",
+		Namespace: "default",
+		Node:      "node-a",
+		Phase:     "Running",
+		Timestamps: map[string]string{
+			"created": "2025-06-01T10:00:00Z",
+			"ready":   "2025-06-01T10:00:40Z",
+			"failed":  "",
+		},
+		Durations: map[string]string{
+			"toReady":     "40s",
+			"toScheduled": "1s",
+			"custom":      "6s",
+			"broken":      "soon",
+		},
+	}
+
+	It("should print the pod and its durations on one line", func() {
+		Expect(FormatRecord(rec, false)).To(Equal(
+			"2025-06-01T10:00:40Z default/web-1 node=node-a Running toScheduled=1s toReady=40s custom=6s"))
+	})
+
+	It("should color durations by how slow they are", func() {
+		Expect(FormatRecord(rec, true)).To(Equal("2025-06-01T10:00:40Z default/web-1 node=node-a Running " +
+			"toScheduled=\x1b[32m1s\x1b[0m toReady=\x1b[31m40s\x1b[0m custom=\x1b[33m6s\x1b[0m"))
+	})
+
+	It("should mark incomplete records and leave out what is unknown", func() {
+		Expect(FormatRecord(reader.Record{Pod: "stuck", Namespace: "batch", Incomplete: true}, true)).
+			To(Equal("batch/stuck (incomplete)"))
+	})
+})
+
+var _ = Describe("Main", func() {
+	It("should require --key-file with --cert-file", func() {
+		var stderr lockedBuffer
+		Expect(Main([]string{"--grpc-address", "localhost:9090", "--cert-file", "client.crt"}, &lockedBuffer{}, &stderr)).To(Equal(2))
+		Expect(stderr.String()).To(ContainSubstring("Usage: tail"))
+	})
+})
+
+var _ = Describe("Follow", func() {
+	It("should print records appended after it starts, filtered by namespace", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "records.jsonl")
+		Expect(os.WriteFile(path, []byte(`{"pod":"old","namespace":"default"}`+"\n"), 0o644)).To(Succeed())
+
+		var out lockedBuffer
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- Run(ctx, Options{File: path, Namespace: "default"}, &out)
+		}()
+
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close() //nolint:errcheck
+		// Give Follow time to start at the end of the file
+		time.Sleep(2 * pollInterval)
+		_, err = file.WriteString(`{"pod":"other","namespace":"kube-system"}` + "\n" +
+			`{"pod":"new","namespace":"default","durations":{"toReady":"2s"}}` + "\n" + `{"pod":"half`)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(out.String).Should(Equal("default/new toReady=2s\n"))
+		cancel()
+		Eventually(done).Should(Receive(MatchError(context.Canceled)))
+	})
+
+	It("should read a file renamed over the followed one from its start", func() {
+		dir := GinkgoT().TempDir()
+		path := filepath.Join(dir, "records.jsonl")
+		Expect(os.WriteFile(path, []byte(`{"pod":"old","namespace":"default"}`+"\n"), 0o644)).To(Succeed())
+
+		var out lockedBuffer
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- Run(ctx, Options{File: path}, &out)
+		}()
+		time.Sleep(2 * pollInterval)
+
+		// Longer than the old file, so only the rename can reveal it
+		replacement := filepath.Join(dir, "records.jsonl.tmp")
+		Expect(os.WriteFile(replacement, []byte(`{"pod":"kept","namespace":"default"}`+"\n"+
+			`{"pod":"rewritten","namespace":"default"}`+"\n"), 0o644)).To(Succeed())
+		Expect(os.Rename(replacement, path)).To(Succeed())
+		Eventually(out.String).Should(Equal("default/kept\ndefault/rewritten\n"))
+
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close() //nolint:errcheck
+		_, err = file.WriteString(`{"pod":"appended","namespace":"default"}` + "\n")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(out.String).Should(HaveSuffix("default/rewritten\ndefault/appended\n"))
+		cancel()
+		Eventually(done).Should(Receive(MatchError(context.Canceled)))
+	})
+})
+
+// lockedBuffer collects output and is safe to read while Run writes to it.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
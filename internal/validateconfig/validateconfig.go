@@ -0,0 +1,84 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validateconfig checks a controller config file offline, so a new
+// ConfigMap can be vetted before the controller picks it up.
+package validateconfig
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/karthikbhat19/pod-time-measure-controller/internal/controller"
+)
+
+// closeTimeout bounds closing the sinks built while validating.
+const closeTimeout = 5 * time.Second
+
+// Main runs the validate-config subcommand with the given arguments,
+// reporting the outcome to stdout and problems to stderr, and returns the
+// exit code: 0 for a valid config, 1 for an invalid one and 2 for bad usage.
+func Main(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate-config", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: validate-config <file>") //nolint:errcheck
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	if err := Validate(fs.Arg(0)); err != nil {
+		fmt.Fprintf(stderr, "validate-config: %s is invalid:\n%v\n", fs.Arg(0), err) //nolint:errcheck
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s is valid\n", fs.Arg(0)) //nolint:errcheck
+	return 0
+}
+
+// Validate loads the config file at path and builds everything the
+// controller would from it, short of connecting to the cluster, returning
+// every problem found. Unlike the controller, which falls back to the
+// defaults, it treats a missing file as an error.
+func Validate(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	cfg, err := controller.LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	// Building the reconciler validates the config, constructs the sinks
+	// and reads the files it refers to
+	r, err := cfg.NewReconciler(nil, nil)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	if err := r.Close(ctx); err != nil {
+		return fmt.Errorf("closing sinks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validateconfig
+
+import (
+	"bytes"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("validate-config", func() {
+	run := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := Main(args, &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	It("should accept a valid config", func() {
+		code, stdout, stderr := run(filepath.Join("testdata", "valid.yaml"))
+		Expect(code).To(Equal(0), stderr)
+		Expect(stdout).To(ContainSubstring("valid.yaml is valid"))
+	})
+
+	It("should report every problem of an invalid config", func() {
+		code, stdout, stderr := run(filepath.Join("testdata", "invalid.yaml"))
+		Expect(code).To(Equal(1))
+		Expect(stdout).To(BeEmpty())
+		Expect(stderr).To(ContainSubstring("invalid.yaml is invalid"))
+		Expect(stderr).To(ContainSubstring(`minCompleteness: unknown value "Halfway"`))
+		Expect(stderr).To(ContainSubstring("sinks.file: path or dir is required"))
+		Expect(stderr).To(ContainSubstring("sinks.parquet: dir is required"))
+	})
+
+	It("should reject unknown sinks and unparseable durations", func() {
+		code, _, stderr := run(filepath.Join("testdata", "unknown_sink.yaml"))
+		Expect(code).To(Equal(1))
+		Expect(stderr).To(ContainSubstring(`unknown field "redis"`))
+
+		code, _, stderr = run(filepath.Join("testdata", "bad_duration.yaml"))
+		Expect(code).To(Equal(1))
+		Expect(stderr).To(ContainSubstring("soon"))
+	})
+
+	It("should fail on a missing file and bad usage", func() {
+		code, _, stderr := run(filepath.Join("testdata", "missing.yaml"))
+		Expect(code).To(Equal(1))
+		Expect(stderr).To(ContainSubstring("missing.yaml"))
+
+		code, _, stderr = run()
+		Expect(code).To(Equal(2))
+		Expect(stderr).To(ContainSubstring("Usage: validate-config <file>"))
+	})
+})
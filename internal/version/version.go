@@ -0,0 +1,49 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package version reports which build of the controller is running.
+package version
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Version and Commit are injected at build time, e.g.
+//
+//	go build -ldflags "-X github.com/karthikbhat19/pod-time-measure-controller/internal/version.Version=v0.1.0"
+var (
+	Version = "dev"
+	Commit  = "unknown"
+)
+
+// String describes the build in one line, as printed by --version.
+func String() string {
+	return fmt.Sprintf("pod-time-measure-controller %s (commit %s, %s)", Version, Commit, runtime.Version())
+}
+
+// NewCollector returns a build_info gauge set to 1, labelled with the
+// current build variables.
+func NewCollector() prometheus.Collector {
+	buildInfo := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "build_info",
+		Help: "Build information of the controller, always 1.",
+	}, []string{"version", "commit", "goversion"})
+	buildInfo.WithLabelValues(Version, Commit, runtime.Version()).Set(1)
+	return buildInfo
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"runtime"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Build info", func() {
+	BeforeEach(func() {
+		version, commit := Version, Commit
+		DeferCleanup(func() { Version, Commit = version, commit })
+		Version, Commit = "v1.2.3", "abc1234"
+	})
+
+	It("should register a build_info gauge labelled with the build variables", func() {
+		registry := prometheus.NewRegistry()
+		Expect(registry.Register(NewCollector())).To(Succeed())
+
+		expected := `
+# HELP build_info Build information of the controller, always 1.
+# TYPE build_info gauge
+build_info{commit="abc1234",goversion="` + runtime.Version() + `",version="v1.2.3"} 1
+`
+		Expect(testutil.GatherAndCompare(registry, strings.NewReader(expected), "build_info")).To(Succeed())
+	})
+
+	It("should describe the build", func() {
+		Expect(String()).To(ContainSubstring("v1.2.3"))
+		Expect(String()).To(ContainSubstring("abc1234"))
+	})
+})
@@ -0,0 +1,172 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package reader parses the pod startup records written by the controller's
+// file sink, so consumers don't have to decode them by hand.
+package reader
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
+)
+
+// Record is a pod startup record. Fields the controller only writes when
+// enabled, such as Cluster or Labels, are empty otherwise. Parts of the
+// record not listed here are dropped.
+type Record struct {
+	Pod           string `json:"pod"`
+	Namespace     string `json:"namespace"`
+	UID           string `json:"uid"`
+	ID            string `json:"id,omitempty"`
+	Node          string `json:"node"`
+	Phase         string `json:"phase"`
+	Cluster       string `json:"cluster,omitempty"`
+	SchemaVersion string `json:"schemaVersion"`
+
+	OwnerKind         string            `json:"ownerKind,omitempty"`
+	OwnerName         string            `json:"ownerName,omitempty"`
+	PodTemplateHash   string            `json:"podTemplateHash,omitempty"`
+	PriorityClassName string            `json:"priorityClassName,omitempty"`
+	Priority          *int64            `json:"priority,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+
+	QOSClass             string `json:"qosClass,omitempty"`
+	CPURequestMillicores int64  `json:"cpuRequestMillicores"`
+	MemoryRequestBytes   int64  `json:"memoryRequestBytes"`
+
+	// Incomplete marks pods recorded before becoming ready or finishing.
+	Incomplete bool `json:"incomplete,omitempty"`
+
+	// Timestamps are RFC 3339 times by name, empty when not reached. Use
+	// Timestamp to parse one.
+	Timestamps map[string]string `json:"timestamps"`
+
+	// Durations are Go duration strings by name. Use Duration to parse one.
+	Durations map[string]string `json:"durations"`
+}
+
+// Timestamp returns the named timestamp, and false when it is missing or
+// unparsable.
+func (r Record) Timestamp(name string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339Nano, r.Timestamps[name])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// Duration returns the named duration, such as "toReady", and false when it
+// is missing or unparsable.
+func (r Record) Duration(name string) (time.Duration, bool) {
+	value, ok := r.Durations[name]
+	if !ok {
+		return 0, false
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+// ReadRecords reads every record from rd, which holds either a JSON array of
+// records or JSON Lines, told apart by whether the first non-whitespace byte
+// is '['. Empty input yields no records.
+func ReadRecords(rd io.Reader) ([]Record, error) {
+	br := bufio.NewReader(rd)
+	first, err := peekNonSpace(br)
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(br)
+	if first == '[' {
+		var records []Record
+		if err := dec.Decode(&records); err != nil {
+			return nil, fmt.Errorf("decoding record array: %w", err)
+		}
+		if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+			return nil, errors.New("decoding record array: unexpected data after the array")
+		}
+		return records, nil
+	}
+
+	var records []Record
+	for {
+		var rec Record
+		if err := dec.Decode(&rec); errors.Is(err, io.EOF) {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("decoding record %d: %w", len(records)+1, err)
+		}
+		records = append(records, rec)
+	}
+}
+
+// peekNonSpace skips leading whitespace and returns the next byte without
+// consuming it.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, br.UnreadByte()
+	}
+}
+
+// FilterNamespace returns the records of pods in namespace, in their
+// original order.
+func FilterNamespace(records []Record, namespace string) []Record {
+	var matched []Record
+	for _, rec := range records {
+		if rec.Namespace == namespace {
+			matched = append(matched, rec)
+		}
+	}
+	return matched
+}
+
+// Percentile returns the p-th percentile, between 0 and 1, of the named
+// duration over the records that have it, by the nearest-rank method. It
+// returns false when no record has the duration.
+func Percentile(records []Record, duration string, p float64) (time.Duration, bool) {
+	var ds []time.Duration
+	for _, rec := range records {
+		if d, ok := rec.Duration(duration); ok {
+			ds = append(ds, d)
+		}
+	}
+	if len(ds) == 0 {
+		return 0, false
+	}
+	slices.Sort(ds)
+	rank := int(math.Ceil(min(max(p, 0), 1)*float64(len(ds)))) - 1
+	return ds[max(rank, 0)], true
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reader
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// record is a record as the file sink writes it, indented like a JSON array
+// element.
+const record = `{
+  "pod": "web-1",
+  "namespace": "default",
+  "uid": "uid-web-1",
+  "node": "node-a",
+  "phase": "Running",
+  "schemaVersion": "v1",
+  "ownerKind": "ReplicaSet",
+  "ownerName": "web-5d9c",
+  "priority": 100,
+  "cpuRequestMillicores": 250,
+  "memoryRequestBytes": 134217728,
+  "preempted": false,
+  "timestamps": {"created": "2025-06-01T10:00:00Z", "ready": "2025-06-01T10:00:04Z", "failed": ""},
+  "durations": {"toReady": "4s", "runtime": "not-a-duration"}
+}`
+
+var _ = Describe("ReadRecords", func() {
+	It("should read a JSON array", func() {
+		records, err := ReadRecords(strings.NewReader("\n[" + record + "," + record + "]\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(2))
+
+		rec := records[0]
+		Expect(rec.Pod).To(Equal("web-1"))
+		Expect(rec.Namespace).To(Equal("default"))
+		Expect(rec.OwnerKind).To(Equal("ReplicaSet"))
+		Expect(rec.Priority).To(HaveValue(BeEquivalentTo(100)))
+		Expect(rec.MemoryRequestBytes).To(BeEquivalentTo(134217728))
+
+		ready, ok := rec.Timestamp("ready")
+		Expect(ok).To(BeTrue())
+		Expect(ready).To(Equal(time.Date(2025, 6, 1, 10, 0, 4, 0, time.UTC)))
+		_, ok = rec.Timestamp("failed")
+		Expect(ok).To(BeFalse(), "timestamps not reached are empty")
+
+		toReady, ok := rec.Duration("toReady")
+		Expect(ok).To(BeTrue())
+		Expect(toReady).To(Equal(4 * time.Second))
+		_, ok = rec.Duration("runtime")
+		Expect(ok).To(BeFalse())
+		_, ok = rec.Duration("toSucceeded")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should read JSON Lines", func() {
+		compact := strings.Join(strings.Fields(record), "")
+		records, err := ReadRecords(strings.NewReader(compact + "\n" + compact + "\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(2))
+		Expect(records[1].UID).To(Equal("uid-web-1"))
+	})
+
+	It("should read empty input as no records", func() {
+		for _, input := range []string{"", " \n", "[]"} {
+			records, err := ReadRecords(strings.NewReader(input))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(records).To(BeEmpty())
+		}
+	})
+
+	It("should reject malformed input", func() {
+		for _, input := range []string{
+			"[" + record,
+			"[" + record + "] {}",
+			"{\"pod\": \"a\"}\n{\"pod\":",
+			"{\"pod\": 1}",
+			"not json",
+		} {
+			_, err := ReadRecords(strings.NewReader(input))
+			Expect(err).To(HaveOccurred(), input)
+		}
+	})
+})
+
+var _ = Describe("Record helpers", func() {
+	records := []Record{
+		{Pod: "a", Namespace: "team-a", Durations: map[string]string{"toReady": "1s"}},
+		{Pod: "b", Namespace: "team-b", Durations: map[string]string{"toReady": "9s"}},
+		{Pod: "c", Namespace: "team-a", Durations: map[string]string{"toReady": "3s"}},
+		{Pod: "d", Namespace: "team-a"},
+		{Pod: "e", Namespace: "team-a", Durations: map[string]string{"toReady": "2s"}},
+	}
+
+	It("should filter by namespace in order", func() {
+		var pods []string
+		for _, rec := range FilterNamespace(records, "team-a") {
+			pods = append(pods, rec.Pod)
+		}
+		Expect(pods).To(Equal([]string{"a", "c", "d", "e"}))
+		Expect(FilterNamespace(records, "other")).To(BeEmpty())
+	})
+
+	It("should compute nearest-rank percentiles over records with the duration", func() {
+		teamA := FilterNamespace(records, "team-a")
+		for p, want := range map[float64]time.Duration{0: time.Second, 0.5: 2 * time.Second, 0.99: 3 * time.Second, 1: 3 * time.Second} {
+			got, ok := Percentile(teamA, "toReady", p)
+			Expect(ok).To(BeTrue())
+			Expect(got).To(Equal(want), "p%v", p)
+		}
+
+		_, ok := Percentile(teamA, "toSucceeded", 0.5)
+		Expect(ok).To(BeFalse())
+	})
+})